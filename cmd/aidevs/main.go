@@ -0,0 +1,35 @@
+package main
+
+import (
+	"github.com/crowmw/ai_devs3/pkg/cli"
+
+	// Episodes register their tasks in init
+	_ "github.com/crowmw/ai_devs3/cmd/poligon"
+	_ "github.com/crowmw/ai_devs3/cmd/s01e01"
+	_ "github.com/crowmw/ai_devs3/cmd/s01e02"
+	_ "github.com/crowmw/ai_devs3/cmd/s01e03"
+	_ "github.com/crowmw/ai_devs3/cmd/s01e05"
+	_ "github.com/crowmw/ai_devs3/cmd/s02e01"
+	_ "github.com/crowmw/ai_devs3/cmd/s02e02"
+	_ "github.com/crowmw/ai_devs3/cmd/s02e03"
+	_ "github.com/crowmw/ai_devs3/cmd/s02e04"
+	_ "github.com/crowmw/ai_devs3/cmd/s02e05"
+	_ "github.com/crowmw/ai_devs3/cmd/s03e01"
+	_ "github.com/crowmw/ai_devs3/cmd/s03e02"
+	_ "github.com/crowmw/ai_devs3/cmd/s03e03"
+	_ "github.com/crowmw/ai_devs3/cmd/s03e04"
+	_ "github.com/crowmw/ai_devs3/cmd/s03e05"
+	_ "github.com/crowmw/ai_devs3/cmd/s04e01"
+	_ "github.com/crowmw/ai_devs3/cmd/s04e02"
+	_ "github.com/crowmw/ai_devs3/cmd/s04e03"
+	_ "github.com/crowmw/ai_devs3/cmd/s04e04"
+	_ "github.com/crowmw/ai_devs3/cmd/s04e05"
+	_ "github.com/crowmw/ai_devs3/cmd/s05e01"
+	_ "github.com/crowmw/ai_devs3/cmd/s05e02"
+	_ "github.com/crowmw/ai_devs3/cmd/s05e03"
+	_ "github.com/crowmw/ai_devs3/cmd/s05e04"
+)
+
+func main() {
+	cli.Main()
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/crowmw/ai_devs3/pkg/logging"
+	"github.com/crowmw/ai_devs3/pkg/mock"
+)
+
+// Local stand-in for C3ntrala. Point C3NTRALA_URL at it to run episodes offline.
+func main() {
+	addr := flag.String("addr", ":3000", "address to listen on")
+	dir := flag.String("dir", "fixtures/c3ntrala", "fixture directory with mock.json, data/ and dane/")
+	logLevel := flag.String("log-level", "info", "minimum log level: debug, info, warn or error")
+	logFormat := flag.String("log-format", logging.FormatPretty, "log output format: pretty or json")
+	flag.Parse()
+
+	if err := logging.Setup(logging.Options{Level: *logLevel, Format: *logFormat}); err != nil {
+		slog.Error("invalid logging options", "error", err)
+		os.Exit(1)
+	}
+	logger := logging.New("mock")
+
+	server, err := mock.NewServer(*dir)
+	if err != nil {
+		logger.Error("error loading fixtures", "error", err)
+		os.Exit(1)
+	}
+
+	logger.Info("starting C3ntrala mock", "addr", *addr, "fixtures", *dir)
+	if err := http.ListenAndServe(*addr, server); err != nil {
+		logger.Error("server error", "error", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,50 @@
+package poligon
+
+import (
+	"github.com/crowmw/ai_devs3/pkg/cli"
+	"github.com/crowmw/ai_devs3/pkg/env"
+	"github.com/crowmw/ai_devs3/pkg/http"
+	"github.com/crowmw/ai_devs3/pkg/processor"
+)
+
+func init() {
+	cli.Register(cli.Task{
+		Name:        "poligon",
+		Description: "Warm-up: fetch poligon data and verify it",
+		Required:    []string{env.KeyPoligonURL, env.KeyMyAPIKey},
+		Run:         run,
+	})
+}
+
+func run(app *cli.Context) error {
+	envSvc := app.Env
+
+	// Fetch data
+	data, err := http.FetchData(envSvc.GetPoligonURL() + "/dane.txt")
+	if err != nil {
+		return err
+	}
+
+	// Process data
+	filteredLines := processor.ReadLinesFromTextFile(data)
+
+	// Display processed data
+	for i, line := range filteredLines {
+		app.Log.Info("fetched line", "line", i+1, "text", line)
+	}
+
+	c3ntralaSvc, err := app.C3ntrala()
+	if err != nil {
+		return err
+	}
+
+	// Send through the outbox, so --dry-run holds the answer back like any report
+	response, err := c3ntralaSvc.PostVerify(envSvc.GetPoligonURL()+"/verify", "POLIGON", filteredLines)
+	if err != nil {
+		return err
+	}
+
+	app.Log.Info("verify response", "response", response)
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package s01e01
+
+import (
+	"fmt"
+
+	"github.com/crowmw/ai_devs3/pkg/ai"
+	"github.com/crowmw/ai_devs3/pkg/cli"
+	"github.com/crowmw/ai_devs3/pkg/env"
+	"github.com/crowmw/ai_devs3/pkg/http"
+	"github.com/crowmw/ai_devs3/pkg/processor"
+)
+
+func init() {
+	cli.Register(cli.Task{
+		Name:        "s01e01",
+		Description: "Log into the robot site by answering the year question",
+		Required:    []string{env.KeyXYZURL},
+		Run:         run,
+	})
+}
+
+func run(app *cli.Context) error {
+	envSvc := app.Env
+
+	aiSvc, err := app.AI()
+	if err != nil {
+		return err
+	}
+
+	// Fetch data
+	data, err := http.FetchData(envSvc.GetXYZURL())
+	if err != nil {
+		return err
+	}
+
+	// Process data
+	htmlString := processor.GetHTMLString(data)
+
+	question := processor.ExtractTextFromHTML(htmlString, "//p[@id='human-question']")
+
+	// Send question to OpenAI
+	openaiYearResponse, err := aiSvc.SendChatCompletion("gpt-4o-mini", true, ai.GetYearExtractionPrompt(question))
+	if err != nil {
+		return fmt.Errorf("error getting OpenAI response: %w", err)
+	}
+	formData := map[string]string{
+		"username": "tester",
+		"password": "574e112a",
+		"answer":   openaiYearResponse,
+	}
+
+	response, err := http.SendFormData(envSvc.GetXYZURL(), formData)
+	if err != nil {
+		return fmt.Errorf("error sending form data: %w", err)
+	}
+
+	flag := processor.ExtractTextFromHTML(response, "//h2/text()")
+
+	app.Log.Info("flag found", "flag", flag)
+
+	return nil
+}
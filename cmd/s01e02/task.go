@@ -0,0 +1,41 @@
+package s01e02
+
+import (
+	"fmt"
+
+	"github.com/crowmw/ai_devs3/pkg/cli"
+	"github.com/crowmw/ai_devs3/pkg/env"
+	"github.com/crowmw/ai_devs3/pkg/robot"
+)
+
+func init() {
+	cli.Register(cli.Task{
+		Name:        "s01e02",
+		Description: "Pass the robot guard verification",
+		Required:    []string{env.KeyXYZURL},
+		Run:         run,
+	})
+}
+
+func run(app *cli.Context) error {
+	envSvc := app.Env
+
+	aiSvc, err := app.AI()
+	if err != nil {
+		return err
+	}
+
+	// Create new robot guard instance
+	guard, err := robot.NewRobotGuard(envSvc, aiSvc)
+	if err != nil {
+		return fmt.Errorf("error creating robot guard: %w", err)
+	}
+
+	// Start the verification process
+	err = guard.StartVerification()
+	if err != nil {
+		return fmt.Errorf("error during verification: %w", err)
+	}
+
+	return nil
+}
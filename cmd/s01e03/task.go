@@ -0,0 +1,50 @@
+package s01e03
+
+import (
+	"fmt"
+
+	"github.com/crowmw/ai_devs3/pkg/cli"
+	"github.com/crowmw/ai_devs3/pkg/env"
+	"github.com/crowmw/ai_devs3/pkg/robot"
+)
+
+func init() {
+	cli.Register(cli.Task{
+		Name:        "s01e03",
+		Description: "Fix and send industrial robot calibration data",
+		Required:    []string{env.KeyC3ntralaURL, env.KeyMyAPIKey},
+		Run:         run,
+	})
+}
+
+func run(app *cli.Context) error {
+	envSvc := app.Env
+
+	aiSvc, err := app.AI()
+	if err != nil {
+		return err
+	}
+
+	c3ntralaSvc, err := app.C3ntrala()
+	if err != nil {
+		return err
+	}
+
+	// Create new robot guard instance
+	robot, err := robot.NewIndustrialRobot(envSvc, aiSvc, c3ntralaSvc)
+	if err != nil {
+		return fmt.Errorf("error creating industrial robot: %w", err)
+	}
+
+	err = robot.Recalibrate()
+	if err != nil {
+		return fmt.Errorf("error during recalibration: %w", err)
+	}
+
+	err = robot.SendCalibrationReport()
+	if err != nil {
+		return fmt.Errorf("error sending calibration report: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package s01e05
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/crowmw/ai_devs3/pkg/c3ntrala"
+	"github.com/crowmw/ai_devs3/pkg/cli"
+	"github.com/crowmw/ai_devs3/pkg/env"
+	"github.com/crowmw/ai_devs3/pkg/logging"
+	"github.com/crowmw/ai_devs3/pkg/processor"
+	"github.com/sashabaranov/go-openai"
+)
+
+func init() {
+	cli.Register(cli.Task{
+		Name:        "s01e05",
+		Description: "Censor personal data in the agent report",
+		Required:    []string{env.KeyC3ntralaURL, env.KeyMyAPIKey},
+		Run:         run,
+	})
+}
+
+func run(app *cli.Context) error {
+	aiSvc, err := app.AI()
+	if err != nil {
+		return err
+	}
+
+	// Fetch the text file that needs to be censored from the API
+	resources, err := app.Resources()
+	if err != nil {
+		return err
+	}
+	lines, err := resources.LoadLines(c3ntrala.ResourceCensorship)
+	if err != nil {
+		return err
+	}
+
+	// Convert the fetched data into a string format
+	dataToCensor := strings.Join(lines, "\n")
+
+	logging.Dump(app.Log, "data to censor", "text", dataToCensor)
+
+	// Read the system instructions from system.md file
+	// This file contains the rules and guidelines for censorship
+	systemPrompt, err := processor.ReadMarkdownFile("cmd/s01e05/systemPrompt.md")
+	if err != nil {
+		return fmt.Errorf("error reading system.md: %w", err)
+	}
+
+	// Create a chat completion request with:
+	// - System message: Instructions from system.md
+	// - User message: The text that needs to be censored
+	question := []openai.ChatCompletionMessage{
+		{
+			Role:    "system",
+			Content: systemPrompt,
+		},
+		{
+			Role:    "user",
+			Content: dataToCensor,
+		},
+	}
+
+	// Send the request to OpenAI API to get censored version of the text
+	censoredData, err := aiSvc.SendChatCompletion("gpt-4o-mini", true, question)
+	if err != nil {
+		return fmt.Errorf("error sending chat completion: %w", err)
+	}
+
+	app.Log.Info("data censored", "text", censoredData)
+
+	// Send the censored text back to the API as a report
+	c3ntralaSvc, err := app.C3ntrala()
+	if err != nil {
+		return err
+	}
+	reportResponse, err := c3ntralaSvc.PostReport("CENZURA", censoredData, false)
+	if err != nil {
+		return fmt.Errorf("error sending report: %w", err)
+	}
+
+	app.Log.Info("report sent", "result", reportResponse)
+
+	return nil
+}
@@ -1,49 +1,46 @@
-package main
+package s02e01
 
 import (
-	"crypto/md5"
-	"fmt"
-
-	"github.com/crowmw/ai_devs3/pkg/ai"
-	"github.com/crowmw/ai_devs3/pkg/config"
-	"github.com/crowmw/ai_devs3/pkg/http"
+	"github.com/crowmw/ai_devs3/pkg/c3ntrala"
+	"github.com/crowmw/ai_devs3/pkg/cli"
+	"github.com/crowmw/ai_devs3/pkg/env"
 	"github.com/crowmw/ai_devs3/pkg/processor"
 	"github.com/sashabaranov/go-openai"
 )
 
-func main() {
-	// Load environment variables from .env file
-	if err := config.LoadEnv(); err != nil {
-		fmt.Println(err)
-		return
-	}
+func init() {
+	cli.Register(cli.Task{
+		Name:        "s02e01",
+		Description: "Find the institute street from interrogation recordings",
+		Required:    []string{env.KeyC3ntralaURL, env.KeyMyAPIKey},
+		Run:         run,
+	})
+}
 
-	zippedData, err := http.FetchData(config.GetC3ntralaURL() + "/dane/przesluchania.zip")
+func run(app *cli.Context) error {
+	aiSvc, err := app.AI()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
-	// Create MD5 hash of the zipped data
-	hash := md5.Sum(zippedData)
-
-	// Unzip the data to a directory named after the hash
-	hashDir := fmt.Sprintf("%x", hash)
-	if err := processor.ExtractZipToDirectory(zippedData, hashDir); err != nil {
-		fmt.Println(err)
-		return
+	// Unzip the current version of the recordings next to its mirrored copy
+	resources, err := app.Resources()
+	if err != nil {
+		return err
+	}
+	hashDir, err := resources.ExtractZip(c3ntrala.ResourceInterrogation, "")
+	if err != nil {
+		return err
 	}
 
-	err = processor.ProcessAudioFiles(hashDir)
+	err = processor.ProcessAudioFiles(aiSvc, hashDir)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
 	concatenatedTranscriptions, err := processor.ReadAllTxtFilesFromDirectory(hashDir + "/transcriptions")
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
 	systemPrompt := GeneratePrompt(concatenatedTranscriptions)
@@ -59,22 +56,26 @@ func main() {
 		},
 	}
 
-	aiResponse, err := ai.SendChatCompletion("gpt-4o", true, message)
+	aiResponse, err := aiSvc.SendChatCompletion("gpt-4o", true, message)
 
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
-	fmt.Println("AI response: ", aiResponse)
+	app.Log.Info("AI response", "response", aiResponse)
 
-	result, err := http.SendC3ntralaReport("mp3", aiResponse)
+	c3ntralaSvc, err := app.C3ntrala()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
+	result, err := c3ntralaSvc.PostReport("mp3", aiResponse, false)
+	if err != nil {
+		return err
+	}
+
+	app.Log.Info("report sent", "result", result)
 
-	fmt.Println("Result: ", result)
+	return nil
 }
 
 func GeneratePrompt(transcription string) string {
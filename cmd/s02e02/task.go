@@ -1,27 +1,33 @@
-package main
+package s02e02
 
 import (
 	"fmt"
 
-	"github.com/crowmw/ai_devs3/pkg/ai"
-	"github.com/crowmw/ai_devs3/pkg/config"
+	"github.com/crowmw/ai_devs3/pkg/cli"
 	"github.com/crowmw/ai_devs3/pkg/processor"
 	"github.com/sashabaranov/go-openai"
 )
 
-func main() {
-	// Load environment variables from .env file
-	if err := config.LoadEnv(); err != nil {
-		fmt.Println(err)
-		return
+func init() {
+	cli.Register(cli.Task{
+		Name:        "s02e02",
+		Description: "Recognize the city from map fragments",
+		Run:         run,
+	})
+}
+
+func run(app *cli.Context) error {
+
+	aiSvc, err := app.AI()
+	if err != nil {
+		return err
 	}
 
 	// Read all images from maps directory
-	fmt.Println("Reading images from maps directory")
+	app.Log.Info("reading images from maps directory")
 	mapImagesBase64, err := processor.ReadAllImagesFromDirectory("cmd/s02e02/maps")
 	if err != nil {
-		fmt.Printf("Error reading images: %v\n", err)
-		return
+		return fmt.Errorf("error reading images: %w", err)
 	}
 
 	prompt := []openai.ChatCompletionMessage{
@@ -51,14 +57,15 @@ func main() {
 		})
 	}
 
-	fmt.Println("Sending chat completion")
-	response, err := ai.SendChatCompletion("gpt-4o", false, prompt)
+	app.Log.Info("sending chat completion", "images", len(mapImagesBase64))
+	response, err := aiSvc.SendChatCompletion("gpt-4o", false, prompt)
 	if err != nil {
-		fmt.Printf("Error sending chat completion: %v\n", err)
-		return
+		return fmt.Errorf("error sending chat completion: %w", err)
 	}
 
-	fmt.Println(response)
+	app.Log.Info("city recognized", "response", response)
+
+	return nil
 }
 
 const systemPrompt = `You are an expert in analyzing Polish maps. Please carefully analyze four map fragments that will be sent as JPG images.
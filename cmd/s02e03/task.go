@@ -0,0 +1,49 @@
+package s02e03
+
+import (
+	"fmt"
+
+	"github.com/crowmw/ai_devs3/pkg/cli"
+	"github.com/crowmw/ai_devs3/pkg/env"
+	"github.com/crowmw/ai_devs3/pkg/robot"
+)
+
+type RobotDescriptionResult struct {
+	Description string `json:"description"`
+}
+
+func init() {
+	cli.Register(cli.Task{
+		Name:        "s02e03",
+		Description: "Generate a robot image from its description",
+		Required:    []string{env.KeyC3ntralaURL, env.KeyMyAPIKey},
+		Run:         run,
+	})
+}
+
+func run(app *cli.Context) error {
+	envSvc := app.Env
+
+	aiSvc, err := app.AI()
+	if err != nil {
+		return err
+	}
+
+	robot, err := robot.NewSentryRobot(envSvc, aiSvc)
+	if err != nil {
+		return fmt.Errorf("error creating robot: %w", err)
+	}
+
+	c3ntralaSvc, err := app.C3ntrala()
+	if err != nil {
+		return err
+	}
+	report, err := c3ntralaSvc.PostReport("robotid", robot.ImageURL, false)
+	if err != nil {
+		return fmt.Errorf("error sending report: %w", err)
+	}
+
+	app.Log.Info("report sent", "result", report)
+
+	return nil
+}
@@ -1,13 +1,14 @@
-package main
+package s02e04
 
 import (
 	"encoding/json"
 	"fmt"
 
-	"github.com/crowmw/ai_devs3/pkg/ai"
+	"github.com/crowmw/ai_devs3/pkg/cli"
 	"github.com/crowmw/ai_devs3/pkg/env"
 	"github.com/crowmw/ai_devs3/pkg/factory"
-	"github.com/crowmw/ai_devs3/pkg/http"
+	"github.com/crowmw/ai_devs3/pkg/logging"
+	"github.com/crowmw/ai_devs3/pkg/textpl"
 	"github.com/sashabaranov/go-openai"
 )
 
@@ -15,18 +16,28 @@ type RobotDescriptionResult struct {
 	Description string `json:"description"`
 }
 
-func main() {
-	// Load environment variables from .env file
-	envSvc, err := env.NewService()
+var logger = logging.New("cli").With(logging.KeyTask, "s02e04")
+
+func init() {
+	cli.Register(cli.Task{
+		Name:        "s02e04",
+		Description: "Categorize factory files into people and hardware",
+		Required:    []string{env.KeyC3ntralaURL, env.KeyMyAPIKey},
+		Run:         run,
+	})
+}
+
+func run(app *cli.Context) error {
+	envSvc := app.Env
+
+	aiSvc, err := app.AI()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
-	factoryData, err := factory.NewFactory(envSvc)
+	factoryData, err := factory.NewFactory(envSvc, aiSvc)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
 	var allFactoryFilesContent []factory.FactoryFileContent
@@ -34,31 +45,26 @@ func main() {
 	// Get text files
 	factoryTextFiles, err := factoryData.GetTextFiles()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 	allFactoryFilesContent = append(allFactoryFilesContent, factoryTextFiles...)
 
 	// Get image files texts
 	factoryImageFilesTexts, err := factoryData.GetImageFilesTexts()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 	allFactoryFilesContent = append(allFactoryFilesContent, factoryImageFilesTexts...)
 
 	// Get audio files texts
 	factoryAudioFilesTexts, err := factoryData.GetAudioFilesTexts()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 	allFactoryFilesContent = append(allFactoryFilesContent, factoryAudioFilesTexts...)
 
-	// Print all files
 	for _, file := range allFactoryFilesContent {
-		fmt.Println(file.File)
-		fmt.Println(file.Content)
+		logging.Dump(app.Log, "factory file", "file", file.File, "content", file.Content)
 	}
 
 	message := []openai.ChatCompletionMessage{
@@ -72,17 +78,14 @@ func main() {
 		},
 	}
 
-	fmt.Println("--------------------------------")
-	fmt.Println(fmt.Sprintf("%+v", allFactoryFilesContent))
-	fmt.Println("--------------------------------")
+	app.Log.Info("categorizing factory files", "files", len(allFactoryFilesContent))
 
-	aiResponse, err := ai.SendChatCompletion("gpt-4o", false, message)
+	aiResponse, err := aiSvc.SendChatCompletion("gpt-4o", false, message)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
-	fmt.Println(aiResponse)
+	app.Log.Debug("AI response", "response", aiResponse)
 
 	var categories struct {
 		People   []string `json:"people"`
@@ -90,16 +93,45 @@ func main() {
 	}
 
 	if err := json.Unmarshal([]byte(aiResponse), &categories); err != nil {
-		fmt.Println("Error unmarshaling response:", err)
-		return
+		return fmt.Errorf("error unmarshaling response: %w", err)
+	}
+
+	// The model sometimes alters filenames, so map them back to the real ones
+	var fileNames []string
+	for _, file := range allFactoryFilesContent {
+		fileNames = append(fileNames, file.File)
 	}
+	categories.People = matchFileNames(categories.People, fileNames)
+	categories.Hardware = matchFileNames(categories.Hardware, fileNames)
 
-	report, err := http.SendC3ntralaReport("kategorie", categories)
+	c3ntralaSvc, err := app.C3ntrala()
 	if err != nil {
-		fmt.Println("Error sending report:", err)
-		return
+		return err
+	}
+	report, err := c3ntralaSvc.PostReport("kategorie", categories, false)
+	if err != nil {
+		return fmt.Errorf("error sending report: %w", err)
+	}
+	app.Log.Info("report sent", "result", report)
+
+	return nil
+}
+
+// matchFileNames replaces each name with the closest existing filename, dropping names that match nothing
+func matchFileNames(names []string, fileNames []string) []string {
+	matched := []string{}
+	for _, name := range names {
+		match, ok := textpl.BestMatch(name, fileNames, 0.8)
+		if !ok {
+			logger.Warn("unknown file in categories", "file", name)
+			continue
+		}
+		if match.Value != name {
+			logger.Info("fixed filename", "from", name, "to", match.Value)
+		}
+		matched = append(matched, match.Value)
 	}
-	fmt.Println("Report:", report)
+	return matched
 }
 
 var systemPrompt = `
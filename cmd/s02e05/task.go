@@ -0,0 +1,81 @@
+package s02e05
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/crowmw/ai_devs3/pkg/c3ntrala"
+	"github.com/crowmw/ai_devs3/pkg/cli"
+	"github.com/crowmw/ai_devs3/pkg/env"
+	"github.com/crowmw/ai_devs3/pkg/processor"
+)
+
+func init() {
+	cli.Register(cli.Task{
+		Name:        "s02e05",
+		Description: "Answer questions about the arxiv draft with media",
+		Required:    []string{env.KeyC3ntralaURL, env.KeyMyAPIKey},
+		Run:         run,
+	})
+}
+
+func run(app *cli.Context) error {
+	envSvc := app.Env
+
+	aiSvc, err := app.AI()
+	if err != nil {
+		return err
+	}
+
+	// Create output directory if it doesn't exist
+	outputDir := filepath.Join("cmd", "s02e05", "downloaded")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("error creating output directory: %w", err)
+	}
+
+	// Create article processor
+	articleProcessor := processor.NewArticleProcessor(envSvc, aiSvc, outputDir)
+
+	// Fetch HTML content
+	resources, err := app.Resources()
+	if err != nil {
+		return err
+	}
+	htmlContent, err := resources.Fetch(c3ntrala.ResourceArxivDraft)
+	if err != nil {
+		return fmt.Errorf("error fetching HTML: %w", err)
+	}
+
+	// Process article
+	articleText, err := articleProcessor.ProcessArticle(htmlContent)
+	if err != nil {
+		return fmt.Errorf("error processing article: %w", err)
+	}
+
+	// Fetch questions from arxiv.txt
+	questions, err := resources.LoadLines(c3ntrala.ResourceArxivQuestion)
+	if err != nil {
+		return fmt.Errorf("error fetching questions: %w", err)
+	}
+
+	// Process questions
+	answers, err := articleProcessor.ProcessQuestions(articleText, questions)
+	if err != nil {
+		return fmt.Errorf("error processing questions: %w", err)
+	}
+
+	// Send report
+	c3ntralaSvc, err := app.C3ntrala()
+	if err != nil {
+		return err
+	}
+	result, err := c3ntralaSvc.PostReport("arxiv", answers, false)
+	if err != nil {
+		return fmt.Errorf("error sending report: %w", err)
+	}
+
+	app.Log.Info("report sent", "result", result)
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package s03e01
+
+import (
+	"fmt"
+
+	"github.com/crowmw/ai_devs3/pkg/cli"
+	"github.com/crowmw/ai_devs3/pkg/env"
+	"github.com/crowmw/ai_devs3/pkg/factory"
+)
+
+func init() {
+	cli.Register(cli.Task{
+		Name:        "s03e01",
+		Description: "Generate keywords for factory reports",
+		Required:    []string{env.KeyC3ntralaURL, env.KeyMyAPIKey},
+		Run:         run,
+	})
+}
+
+func run(app *cli.Context) error {
+	envSvc := app.Env
+
+	aiSvc, err := app.AI()
+	if err != nil {
+		return err
+	}
+
+	f, err := factory.NewFactory(envSvc, aiSvc)
+	if err != nil {
+		return err
+	}
+
+	aiResponse, err := f.AnalyzeReports()
+	if err != nil {
+		return err
+	}
+	app.Log.Info("keywords generated", "response", aiResponse)
+
+	// Send report
+	c3ntralaSvc, err := app.C3ntrala()
+	if err != nil {
+		return err
+	}
+	result, err := c3ntralaSvc.PostReport("dokumenty", aiResponse, false)
+	if err != nil {
+		return fmt.Errorf("error sending report: %w", err)
+	}
+
+	app.Log.Info("report sent", "result", result)
+
+	return nil
+}
@@ -1,46 +1,46 @@
-package main
+package s03e02
 
 import (
 	"context"
 	"fmt"
 
-	"github.com/crowmw/ai_devs3/pkg/ai"
+	"github.com/crowmw/ai_devs3/pkg/cli"
 	"github.com/crowmw/ai_devs3/pkg/env"
 	"github.com/crowmw/ai_devs3/pkg/factory"
-	"github.com/crowmw/ai_devs3/pkg/http"
 	"github.com/crowmw/ai_devs3/pkg/vector"
 	"github.com/qdrant/go-client/qdrant"
 )
 
-func main() {
-	envSvc, err := env.NewService()
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
+func init() {
+	cli.Register(cli.Task{
+		Name:        "s03e02",
+		Description: "Find the weapon test date with vector search",
+		Required:    []string{env.KeyC3ntralaURL, env.KeyMyAPIKey},
+		Run:         run,
+	})
+}
 
-	f, err := factory.NewFactory(envSvc)
+func run(app *cli.Context) error {
+	envSvc := app.Env
+
+	aiSvc, err := app.AI()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
-	weaponTests, err := f.GetWeaponTests()
+	f, err := factory.NewFactory(envSvc, aiSvc)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
-	aiSvc, err := ai.NewService(envSvc)
+	weaponTests, err := f.GetWeaponTests()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
 	vectorSvc, err := vector.NewService(envSvc, aiSvc, 1024)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
 	collectionName := "weapon_tests"
@@ -58,38 +58,37 @@ func main() {
 	}
 	addedPoints, err := vectorSvc.AddPoints(context.Background(), collectionName, points)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
 	query := "W raporcie, z którego dnia znajduje się wzmianka o kradzieży prototypu broni?"
 
 	results, err := vectorSvc.Search(context.Background(), collectionName, query, 1)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
-	fmt.Println("Search results:", results)
+	app.Log.Debug("search results", "results", results)
 
 	var matchingPoint *qdrant.Struct
 	for _, point := range addedPoints {
 		if point.Id.String() == results[0].Id.String() {
-			// Debug print the structure
-			fmt.Printf("Payload structure: %+v\n", point.Payload)
-			metadataValue := point.Payload["metadata"]
-			fmt.Printf("Metadata value: %+v\n", metadataValue)
-			matchingPoint = metadataValue.GetStructValue()
-			fmt.Printf("Metadata struct: %+v\n", matchingPoint)
+			app.Log.Debug("matching point payload", "payload", point.Payload)
+			matchingPoint = point.Payload["metadata"].GetStructValue()
 			break
 		}
 	}
 
-	fmt.Printf("Matching point: %+v\n", matchingPoint)
-	r, err := http.SendC3ntralaReport("wektory", matchingPoint.GetFields()["date"].GetStringValue())
+	app.Log.Info("matching point found", "metadata", matchingPoint)
+	c3ntralaSvc, err := app.C3ntrala()
+	if err != nil {
+		return err
+	}
+	r, err := c3ntralaSvc.PostReport("wektory", matchingPoint.GetFields()["date"].GetStringValue(), false)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
-	fmt.Println("Report response:", r)
+	app.Log.Info("report sent", "result", r)
+
+	return nil
 }
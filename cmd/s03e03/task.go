@@ -1,4 +1,4 @@
-package main
+package s03e03
 
 import (
 	"encoding/json"
@@ -6,26 +6,43 @@ import (
 	"strings"
 
 	"github.com/crowmw/ai_devs3/pkg/ai"
+	"github.com/crowmw/ai_devs3/pkg/cli"
+	"github.com/crowmw/ai_devs3/pkg/conversation"
 	"github.com/crowmw/ai_devs3/pkg/env"
 	"github.com/crowmw/ai_devs3/pkg/http"
 	"github.com/sashabaranov/go-openai"
 )
 
-func main() {
-	envSvc, err := env.NewService()
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
+func init() {
+	cli.Register(cli.Task{
+		Name:        "s03e03",
+		Description: "Find inactive datacenters with managers on leave via APIDB",
+		Required:    []string{env.KeyC3ntralaURL, env.KeyMyAPIKey},
+		Run:         run,
+	})
+}
+
+func run(app *cli.Context) error {
+	envSvc := app.Env
 
-	aiSvc, err := ai.NewService(envSvc)
+	aiSvc, err := app.AI()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
 	model := "gpt-4o-mini"
 
+	// table structures found early on are summarized once the query results outgrow the budget
+	conv, err := conversation.New(conversation.Options{
+		Model:     model,
+		MaxTokens: 6000,
+		KeepLast:  6,
+		Summarize: conversation.AISummarizer(aiSvc, model),
+	})
+	if err != nil {
+		return err
+	}
+
 	messages := []openai.ChatCompletionMessage{
 		{Role: "system", Content: systemPrompt},
 		{Role: "user", Content: "Let's begin investigating the database. First, let's see what tables are available."},
@@ -35,35 +52,37 @@ func main() {
 	var result string
 
 	for {
+		messages, _, err = conv.Fit(messages)
+		if err != nil {
+			return err
+		}
+
 		response, err := aiSvc.ChatCompletion(ai.ChatCompletionConfig{
 			Model:    model,
 			Messages: messages,
 		})
 		if err != nil {
-			fmt.Println(err)
-			return
+			return err
 		}
 
 		content := response.Choices[0].Message.Content
 
-		fmt.Println("🤖 AI:", content)
+		app.Log.Info("AI query", "query", content)
 
 		if strings.HasPrefix(content, "FINAL:") {
 			finalQuery = strings.TrimPrefix(content, "FINAL:")
 			result, err = http.PostSQLQueryToAPIDB(envSvc, finalQuery)
 			if err != nil {
-				fmt.Println(err)
-				return
+				return err
 			}
 			break
 		}
 
 		queryResult, err := http.PostSQLQueryToAPIDB(envSvc, content)
 		if err != nil {
-			fmt.Println(err)
-			return
+			return err
 		}
-		fmt.Println("📊 DB:", content)
+		app.Log.Debug("DB result", "query", content, "result", queryResult)
 
 		messages = append(messages,
 			openai.ChatCompletionMessage{Role: "assistant", Content: content},
@@ -71,9 +90,7 @@ func main() {
 		)
 	}
 
-	fmt.Println("--------------------------------")
-	fmt.Println("🎯 Final query:", finalQuery)
-	fmt.Println("🎯 Result:", result)
+	app.Log.Info("final query", "query", finalQuery, "result", result)
 
 	// Extract dc_id values from the result
 	var response struct {
@@ -84,8 +101,7 @@ func main() {
 	}
 
 	if err := json.Unmarshal([]byte(result), &response); err != nil {
-		fmt.Println("Error parsing result:", err)
-		return
+		return fmt.Errorf("error parsing result: %w", err)
 	}
 
 	// Create array of dc_id values
@@ -94,13 +110,18 @@ func main() {
 		dcIDs[i] = item.DCID
 	}
 
-	reportResult, err := http.SendC3ntralaReport("database", dcIDs)
+	c3ntralaSvc, err := app.C3ntrala()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
+	reportResult, err := c3ntralaSvc.PostReport("database", dcIDs, false)
+	if err != nil {
+		return err
+	}
+
+	app.Log.Info("report sent", "result", reportResult)
 
-	fmt.Println("❇️ Report result:", reportResult)
+	return nil
 }
 
 const systemPrompt = `
@@ -0,0 +1,176 @@
+package s03e04
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/crowmw/ai_devs3/pkg/ai"
+	"github.com/crowmw/ai_devs3/pkg/c3ntrala"
+	"github.com/crowmw/ai_devs3/pkg/cli"
+	"github.com/crowmw/ai_devs3/pkg/explorer"
+	"github.com/crowmw/ai_devs3/pkg/graph"
+	"github.com/crowmw/ai_devs3/pkg/textpl"
+	"github.com/sashabaranov/go-openai"
+)
+
+func init() {
+	cli.Register(cli.Task{
+		Name:        "s03e04",
+		Description: "Track Barbara through people and places search",
+		Run:         run,
+	})
+}
+
+func run(app *cli.Context) error {
+
+	c3ntralaSvc, err := app.C3ntrala()
+	if err != nil {
+		return err
+	}
+
+	barbaraNote, err := c3ntralaSvc.GetBarbaraNote()
+	if err != nil {
+		return err
+	}
+
+	aiSvc, err := app.AI()
+	if err != nil {
+		return err
+	}
+
+	model := "gpt-4o"
+
+	messages := []openai.ChatCompletionMessage{
+		{Role: "system", Content: extractNamesAndCitiesPrompt},
+		{Role: "user", Content: barbaraNote},
+	}
+
+	aiExtractResponse, err := aiSvc.ChatCompletion(ai.ChatCompletionConfig{
+		Model:    model,
+		Messages: messages,
+	})
+	if err != nil {
+		return err
+	}
+
+	app.Log.Info("extracted names and cities", "response", aiExtractResponse.Choices[0].Message.Content)
+
+	type ExtractedData struct {
+		Names  []string `json:"names"`
+		Cities []string `json:"cities"`
+	}
+
+	var extractedData ExtractedData
+	if err := json.Unmarshal([]byte(aiExtractResponse.Choices[0].Message.Content), &extractedData); err != nil {
+		return fmt.Errorf("error parsing extracted data: %w", err)
+	}
+
+	var seeds []explorer.Entity
+	for _, name := range extractedData.Names {
+		seeds = append(seeds, explorer.Entity{Type: "person", Value: name})
+	}
+	for _, city := range extractedData.Cities {
+		seeds = append(seeds, explorer.Entity{Type: "place", Value: city})
+	}
+
+	// Places where BARBARA was already known to be
+	knownCities := map[string]bool{"WARSZAWA": true, "KRAKOW": true}
+
+	loopExplorer, err := explorer.NewExplorer(explorer.Config{
+		Seeds: seeds,
+		Expanders: map[string]explorer.ExpandFunc{
+			"person": func(ctx context.Context, name string) ([]explorer.Entity, error) {
+				places, err := c3ntralaSvc.QueryPeople(name)
+				if err != nil {
+					return nil, err
+				}
+				app.Log.Info("person seen in", "person", name, "places", places.Names(), "restricted", places.RestrictedCount)
+				return entities("place", places.Names()), nil
+			},
+			"place": func(ctx context.Context, city string) ([]explorer.Entity, error) {
+				people, err := c3ntralaSvc.QueryPlaces(city)
+				if err != nil {
+					return nil, err
+				}
+				app.Log.Info("people seen in place", "place", city, "people", people.Names(), "restricted", people.RestrictedCount)
+				return entities("person", people.Names()), nil
+			},
+		},
+		Relationships: map[string]string{
+			"person": "SEEN_IN",
+			"place":  "SEEN",
+		},
+		Normalize:   c3ntrala.NormalizeName,
+		MaxDepth:    10,
+		Concurrency: 4,
+		Stop: func(discovery explorer.Discovery) bool {
+			return discovery.From.Type == "place" &&
+				textpl.MatchName(discovery.To.Value, "Barbara") &&
+				!knownCities[discovery.From.Value]
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	result, err := loopExplorer.Run(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if result.Match == nil {
+		if len(result.Errors) > 0 {
+			return fmt.Errorf("BARBARA not found, %d expansions failed: %w", len(result.Errors), errors.Join(result.Errors...))
+		}
+		app.Log.Warn("BARBARA not found in any new place")
+		return nil
+	}
+
+	lastPlace := result.Match.From.Value
+	app.Log.Info("found BARBARA", "place", lastPlace)
+
+	if err := result.Graph.ExportToFile("cmd/s03e04/loop.dot", graph.ExportOptions{
+		Path: result.Graph.ShortestPath("person:BARBARA", "place:"+lastPlace),
+	}); err != nil {
+		app.Log.Warn("could not export graph", "error", err)
+	}
+
+	report, err := c3ntralaSvc.PostReport("loop", lastPlace, false)
+	if err != nil {
+		return err
+	}
+	app.Log.Info("report sent", "result", report)
+
+	return nil
+}
+
+// entities wraps raw values returned by C3ntrala into explorer entities
+func entities(entityType string, values []string) []explorer.Entity {
+	result := make([]explorer.Entity, 0, len(values))
+	for _, value := range values {
+		result = append(result, explorer.Entity{Type: entityType, Value: value})
+	}
+	return result
+}
+
+const extractNamesAndCitiesPrompt = `You are a helpful assistant that extracts names and cities from a text.
+
+<rules>
+- You should extract only first names and cities that are mentioned in the text.
+- Make sure the returned first name is in the nominative and without Polish characters like ą, ć, ę, ł, ń, ś, ź, ż. (e.g. RAFAL instead of Rafałowi Bombie).
+- Return only the first names and cities in the following format: {"names": ["FIRST_NAME1", "FIRST_NAME2", "FIRST_NAME3"], "cities": ["CITY1", "CITY2", "CITY3"]}. Plain JSON format. No other text or Markdown decorations.
+- If there are no names or cities, return {names: [], cities: []}.
+- Make sure firstnames and cities is distinct for each group.
+- Return only uppercase names and cities and without Polish characters 
+- Return only valid names in polish. Fix any issues in names like ALEKSANDER instead of ALEKSANDR.
+</rules>
+
+<examples>
+USER: [Typical input example]
+AI: {"names": ["FIRST_NAME1", "FIRST_NAME2", "FIRST_NAME3"], "cities": ["CITY1", "CITY2", "CITY3"]}
+</examples>
+
+Let's begin extracting names and cities from the text.
+`
@@ -1,4 +1,4 @@
-package main
+package s03e05
 
 import (
 	"context"
@@ -6,23 +6,25 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/crowmw/ai_devs3/pkg/c3ntrala"
-	"github.com/crowmw/ai_devs3/pkg/env"
+	"github.com/crowmw/ai_devs3/pkg/cli"
 	"github.com/crowmw/ai_devs3/pkg/graph"
 	"github.com/crowmw/ai_devs3/pkg/http"
 )
 
-func main() {
-	envSvc, err := env.NewService()
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
+func init() {
+	cli.Register(cli.Task{
+		Name:        "s03e05",
+		Description: "Find the shortest connection between Rafal and Barbara",
+		Run:         run,
+	})
+}
+
+func run(app *cli.Context) error {
+	envSvc := app.Env
 
 	users, err := http.PostSQLQueryToAPIDB(envSvc, "SELECT id, username FROM users")
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 	var usersResponse struct {
 		Reply []struct {
@@ -33,16 +35,14 @@ func main() {
 	}
 
 	if err := json.Unmarshal([]byte(users), &usersResponse); err != nil {
-		fmt.Println("Error parsing result:", err)
-		return
+		return fmt.Errorf("error parsing result: %w", err)
 	}
 
-	fmt.Println(len(usersResponse.Reply))
+	app.Log.Info("users loaded", "count", len(usersResponse.Reply))
 
 	connections, err := http.PostSQLQueryToAPIDB(envSvc, "SELECT * FROM connections")
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
 	var connectionsResponse struct {
@@ -54,36 +54,30 @@ func main() {
 	}
 
 	if err := json.Unmarshal([]byte(connections), &connectionsResponse); err != nil {
-		fmt.Println("Error parsing result:", err)
-		return
+		return fmt.Errorf("error parsing result: %w", err)
 	}
 
-	fmt.Println(len(connectionsResponse.Reply))
+	app.Log.Info("connections loaded", "count", len(connectionsResponse.Reply))
 
 	graphSvc, err := graph.NewService(context.Background(), envSvc)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
-	fmt.Println("Graph service created", graphSvc)
-
 	for _, user := range usersResponse.Reply {
 		err := graphSvc.CreatePerson(context.Background(), graph.PersonNode{
 			OriginalID: user.ID,
 			Username:   user.Username,
 		})
 		if err != nil {
-			fmt.Println(err)
-			return
+			return err
 		}
 	}
 
 	for _, connection := range connectionsResponse.Reply {
 		err := graphSvc.CreateRelationship(context.Background(), connection.User1_id, connection.User2_id, "KNOWS")
 		if err != nil {
-			fmt.Println(err)
-			return
+			return err
 		}
 	}
 
@@ -94,7 +88,7 @@ func main() {
 			break
 		}
 	}
-	fmt.Println("RAFAŁ's ID:", rafalID)
+	app.Log.Info("found user", "username", "Rafał", "id", rafalID)
 	var barbaraID string
 	for _, user := range usersResponse.Reply {
 		if user.Username == "Barbara" {
@@ -102,27 +96,36 @@ func main() {
 			break
 		}
 	}
-	fmt.Println("BARBARA's ID:", barbaraID)
+	app.Log.Info("found user", "username", "Barbara", "id", barbaraID)
 
 	shortestConnection, err := graphSvc.GetShortestConnection(context.Background(), rafalID, barbaraID)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
+	}
+
+	connectionsGraph, err := graphSvc.LoadGraph(context.Background())
+	if err != nil {
+		return err
+	}
+	for _, exportPath := range []string{"cmd/s03e05/connections.dot", "cmd/s03e05/connections.json"} {
+		if err := connectionsGraph.ExportToFile(exportPath, graph.ExportOptions{Path: shortestConnection}); err != nil {
+			app.Log.Warn("could not export graph", "path", exportPath, "error", err)
+		}
 	}
 
 	joined := strings.Join(shortestConnection, ",")
-	fmt.Println(joined)
+	app.Log.Info("shortest connection", "path", joined)
 
-	c3ntralaSvc, err := c3ntrala.NewService(envSvc)
+	c3ntralaSvc, err := app.C3ntrala()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
-	apiResponse, err := c3ntralaSvc.PostReport("connections", joined)
+	apiResponse, err := c3ntralaSvc.PostReport("connections", joined, false)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
-	fmt.Println("❇️ API response:", apiResponse)
+	app.Log.Info("report sent", "result", apiResponse)
+
+	return nil
 }
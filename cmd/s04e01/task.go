@@ -0,0 +1,55 @@
+package s04e01
+
+import (
+	"github.com/crowmw/ai_devs3/pkg/cli"
+	photosautomate "github.com/crowmw/ai_devs3/pkg/photos-automate"
+	"github.com/crowmw/ai_devs3/pkg/recognize"
+)
+
+func init() {
+	cli.Register(cli.Task{
+		Name:        "s04e01",
+		Description: "Fix photos and describe Barbara",
+		Run:         run,
+	})
+}
+
+func run(app *cli.Context) error {
+	envSvc := app.Env
+
+	aiSvc, err := app.AI()
+	if err != nil {
+		return err
+	}
+
+	c3ntralaSvc, err := app.C3ntrala()
+	if err != nil {
+		return err
+	}
+
+	photosSvc, err := photosautomate.NewService(envSvc, c3ntralaSvc)
+	if err != nil {
+		return err
+	}
+
+	recognizeSvc, err := recognize.NewService(envSvc, aiSvc, photosSvc, c3ntralaSvc)
+	if err != nil {
+		return err
+	}
+
+	barbaraDescription, err := recognizeSvc.StartRecognize()
+	if err != nil {
+		return err
+	}
+
+	app.Log.Info("person described", "description", barbaraDescription)
+
+	answer, err := c3ntralaSvc.PostReport("photos", barbaraDescription, false)
+	if err != nil {
+		return err
+	}
+
+	app.Log.Info("report sent", "result", answer)
+
+	return nil
+}
@@ -1,39 +1,38 @@
-package main
+package s04e02
 
 import (
 	"fmt"
 	"os"
 
 	"github.com/crowmw/ai_devs3/pkg/ai"
-	"github.com/crowmw/ai_devs3/pkg/c3ntrala"
-	"github.com/crowmw/ai_devs3/pkg/env"
+	"github.com/crowmw/ai_devs3/pkg/cli"
 	"github.com/crowmw/ai_devs3/pkg/processor"
 	"github.com/sashabaranov/go-openai"
 )
 
-func main() {
-	envSvc, err := env.NewService()
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
+func init() {
+	cli.Register(cli.Task{
+		Name:        "s04e02",
+		Description: "Classify research samples with a fine-tuned model",
+		Run:         run,
+	})
+}
 
-	aiSvc, err := ai.NewService(envSvc)
+func run(app *cli.Context) error {
+
+	aiSvc, err := app.AI()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
-	c3ntralaSvc, err := c3ntrala.NewService(envSvc)
+	c3ntralaSvc, err := app.C3ntrala()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
 	file, err := os.ReadFile("lab_data/verify.txt")
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
 	lines := processor.ReadLinesFromTextFile(file)
@@ -43,7 +42,7 @@ func main() {
 		}
 	}
 
-	fmt.Println("❇️ Lines:", lines)
+	app.Log.Debug("lines to classify", "lines", lines)
 
 	var correctLines []string
 	for index, line := range lines {
@@ -55,8 +54,7 @@ func main() {
 			},
 		})
 		if err != nil {
-			fmt.Println(err)
-			return
+			return err
 		}
 
 		if answer.Choices[0].Message.Content == "1" {
@@ -64,13 +62,14 @@ func main() {
 		}
 	}
 
-	fmt.Println("❇️ Correct lines:", correctLines)
+	app.Log.Info("correct lines", "lines", correctLines)
 
-	reportResponse, err := c3ntralaSvc.PostReport("research", correctLines)
+	reportResponse, err := c3ntralaSvc.PostReport("research", correctLines, false)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
-	fmt.Println("❇️ Report response:", reportResponse)
+	app.Log.Info("report sent", "result", reportResponse)
+
+	return nil
 }
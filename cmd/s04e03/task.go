@@ -0,0 +1,72 @@
+package s04e03
+
+import (
+	"github.com/crowmw/ai_devs3/pkg/cli"
+	"github.com/crowmw/ai_devs3/pkg/softo"
+)
+
+func init() {
+	cli.Register(cli.Task{
+		Name:        "s04e03",
+		Description: "Answer questions by crawling the SoftoAI site",
+		Run:         run,
+	})
+}
+
+func run(app *cli.Context) error {
+	envSvc := app.Env
+
+	c3ntralaSvc, err := app.C3ntrala()
+	if err != nil {
+		return err
+	}
+
+	aiSvc, err := app.AI()
+	if err != nil {
+		return err
+	}
+
+	questions, err := c3ntralaSvc.GetSoftoQuestions()
+	if err != nil {
+		return err
+	}
+
+	app.Log.Info("questions loaded", "questions", questions)
+
+	softoSvc, err := softo.NewService(envSvc, aiSvc)
+	if err != nil {
+		return err
+	}
+
+	answers := make(map[string]string)
+	for i, question := range questions {
+		currentUrl := ""
+		for {
+			answer, err := softoSvc.TryToFindAnswer(question, currentUrl)
+			if err != nil {
+				return err
+			}
+
+			if answer.Response == "YES" {
+				app.Log.Info("answer found", "question", i, "answer", answer.Answer)
+				answers[i] = answer.Answer
+				break
+			} else if answer.Response == "NO" {
+				app.Log.Info("following link", "question", i, "url", answer.Answer)
+				currentUrl = answer.Answer
+				continue
+			}
+		}
+	}
+
+	app.Log.Info("all answers found", "answers", answers)
+
+	report, err := c3ntralaSvc.PostReport("softo", answers, false)
+	if err != nil {
+		return err
+	}
+
+	app.Log.Info("report sent", "result", report)
+
+	return nil
+}
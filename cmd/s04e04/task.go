@@ -1,16 +1,17 @@
-package main
+package s04e04
 
 import (
 	"fmt"
 	"time"
 
 	"encoding/json"
-	"log"
 	"net/http"
+	"os"
 
 	"github.com/crowmw/ai_devs3/pkg/ai"
-	"github.com/crowmw/ai_devs3/pkg/c3ntrala"
+	"github.com/crowmw/ai_devs3/pkg/cli"
 	"github.com/crowmw/ai_devs3/pkg/env"
+	"github.com/crowmw/ai_devs3/pkg/logging"
 	"github.com/sashabaranov/go-openai"
 )
 
@@ -30,7 +31,7 @@ func handleDroneMovement(w http.ResponseWriter, r *http.Request, aiSvc *ai.Servi
 		return
 	}
 
-	fmt.Println("Instruction:", instruction.Instruction)
+	logger.Info("instruction received", "instruction", instruction.Instruction)
 
 	response, err := aiSvc.ChatCompletion(ai.ChatCompletionConfig{
 		Model: "gpt-4.1",
@@ -46,6 +47,7 @@ func handleDroneMovement(w http.ResponseWriter, r *http.Request, aiSvc *ai.Servi
 		},
 	})
 	if err != nil {
+		logger.Error("could not get AI response", "error", err)
 		http.Error(w, "Failed to get AI response", http.StatusInternalServerError)
 		return
 	}
@@ -56,7 +58,7 @@ func handleDroneMovement(w http.ResponseWriter, r *http.Request, aiSvc *ai.Servi
 	}
 	json.Unmarshal([]byte(response.Choices[0].Message.Content), &landingPosition)
 
-	fmt.Println("AI response:", landingPosition)
+	logger.Info("landing position", "description", landingPosition.Description, "thinking", landingPosition.Thinking)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -65,23 +67,28 @@ func handleDroneMovement(w http.ResponseWriter, r *http.Request, aiSvc *ai.Servi
 	})
 }
 
-func main() {
-	envSvc, err := env.NewService()
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
+var logger = logging.New("cli").With(logging.KeyTask, "s04e04")
+
+func init() {
+	cli.Register(cli.Task{
+		Name:        "s04e04",
+		Description: "Serve the drone navigation webhook",
+		Required:    []string{env.KeyNGrokURL},
+		Run:         run,
+	})
+}
+
+func run(app *cli.Context) error {
+	envSvc := app.Env
 
-	aiSvc, err := ai.NewService(envSvc)
+	aiSvc, err := app.AI()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
-	c3ntralaSvc, err := c3ntrala.NewService(envSvc)
+	c3ntralaSvc, err := app.C3ntrala()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
 	// Set up HTTP server
@@ -89,24 +96,24 @@ func main() {
 		handleDroneMovement(w, r, aiSvc)
 	})
 
-	log.Println("Starting HTTP server on :8080")
+	app.Log.Info("starting HTTP server", "addr", ":8080")
 
 	// Start server in a goroutine
 	go func() {
 		if err := http.ListenAndServe(":8080", nil); err != nil {
-			log.Fatal("Server error:", err)
+			app.Log.Error("server error", "error", err)
+			os.Exit(1)
 		}
 	}()
 
 	// Wait a bit for server to start
 	time.Sleep(2 * time.Second)
 
-	centralaResponse, err := c3ntralaSvc.PostReport("webhook", envSvc.GetNGrokURL()+"/drone")
+	centralaResponse, err := c3ntralaSvc.PostReport("webhook", envSvc.GetNGrokURL()+"/drone", false)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
-	fmt.Println("Centrala response:", centralaResponse)
+	app.Log.Info("report sent", "result", centralaResponse)
 
 	// Keep main goroutine alive
 	select {}
@@ -1,7 +1,8 @@
-package main
+package s04e05
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -9,30 +10,19 @@ import (
 
 	"github.com/crowmw/ai_devs3/pkg/ai"
 	"github.com/crowmw/ai_devs3/pkg/c3ntrala"
-	"github.com/crowmw/ai_devs3/pkg/config"
-	"github.com/crowmw/ai_devs3/pkg/env"
-	"github.com/crowmw/ai_devs3/pkg/http"
+	"github.com/crowmw/ai_devs3/pkg/cli"
+	"github.com/crowmw/ai_devs3/pkg/logging"
 	"github.com/crowmw/ai_devs3/pkg/processor"
 	"github.com/sashabaranov/go-openai"
 )
 
-type HintResponse struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Hint    string `json:"hint"`
-	Debug   string `json:"debug"`
-}
-
 type Answer struct {
 	Thinking string `json:"_thinking"`
 	Answer   string `json:"answer"`
 }
 
 func processSingleQuestion(aiSvc *ai.Service, questionID string, question string, systemPrompt string) (*Answer, error) {
-	fmt.Printf("Processing question %s: %s\n", questionID, question)
-	fmt.Println("Question:")
-	fmt.Println(question)
-	fmt.Println("--------------------------------")
+	logger.Info("processing question", "id", questionID, "question", question)
 
 	response, err := aiSvc.ChatCompletion(ai.ChatCompletionConfig{
 		Model: "gpt-4.1",
@@ -56,10 +46,7 @@ func processSingleQuestion(aiSvc *ai.Service, questionID string, question string
 		return nil, fmt.Errorf("error parsing answer: %w", err)
 	}
 
-	fmt.Println("Answer:")
-	fmt.Println(answer)
-	fmt.Println("--------------------------------")
-	fmt.Printf("Answer for question %s: %s\n\n", questionID, answer.Answer)
+	logger.Info("question answered", "id", questionID, "answer", answer.Answer, "thinking", answer.Thinking)
 
 	return &answer, nil
 }
@@ -73,20 +60,19 @@ func buildSystemPrompt(currentDate, previousAnswers, hints, context string) stri
 }
 
 func checkAndProcessIncorrectAnswer(aiSvc *ai.Service, c3ntralaSvc *c3ntrala.Service, questions map[string]string, answers map[string]string, previousAnswers, hints, context string) (bool, error) {
-	hint, err := c3ntralaSvc.PostReport("notes", answers)
-	if err != nil {
+	// An incorrect answer comes back as an API error, but the result still carries the hint
+	hintResp, err := c3ntralaSvc.PostReport("notes", answers, false)
+	var apiErr *c3ntrala.APIError
+	if err != nil && !errors.As(err, &apiErr) {
 		return false, fmt.Errorf("error submitting answers: %w", err)
 	}
 
-	var hintResp HintResponse
-	if err := json.Unmarshal([]byte(hint), &hintResp); err != nil {
-		return false, fmt.Errorf("error parsing hint: %w", err)
+	if hintResp.DryRun {
+		// nothing was sent, so there is no feedback to improve the answers with
+		logger.Warn("dry run, answers saved to the outbox without feedback", "result", hintResp.Message)
+		return false, nil
 	}
-
-	fmt.Printf("Code: %d\n", hintResp.Code)
-	fmt.Printf("Message: %s\n", hintResp.Message)
-	fmt.Printf("Hint: %s\n", hintResp.Hint)
-	fmt.Printf("Debug: %s\n", hintResp.Debug)
+	logger.Info("answers checked", "code", hintResp.Code, "message", hintResp.Message, "hint", hintResp.Hint, "debug", hintResp.Debug)
 
 	if !strings.Contains(hintResp.Message, "is incorrect") {
 		return false, nil
@@ -147,62 +133,61 @@ func processQuestions(aiSvc *ai.Service, c3ntralaSvc *c3ntrala.Service, question
 	return answers, nil
 }
 
-func main() {
-	envSvc, err := env.NewService()
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
+var logger = logging.New("cli").With(logging.KeyTask, "s04e05")
+
+func init() {
+	cli.Register(cli.Task{
+		Name:        "s04e05",
+		Description: "Answer questions from Rafal's notebook",
+		Run:         run,
+	})
+}
+
+func run(app *cli.Context) error {
 
-	aiSvc, err := ai.NewService(envSvc)
+	aiSvc, err := app.AI()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
-	c3ntralaSvc, err := c3ntrala.NewService(envSvc)
+	c3ntralaSvc, err := app.C3ntrala()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
-	pdfSvc, err := processor.NewPDFService(envSvc.GetC3ntralaURL()+"/dane/notatnik-rafala.pdf", aiSvc)
+	pdfSvc, err := processor.NewPDFService(c3ntralaSvc.Resources().URL(c3ntrala.ResourceRafalNotebook), aiSvc)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
 	text, err := pdfSvc.GetText()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
 	image, err := pdfSvc.ExtractTextFromPage(19)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
 	fullPDFText := "<pdf_text>" + text + "</pdf_text>" + "\n" + "<pdf_last_page_image>" + image + "</pdf_last_page_image>"
 
 	var questions map[string]string
-	err = http.FetchJSONData(envSvc.GetC3ntralaURL()+"/data/"+config.GetMyAPIKey()+"/notes.json", &questions)
+	err = c3ntralaSvc.Resources().LoadJSON(c3ntrala.ResourceNotes, &questions)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
 	answers, err := processQuestions(aiSvc, c3ntralaSvc, questions, fullPDFText)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
-	fmt.Println("\nFinal answers:")
 	for questionID, answer := range answers {
-		fmt.Printf("Question %s: %s\n", questionID, answer)
+		app.Log.Info("final answer", "id", questionID, "answer", answer)
 	}
+
+	return nil
 }
 
 const answerQuestionsSystemPrompt = `
@@ -1,4 +1,4 @@
-package main
+package s05e01
 
 import (
 	"encoding/json"
@@ -8,7 +8,7 @@ import (
 
 	"github.com/crowmw/ai_devs3/pkg/ai"
 	"github.com/crowmw/ai_devs3/pkg/c3ntrala"
-	"github.com/crowmw/ai_devs3/pkg/env"
+	"github.com/crowmw/ai_devs3/pkg/cli"
 	"github.com/crowmw/ai_devs3/pkg/factory"
 	"github.com/sashabaranov/go-openai"
 )
@@ -38,71 +38,67 @@ func questionsToString(questions map[string]string) string {
 	}`, questions["01"], questions["02"], questions["03"], questions["04"], questions["05"], questions["06"])
 }
 
-func main() {
-	envSvc, err := env.NewService()
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
+func init() {
+	cli.Register(cli.Task{
+		Name:        "s05e01",
+		Description: "Reconstruct phone conversations and answer questions",
+		Run:         run,
+	})
+}
+
+func run(app *cli.Context) error {
+	envSvc := app.Env
 
-	aiSvc, err := ai.NewService(envSvc)
+	aiSvc, err := app.AI()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
-	c3ntralaSvc, err := c3ntrala.NewService(envSvc)
+	c3ntralaSvc, err := app.C3ntrala()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
-	questions, err := c3ntralaSvc.GetQuestions("/phone_questions.json")
+	questions, err := c3ntralaSvc.GetQuestions(c3ntrala.ResourcePhoneQuestion)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
-	fmt.Println(questions)
+	app.Log.Debug("questions loaded", "questions", questions)
 
 	// Write questions to questions.json
 	jsonData, err := json.MarshalIndent(questions, "", "  ")
 	if err != nil {
-		fmt.Println("Error marshaling questions:", err)
-		return
+		return fmt.Errorf("error marshaling questions: %w", err)
 	}
 	err = os.WriteFile("cmd/s05e01/questions.json", jsonData, 0644)
 	if err != nil {
-		fmt.Println("Error writing questions file:", err)
-		return
+		return fmt.Errorf("error writing questions file: %w", err)
 	}
-	fmt.Println("Successfully wrote questions to questions.json")
+	app.Log.Info("questions saved", "path", "cmd/s05e01/questions.json")
 
-	factory, err := factory.NewFactory(envSvc)
+	factory, err := factory.NewFactory(envSvc, aiSvc)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
 	facts, err := factory.GetFactsFilesKeywords()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
 	var phoneData c3ntrala.PhoneData
 	// Check if file exists and load data if it does
 	if data, err := os.ReadFile("cmd/s05e01/conversationWithSpeakers.json"); err == nil {
 		if err := json.Unmarshal(data, &phoneData); err == nil {
-			fmt.Println("Using cached conversation data")
+			app.Log.Info("using cached conversation data")
 		} else {
-			fmt.Println("Error unmarshalling cached data:", err)
+			app.Log.Warn("could not parse cached conversation data", "error", err)
 		}
 	} else {
 		phoneData, err = c3ntralaSvc.GetPhoneData()
 		if err != nil {
-			fmt.Println(err)
-			return
+			return err
 		}
 
 		response, err := aiSvc.ChatCompletion(ai.ChatCompletionConfig{
@@ -119,30 +115,26 @@ func main() {
 			},
 		})
 		if err != nil {
-			fmt.Println(err)
-			return
+			return err
 		}
 
 		if err := json.Unmarshal([]byte(response.Choices[0].Message.Content), &phoneData); err != nil {
-			fmt.Println("Error parsing AI response:", err)
-			fmt.Println("Raw response:", response.Choices[0].Message.Content)
-			return
+			app.Log.Error("could not parse AI response", "error", err, "response", response.Choices[0].Message.Content)
+			return nil
 		}
 
 		jsonData, err := json.MarshalIndent(phoneData, "", "  ")
 		if err != nil {
-			fmt.Println("Error marshaling data:", err)
-			return
+			return fmt.Errorf("error marshaling data: %w", err)
 		}
 
 		err = os.WriteFile("cmd/s05e01/conversationWithSpeakers.json", jsonData, 0644)
 		if err != nil {
-			fmt.Println("Error writing file:", err)
-			return
+			return fmt.Errorf("error writing file: %w", err)
 		}
 	}
 
-	fmt.Println("Successfully recognize speakers in the conversation")
+	app.Log.Info("speakers recognized")
 
 	liarRecognition, err := aiSvc.ChatCompletion(ai.ChatCompletionConfig{
 		Model: "gpt-4.1",
@@ -158,8 +150,7 @@ func main() {
 		},
 	})
 	if err != nil {
-		fmt.Println("Error getting liar recognition:", err)
-		return
+		return fmt.Errorf("error getting liar recognition: %w", err)
 	}
 
 	type LiarRecognition struct {
@@ -169,14 +160,13 @@ func main() {
 	}
 	var liarRecognitionResult LiarRecognition
 	if err := json.Unmarshal([]byte(liarRecognition.Choices[0].Message.Content), &liarRecognitionResult); err != nil {
-		fmt.Println("Error unmarshalling liar recognition result:", err)
-		return
+		return fmt.Errorf("error unmarshalling liar recognition result: %w", err)
 	}
 
-	fmt.Println("Liar Recognition Result:")
-	fmt.Println("Liar:", liarRecognitionResult.Liar)
-	fmt.Println("Reasoning:", liarRecognitionResult.Reasoning)
-	fmt.Println("Evidence:", liarRecognitionResult.Evidence)
+	app.Log.Info("liar recognized",
+		"liar", liarRecognitionResult.Liar,
+		"reasoning", liarRecognitionResult.Reasoning,
+		"evidence", liarRecognitionResult.Evidence)
 
 	responses, err := aiSvc.ChatCompletion(ai.ChatCompletionConfig{
 		Model: "gpt-4.1",
@@ -192,27 +182,26 @@ func main() {
 		},
 	})
 	if err != nil {
-		fmt.Println("Error getting responses:", err)
-		return
+		return fmt.Errorf("error getting responses: %w", err)
 	}
 
-	fmt.Println("Responses:", responses.Choices[0].Message.Content)
+	app.Log.Debug("answers generated", "response", responses.Choices[0].Message.Content)
 
 	var responsesResult map[string]interface{}
 	if err := json.Unmarshal([]byte(responses.Choices[0].Message.Content), &responsesResult); err != nil {
-		fmt.Println("Error unmarshalling responses result:", err)
-		return
+		return fmt.Errorf("error unmarshalling responses result: %w", err)
 	}
 
-	fmt.Println("Responses Result:", responsesResult)
+	app.Log.Info("answers parsed", "answers", responsesResult)
 
-	c3ntralaResponse, err := c3ntralaSvc.PostReport("phone", responsesResult)
+	c3ntralaResponse, err := c3ntralaSvc.PostReport("phone", responsesResult, false)
 	if err != nil {
-		fmt.Println("Error posting report:", err)
-		return
+		return fmt.Errorf("error posting report: %w", err)
 	}
 
-	fmt.Println("C3ntrala Response:", c3ntralaResponse)
+	app.Log.Info("report sent", "result", c3ntralaResponse)
+
+	return nil
 }
 
 const detectSpeakersSystemPrompt = `You are a helpful assistant that can detect speakers in the conversation. 
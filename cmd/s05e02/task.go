@@ -0,0 +1,128 @@
+package s05e02
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/crowmw/ai_devs3/pkg/agent"
+	"github.com/crowmw/ai_devs3/pkg/c3ntrala"
+	"github.com/crowmw/ai_devs3/pkg/cli"
+	"github.com/crowmw/ai_devs3/pkg/eval"
+	"github.com/crowmw/ai_devs3/pkg/gps_agent"
+	"github.com/sashabaranov/go-openai"
+)
+
+func init() {
+	cli.Register(cli.Task{
+		Name:        "s05e02",
+		Description: "Locate people with the GPS agent",
+		Run:         run,
+	})
+	eval.Register(eval.Suite{
+		Agent:       "gps",
+		Description: "Locate people with mocked C3ntrala responses",
+		Dir:         "fixtures/evals/gps",
+		Options:     func() agent.Options { return gps_agent.Options(systemMessages("")) },
+		Synthesize: func(a *agent.Agent) (interface{}, error) {
+			answer, err := agent.Synthesize[Locations](a)
+			if err != nil {
+				return nil, err
+			}
+			return answer.Answer, nil
+		},
+	})
+}
+
+// Location is where a person is, the format expected by C3ntrala
+type Location struct {
+	Lat float64 `json:"lat" description:"latitude"`
+	Lon float64 `json:"lon" description:"longitude"`
+}
+
+// Locations maps person names to their locations
+type Locations map[string]Location
+
+// systemMessages give the gps agent its context, scenarios of the eval suite bring their own logs
+func systemMessages(logs string) []openai.ChatCompletionMessage {
+	return []openai.ChatCompletionMessage{
+		{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: fmt.Sprintf("You are an AI assistant helping analyze GPS logs. You are detail-oriented and methodical in your analysis. Here are the logs: %s", logs),
+		},
+	}
+}
+
+func run(app *cli.Context) error {
+	envSvc := app.Env
+
+	c3ntralaSvc, err := app.C3ntrala()
+	if err != nil {
+		return err
+	}
+
+	aiSvc, err := app.AI()
+	if err != nil {
+		return err
+	}
+
+	logs, err := c3ntralaSvc.GetLogs()
+	if err != nil {
+		return err
+	}
+
+	oversight, err := app.AgentOversight()
+	if err != nil {
+		return err
+	}
+
+	gpsAgentSvc, err := gps_agent.NewService(envSvc, aiSvc, c3ntralaSvc, systemMessages(logs), app.AgentStore(), oversight, app.Options.Limits)
+	if err != nil {
+		return err
+	}
+
+	answer, err := execute(app, c3ntralaSvc, gpsAgentSvc)
+	var abortErr *agent.AbortError
+	if errors.As(err, &abortErr) {
+		// what the run found before it stopped, e.g. to answer by hand or resume with higher limits
+		for _, evidence := range abortErr.Partial {
+			app.Log.Info("partial result", "task", evidence.Task, "tool", evidence.Tool, "payload", evidence.Payload, "result", evidence.Result)
+		}
+		app.Log.Warn("agent stopped", "termination", abortErr.Termination, "tokens", abortErr.Usage.TotalTokens(), "cost", abortErr.Usage.Cost)
+	}
+	if err != nil {
+		return err
+	}
+
+	app.Log.Info("agent answered", "answer", answer.Answer, "evidence", len(answer.Evidence))
+	for _, evidence := range answer.Evidence {
+		app.Log.Debug("answer evidence", "task", evidence.Task, "tool", evidence.Tool, "payload", evidence.Payload, "result", evidence.Result)
+	}
+
+	response, err := c3ntralaSvc.PostReport("gps", answer.Answer, false)
+	if err != nil {
+		return err
+	}
+
+	app.Log.Info("report sent", "result", response)
+
+	return nil
+}
+
+// execute asks the question of the task, or continues the run given by --resume
+func execute(app *cli.Context, c3ntralaSvc *c3ntrala.Service, gpsAgentSvc *gps_agent.Service) (*agent.Answer[Locations], error) {
+	if app.Options.Resume != "" {
+		runID, step, err := agent.ParseRunRef(app.Options.Resume)
+		if err != nil {
+			return nil, err
+		}
+		app.Log.Info("resuming agent run", "run_id", runID, "step", step)
+		return gps_agent.Resume[Locations](gpsAgentSvc, runID, step)
+	}
+
+	question, err := c3ntralaSvc.GetGpsQuestion()
+	if err != nil {
+		return nil, err
+	}
+	app.Log.Info("question loaded", "question", question)
+	return gps_agent.Execute[Locations](gpsAgentSvc, question)
+}
@@ -1,4 +1,4 @@
-package main
+package s05e03
 
 import (
 	"fmt"
@@ -10,8 +10,9 @@ import (
 	"time"
 
 	"github.com/crowmw/ai_devs3/pkg/ai"
-	"github.com/crowmw/ai_devs3/pkg/env"
+	"github.com/crowmw/ai_devs3/pkg/cli"
 	"github.com/crowmw/ai_devs3/pkg/http"
+	"github.com/crowmw/ai_devs3/pkg/logging"
 	"github.com/crowmw/ai_devs3/pkg/processor"
 	"github.com/sashabaranov/go-openai"
 )
@@ -46,7 +47,7 @@ func processQuestionAsync(questionURL string, resultChan chan<- []Question, wg *
 
 	response, err := fetchQuestionData(questionURL)
 	if err != nil {
-		fmt.Printf("Warning: %v\n", err)
+		logger.Warn("could not fetch questions", "url", questionURL, "error", err)
 		return
 	}
 
@@ -182,7 +183,7 @@ func processAIQuestionAsync(aiSvc *ai.Service, question Question, resultChan cha
 
 	response, err := processAIResponse(aiSvc, question, urlMatch)
 	if err != nil {
-		fmt.Printf("Warning: %v\n", err)
+		logger.Warn("could not answer question", "task", question.Task, "error", err)
 		return
 	}
 
@@ -217,17 +218,22 @@ func collectAIResponses(aiSvc *ai.Service, questions []Question) []string {
 	return responses
 }
 
-func main() {
-	envSvc, err := env.NewService()
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
+var logger = logging.New("cli").With(logging.KeyTask, "s05e03")
+
+func init() {
+	cli.Register(cli.Task{
+		Name:        "s05e03",
+		Description: "Answer the timed RafalBot challenge",
+		Run:         run,
+	})
+}
+
+func run(app *cli.Context) error {
+	envSvc := app.Env
 
-	aiSvc, err := ai.NewService(envSvc)
+	aiSvc, err := app.AI()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
 	// Time starts here
@@ -245,8 +251,7 @@ func main() {
 	}
 	err = http.POSTJSONData("https://rafal.ag3nts.org/b46c3", hashBody, &hashResponse)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
 	hash := hashResponse.Message
@@ -268,8 +273,7 @@ func main() {
 
 	err = http.POSTJSONData("https://rafal.ag3nts.org/b46c3", signatureBody, &signatureResponse)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
 	questions := signatureResponse.Message.Challenges
@@ -280,10 +284,7 @@ func main() {
 	// Process all questions through AI service concurrently
 	responses := collectAIResponses(aiSvc, allQuestions)
 
-	// Print all responses
-	for _, response := range responses {
-		fmt.Println(response)
-	}
+	app.Log.Info("questions answered", "responses", responses)
 
 	type ResponseBody struct {
 		ApiKey    string   `json:"apikey"`
@@ -300,12 +301,12 @@ func main() {
 	}
 	response, err := http.SendPost("https://rafal.ag3nts.org/b46c3", responseBody)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
-	fmt.Println("Response: ", response)
+	app.Log.Info("answers sent", "result", response)
+
+	app.Log.Info("finished", "elapsed", time.Since(startTime))
 
-	// Time ends here
-	fmt.Println("Time taken: ", time.Since(startTime))
+	return nil
 }
@@ -1,16 +1,22 @@
-package main
+package s05e04
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"encoding/json"
-	"log"
 	"net/http"
+	"os"
+	"path/filepath"
 
+	"github.com/crowmw/ai_devs3/pkg/agent"
 	"github.com/crowmw/ai_devs3/pkg/ai"
 	"github.com/crowmw/ai_devs3/pkg/c3ntrala"
+	"github.com/crowmw/ai_devs3/pkg/cli"
 	"github.com/crowmw/ai_devs3/pkg/env"
+	"github.com/crowmw/ai_devs3/pkg/logging"
+	"github.com/crowmw/ai_devs3/pkg/memory"
 	"github.com/crowmw/ai_devs3/pkg/serce_agent"
 )
 
@@ -26,7 +32,7 @@ func handleSerce(w http.ResponseWriter, r *http.Request, agent *serce_agent.Serv
 
 	var questionBody QuestionBody
 	if err := json.NewDecoder(r.Body).Decode(&questionBody); err != nil {
-		fmt.Println("Error decoding request body:", err)
+		logger.Warn("could not decode request body", "error", err)
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
@@ -34,12 +40,12 @@ func handleSerce(w http.ResponseWriter, r *http.Request, agent *serce_agent.Serv
 	answer, err := agent.Execute(questionBody.Question)
 
 	if err != nil {
-		fmt.Println("Error executing agent:", err)
+		logger.Error("agent failed", "question", questionBody.Question, "error", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	fmt.Println("Question:", questionBody.Question)
+	logger.Info("question answered", "question", questionBody.Question, "answer", answer)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -48,42 +54,62 @@ func handleSerce(w http.ResponseWriter, r *http.Request, agent *serce_agent.Serv
 	})
 }
 
-func main() {
-	envSvc, err := env.NewService()
+var logger = logging.New("cli").With(logging.KeyTask, "s05e04")
+
+func init() {
+	cli.Register(cli.Task{
+		Name:        "s05e04",
+		Description: "Serve the robots heart webhook agent",
+		Required:    []string{env.KeyNGrokURL},
+		Run:         run,
+	})
+}
+
+func run(app *cli.Context) error {
+	envSvc := app.Env
+
+	aiSvc, err := app.AI()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
-	aiSvc, err := ai.NewService(envSvc)
+	c3ntralaSvc, err := app.C3ntrala()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
-	c3ntralaSvc, err := c3ntrala.NewService(envSvc)
+	oversight, err := app.AgentOversight()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
 
-	agent, err := serce_agent.NewService(envSvc, aiSvc, c3ntralaSvc, initAgentSystemPrompt)
+	// facts from the verification conversations are only useful for a day
+	memoryOpts := memory.Options{
+		Path: filepath.Join(app.Options.CacheDir, "memory", "serce.json"),
+		TTL:  24 * time.Hour,
+	}
+	serceAgent, err := serce_agent.NewService(envSvc, aiSvc, c3ntralaSvc, initAgentSystemPrompt, app.AgentStore(), memoryOpts, oversight, app.Options.Limits)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
+	}
+	if app.Options.Resume != "" {
+		if err := restore(app, serceAgent); err != nil {
+			return err
+		}
 	}
 
 	// Set up HTTP server
 	http.HandleFunc("/serce", func(w http.ResponseWriter, r *http.Request) {
-		handleSerce(w, r, agent)
+		handleSerce(w, r, serceAgent)
 	})
 
-	log.Println("Starting HTTP server on :8080")
+	app.Log.Info("starting HTTP server", "addr", ":8080")
 
 	// Start server in a goroutine
 	go func() {
 		if err := http.ListenAndServe(":8080", nil); err != nil {
-			log.Fatal("Server error:", err)
+			app.Log.Error("server error", "error", err)
+			os.Exit(1)
 		}
 	}()
 
@@ -91,10 +117,10 @@ func main() {
 	time.Sleep(2 * time.Second)
 
 	for {
-		centralaResponse, err := c3ntralaSvc.PostReport("serce", envSvc.GetNGrokURL()+"/serce", true)
-		if err != nil {
-			fmt.Println(err)
-			return
+		centralaResponse, err := c3ntralaSvc.Converse("serce", envSvc.GetNGrokURL()+"/serce", true)
+		var apiErr *c3ntrala.APIError
+		if err != nil && !errors.As(err, &apiErr) {
+			return err
 		}
 
 		type CentralaResponse struct {
@@ -106,19 +132,33 @@ func main() {
 		}
 
 		var centralaResponseData CentralaResponse
-		if err := json.Unmarshal([]byte(centralaResponse), &centralaResponseData); err != nil {
-			fmt.Println("Error unmarshalling centrala response:", err)
-			return
+		if err := centralaResponse.Decode(&centralaResponseData); err != nil {
+			return fmt.Errorf("error unmarshalling centrala response: %w", err)
 		}
 
-		fmt.Println("Centrala response:", centralaResponseData)
-		agent.Hack(centralaResponseData.Output)
+		app.Log.Info("centrala response", "response", centralaResponseData)
+		if _, err := serceAgent.Hack(centralaResponseData.Output); err != nil {
+			// out of steps or budget every next message fails the same way
+			var abortErr *agent.AbortError
+			var budgetErr *ai.BudgetError
+			if errors.As(err, &abortErr) || errors.As(err, &budgetErr) {
+				return fmt.Errorf("serce agent stopped: %w", err)
+			}
+			app.Log.Warn("could not answer centrala", "error", err)
+		}
 
 		time.Sleep(1 * time.Second) // Add small delay between requests
 	}
+}
 
-	// Keep main goroutine alive
-	select {}
+// restore continues the conversation of the run given by --resume
+func restore(app *cli.Context, serceAgent *serce_agent.Service) error {
+	runID, step, err := agent.ParseRunRef(app.Options.Resume)
+	if err != nil {
+		return err
+	}
+	app.Log.Info("restoring agent conversation", "run_id", runID, "step", step)
+	return serceAgent.Restore(runID, step)
 }
 
 const initAgentSystemPrompt = `
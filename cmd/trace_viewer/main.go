@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/crowmw/ai_devs3/pkg/logging"
+	"github.com/crowmw/ai_devs3/pkg/traceview"
+)
+
+// Local web UI for trace files recorded with aidevs --trace.
+func main() {
+	addr := flag.String("addr", ":4000", "address to listen on")
+	dir := flag.String("dir", "data/traces", "directory with JSONL trace files")
+	logLevel := flag.String("log-level", "info", "minimum log level: debug, info, warn or error")
+	logFormat := flag.String("log-format", logging.FormatPretty, "log output format: pretty or json")
+	flag.Parse()
+
+	if err := logging.Setup(logging.Options{Level: *logLevel, Format: *logFormat}); err != nil {
+		slog.Error("invalid logging options", "error", err)
+		os.Exit(1)
+	}
+	logger := logging.New("traceview")
+
+	server, err := traceview.NewServer(*dir)
+	if err != nil {
+		logger.Error("error creating viewer", "error", err)
+		os.Exit(1)
+	}
+
+	logger.Info("starting trace viewer", "addr", *addr, "dir", *dir)
+	if err := http.ListenAndServe(*addr, server); err != nil {
+		logger.Error("server error", "error", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,991 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crowmw/ai_devs3/pkg/ai"
+	"github.com/crowmw/ai_devs3/pkg/logging"
+	"github.com/crowmw/ai_devs3/pkg/redact"
+	"github.com/crowmw/ai_devs3/pkg/trace"
+	"github.com/google/uuid"
+	"github.com/sashabaranov/go-openai"
+)
+
+var logger = logging.New("agent")
+
+// maxPhaseErrors is how many planning failures in a row abort a run
+const maxPhaseErrors = 3
+
+// AbortError is returned by Run when the agent gives up before an answer
+type AbortError struct {
+	Termination Termination
+	Reason      string
+	// Step is the step the run stopped at
+	Step int
+	// Usage is what the run spent until it stopped
+	Usage ai.Usage
+	// Partial are the results of the actions completed before the run stopped
+	Partial []Evidence
+	// Err is the last failure, if any
+	Err error
+}
+
+func (e *AbortError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("agent gave up at step %d: %s", e.Step, e.Reason)
+	}
+	return fmt.Sprintf("agent gave up at step %d: %s: %v", e.Step, e.Reason, e.Err)
+}
+
+func (e *AbortError) Unwrap() error {
+	return e.Err
+}
+
+// Mode selects the phases an agent runs
+type Mode int
+
+const (
+	// ModePlan thinks once, then plans a task list and acts step by step until final_answer is used
+	ModePlan Mode = iota
+	// ModeSelect picks one tool with its payload in a single call and returns the tool result
+	ModeSelect
+)
+
+// Options define an agent: its tools, context and prompts
+type Options struct {
+	// Name tags logs and traces, e.g. "gps"
+	Name string
+	// Model is used by every phase, defaults to gpt-4o
+	Model string
+	// Limits bound the steps, time, tokens and cost of a run
+	Limits Limits
+	// Concurrency limits the actions of a planned batch running at the same time (default 1)
+	Concurrency int
+	// MaxRetries is how many times a task may retry a failed tool before the run is aborted,
+	// defaults to 2, negative disables retries. Tool.MaxRetries overrides it per tool
+	MaxRetries int
+	Mode       Mode
+	// Messages are the initial conversation, usually a system prompt with the agent's context
+	Messages []openai.ChatCompletionMessage
+	// Tools the agent can use, ModePlan adds the default final_answer tool when it is missing
+	Tools   []Tool
+	Prompts Prompts
+	// Store checkpoints the state after every step so runs can be resumed or forked, nil disables it
+	Store Store
+	// Oversight decides which tool calls the operator approves
+	Oversight Oversight
+}
+
+// Agent runs the think, plan and act loop over a set of tools
+type Agent struct {
+	State State
+
+	name        string
+	model       string
+	mode        Mode
+	limits      Limits
+	maxRetries  int
+	concurrency int
+	aiSvc       *ai.Service
+	tools       *Registry
+	prompts     Prompts
+	log         *slog.Logger
+
+	oversight Oversight
+	// approvals asks the operator one call at a time, actions of a batch run concurrently
+	approvals sync.Mutex
+
+	store      Store
+	runID      string
+	forkedFrom string
+	startedAt  time.Time
+	// runAI is aiSvc with the limits of the current run
+	runAI *ai.Service
+	// ctx carries the span of the current run while Run or Resume is running
+	ctx context.Context
+}
+
+// New creates an agent from its options
+func New(aiSvc *ai.Service, opts Options) (*Agent, error) {
+	if opts.Name == "" {
+		return nil, errors.New("agent needs a name")
+	}
+	if opts.Model == "" {
+		opts.Model = "gpt-4o"
+	}
+	if opts.Limits.MaxSteps == 0 {
+		opts.Limits.MaxSteps = 10
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = 2
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+
+	tools, err := NewRegistry(opts.Tools...)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := tools.Get(FinalAnswer); !ok && opts.Mode == ModePlan {
+		tools.Register(FinalAnswerTool())
+	}
+	// a misspelled --tool-policy would otherwise leave the tool it meant on its default policy
+	for name := range opts.Oversight.Policies {
+		if _, ok := tools.Get(name); !ok {
+			return nil, fmt.Errorf("policy for unknown tool %q of agent %s", name, opts.Name)
+		}
+	}
+
+	a := &Agent{
+		State: State{
+			Config:    Config{MaxSteps: opts.Limits.MaxSteps},
+			Tasks:     []Task{},
+			Tools:     tools.Tools(),
+			Documents: []string{},
+			Messages:  opts.Messages,
+		},
+		name:        opts.Name,
+		model:       opts.Model,
+		mode:        opts.Mode,
+		limits:      opts.Limits,
+		maxRetries:  opts.MaxRetries,
+		concurrency: opts.Concurrency,
+		store:       opts.Store,
+		oversight:   opts.Oversight,
+		aiSvc:       aiSvc,
+		tools:       tools,
+		prompts:     opts.Prompts.withDefaults(),
+		log:         logger.With(logging.KeyAgent, opts.Name),
+	}
+	a.log.Debug("agent initialized", "tools", len(a.State.Tools))
+	return a, nil
+}
+
+// Tools returns the tool registry of the agent
+func (a *Agent) Tools() *Registry {
+	return a.tools
+}
+
+// RunID returns the ID of the current run, empty before the first Run
+func (a *Agent) RunID() string {
+	return a.runID
+}
+
+// Run answers the user message. In ModePlan it returns the final_answer result of a new run,
+// in ModeSelect the result of the selected tool, every message being a step of the same run.
+func (a *Agent) Run(userMessage string) (interface{}, error) {
+	if a.mode == ModePlan || a.runID == "" {
+		a.startRun()
+	}
+	a.log.Info("starting execution", "run_id", a.runID, "max_steps", a.State.Config.MaxSteps, "timeout", a.limits.Timeout, "max_tokens", a.limits.MaxTokens, "max_cost", a.limits.MaxCost)
+	logging.Dump(a.log, "user message", "message", userMessage)
+	ctx, span := trace.Start(context.Background(), a.name+" agent", trace.KindRun, logging.KeyAgent, a.name, "run_id", a.runID, "message", userMessage)
+	defer span.End()
+	a.ctx = ctx
+	defer func() { a.ctx = nil }()
+
+	a.State.UserMessage = userMessage
+	var (
+		answer interface{}
+		err    error
+	)
+	if a.mode == ModeSelect {
+		answer, err = a.runSelect(userMessage)
+	} else {
+		answer, err = a.runPlan(userMessage)
+	}
+	a.endSpan(span, answer, err)
+	return answer, err
+}
+
+// Resume continues a ModePlan run from its checkpoints. A negative step continues the latest
+// checkpoint of a crashed or aborted run, otherwise a new run forks from the state after that step.
+func (a *Agent) Resume(runID string, step int) (interface{}, error) {
+	if a.mode != ModePlan {
+		return nil, errors.New("only planning runs can be resumed, use Restore")
+	}
+	if err := a.Restore(runID, step); err != nil {
+		return nil, err
+	}
+	a.log.Info("resuming execution", "run_id", a.runID, "forked_from", a.forkedFrom, "step", a.State.Config.Step)
+	ctx, span := trace.Start(context.Background(), a.name+" agent", trace.KindRun, logging.KeyAgent, a.name, "run_id", a.runID, "resumed_from", fmt.Sprintf("%s@%d", runID, a.State.Config.Step), "message", a.State.UserMessage)
+	defer span.End()
+	a.ctx = ctx
+	defer func() { a.ctx = nil }()
+
+	answer, err := a.loop(a.State.UserMessage)
+	a.endSpan(span, answer, err)
+	return answer, err
+}
+
+// Restore loads the state of a run after a step, or of its latest checkpoint when step is negative,
+// and makes it the current run. Restoring an earlier step forks: the run gets a new ID and the
+// original checkpoints stay unchanged.
+func (a *Agent) Restore(runID string, step int) error {
+	if a.store == nil {
+		return errors.New("agent has no checkpoint store")
+	}
+	checkpoint, err := a.store.Load(runID, step)
+	if err != nil {
+		return err
+	}
+	if checkpoint.Agent != a.name {
+		return fmt.Errorf("run %s belongs to agent %s, not %s", runID, checkpoint.Agent, a.name)
+	}
+
+	if step < 0 {
+		if checkpoint.Status == RunCompleted && a.mode == ModePlan {
+			return fmt.Errorf("run %s is already completed, fork it from a step instead", runID)
+		}
+		a.runID, a.forkedFrom, a.startedAt = checkpoint.RunID, checkpoint.ForkedFrom, checkpoint.StartedAt
+	} else {
+		a.runID, a.forkedFrom, a.startedAt = a.newRunID(), fmt.Sprintf("%s@%d", runID, step), time.Now()
+	}
+
+	a.State = checkpoint.State
+	// tools and limits come from the agent as it is configured now, not from the checkpoint
+	a.State.Tools = a.tools.Tools()
+	a.State.Config.MaxSteps = a.limits.MaxSteps
+	a.startBudget()
+	return nil
+}
+
+func (a *Agent) startRun() {
+	a.runID, a.forkedFrom, a.startedAt = a.newRunID(), "", time.Now()
+	a.State.Config = Config{MaxSteps: a.limits.MaxSteps}
+	a.State.Tasks = []Task{}
+	a.State.Thoughts = Thoughts{}
+	a.startBudget()
+}
+
+func (a *Agent) newRunID() string {
+	return fmt.Sprintf("%s-%s", a.name, time.Now().Format("20060102-150405.000"))
+}
+
+func (a *Agent) endSpan(span *trace.Span, answer interface{}, err error) {
+	span.SetError(err)
+	span.Set("answer", answer)
+	var abortErr *AbortError
+	if errors.As(err, &abortErr) {
+		span.Set("termination", abortErr.Termination)
+		span.Set("abort_reason", abortErr.Reason)
+	}
+	usage := a.Usage()
+	span.Set("total_tokens", usage.TotalTokens())
+	span.Set("cost", usage.Cost)
+}
+
+// Checkpoint saves the current state of the run, it is called after every step.
+// Agents that change State themselves, e.g. to add messages, call it to persist the change.
+func (a *Agent) Checkpoint(answer interface{}, err error) {
+	if a.store == nil || a.runID == "" {
+		return
+	}
+	a.State.Config.Usage = a.Usage()
+	checkpoint := &Checkpoint{
+		RunID:      a.runID,
+		Agent:      a.name,
+		ForkedFrom: a.forkedFrom,
+		Step:       a.State.Config.Step,
+		Status:     RunRunning,
+		Answer:     answer,
+		StartedAt:  a.startedAt,
+		CreatedAt:  time.Now(),
+		State:      a.State,
+	}
+	if err != nil {
+		checkpoint.Status, checkpoint.Error = RunAborted, err.Error()
+	} else if answer != nil && a.mode == ModePlan {
+		checkpoint.Status = RunCompleted
+	}
+	if saveErr := a.store.Save(checkpoint); saveErr != nil {
+		a.log.Warn("could not save checkpoint", "run_id", a.runID, "step", checkpoint.Step, "error", saveErr)
+		return
+	}
+	a.log.Debug("checkpoint saved", "run_id", a.runID, "step", checkpoint.Step, "status", checkpoint.Status)
+}
+
+func (a *Agent) runSelect(userMessage string) (interface{}, error) {
+	if err := a.selectLimits(); err != nil {
+		return nil, err
+	}
+	if err := a.executeSelectPhase(userMessage); err != nil {
+		if abortErr := a.budgetAbort(err); abortErr != nil {
+			return nil, abortErr
+		}
+		return nil, err
+	}
+	result, payload, err := a.callTool(a.ctx, a.State.Thoughts.Tool, a.State.Thoughts.Payload)
+	a.State.Thoughts.Payload = payload
+	var rejected *RejectedError
+	if errors.As(err, &rejected) {
+		// the conversation keeps the rejection so the next message can take it into account
+		a.Record(userMessage, result.Data)
+		return result.Data, nil
+	}
+	if err != nil {
+		a.log.Error("error executing tool", logging.KeyPhase, "action", "error", err)
+		return nil, err
+	}
+
+	a.Record(userMessage, result.Data)
+	return result.Data, nil
+}
+
+// selectLimits returns an *AbortError once a ModeSelect run has no steps or budget left for another message
+func (a *Agent) selectLimits() error {
+	if a.State.Config.Step >= a.State.Config.MaxSteps {
+		return a.abort(TerminationMaxSteps, fmt.Sprintf("no more messages after %d steps", a.State.Config.MaxSteps), nil)
+	}
+	return a.exceeded()
+}
+
+// Record adds an exchange to the conversation of a ModeSelect run as a step and checkpoints it.
+// Run records its own exchanges, agents call it for messages they answer with CallTool directly.
+func (a *Agent) Record(userMessage string, answer interface{}) {
+	if a.runID == "" {
+		a.startRun()
+	}
+	// the conversation is kept in the state, so a restored run continues it
+	a.State.Messages = append(a.State.Messages,
+		openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: userMessage},
+		openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: fmt.Sprint(answer)},
+	)
+	a.State.Config.Step++
+	a.Checkpoint(nil, nil)
+}
+
+func (a *Agent) runPlan(userMessage string) (interface{}, error) {
+	a.State.Messages = append(a.State.Messages, openai.ChatCompletionMessage{
+		Role:    "user",
+		Content: userMessage})
+
+	a.executeThinkingPhase(userMessage)
+	return a.loop(userMessage)
+}
+
+// loop runs planning and action steps until final_answer, the state is checkpointed before every step
+// and when the loop ends
+func (a *Agent) loop(userMessage string) (answer interface{}, err error) {
+	defer func() {
+		a.Checkpoint(answer, err)
+	}()
+
+	phaseErrors := 0
+	for a.State.Config.Step < a.State.Config.MaxSteps {
+		a.Checkpoint(nil, nil)
+		if err := a.exceeded(); err != nil {
+			return nil, err
+		}
+		a.log.Info("starting step", "step", a.State.Config.Step+1, "max_steps", a.State.Config.MaxSteps)
+		if err := a.executePlanningPhase(userMessage); err != nil {
+			if abortErr := a.budgetAbort(err); abortErr != nil {
+				return nil, abortErr
+			}
+			phaseErrors++
+			if phaseErrors >= maxPhaseErrors {
+				return nil, a.abort(TerminationFailed, fmt.Sprintf("planning failed %d times in a row", phaseErrors), err)
+			}
+			a.State.Config.Step++
+			continue
+		}
+		phaseErrors = 0
+		a.executeActionPhase(userMessage)
+
+		batch := a.State.PlannedActions()
+		completed := 0
+		for _, action := range batch {
+			task := a.State.Task(action.TaskUuid)
+			if task == nil || action.Result == nil {
+				a.log.Warn("no valid action result found", "step", a.State.Config.Step+1)
+				continue
+			}
+
+			if action.Status == StatusFailed {
+				// an action cut off by a limit of the run is not a failure of its tool
+				if err := a.exceeded(); err != nil {
+					return nil, err
+				}
+				failures, limit := a.failures(task, action.ToolName), a.retryLimit(action.ToolName)
+				if failures > limit {
+					return nil, a.abort(TerminationFailed, fmt.Sprintf("tool %s failed %d times for task %s", action.ToolName, failures, task.Name), errors.New(formatResult(action.Result)))
+				}
+				a.log.Info("action failed, retrying task", logging.KeyTask, task.Name, logging.KeyTool, action.ToolName, "failures", failures, "retries", limit)
+				continue
+			}
+
+			if action.ToolName == FinalAnswer {
+				a.State.Config.Step++
+				a.log.Info("execution completed", "run_id", a.runID, "steps", a.State.Config.Step)
+				return action.Result.Data, nil
+			}
+
+			task.Status = StatusCompleted
+			completed++
+			a.log.Info("task completed", logging.KeyTask, task.Name)
+		}
+		if len(batch) == 0 {
+			a.log.Warn("no valid action result found", "step", a.State.Config.Step+1)
+		}
+		if completed > 0 {
+			a.selectNextTask()
+		}
+		a.State.Config.Step++
+	}
+
+	return nil, a.abort(TerminationMaxSteps, fmt.Sprintf("no answer after %d steps", a.State.Config.MaxSteps), nil)
+}
+
+// abort ends a run, the reason is logged and returned as an *AbortError with the results gathered so far
+func (a *Agent) abort(termination Termination, reason string, err error) error {
+	abortErr := &AbortError{
+		Termination: termination,
+		Reason:      reason,
+		Step:        a.State.Config.Step + 1,
+		Usage:       a.Usage(),
+		Partial:     a.evidence(),
+		Err:         err,
+	}
+	a.log.Error("giving up", "termination", termination, "reason", reason, "step", abortErr.Step,
+		"tokens", abortErr.Usage.TotalTokens(), "cost", abortErr.Usage.Cost, "partial", len(abortErr.Partial), "error", err)
+	return abortErr
+}
+
+// failures counts the failed actions of a tool within a task
+func (a *Agent) failures(task *Task, toolName string) int {
+	count := 0
+	for _, action := range task.Actions {
+		if action.ToolName == toolName && action.Status == StatusFailed {
+			count++
+		}
+	}
+	return count
+}
+
+// retryLimit is how many failed attempts of a tool a task may retry
+func (a *Agent) retryLimit(toolName string) int {
+	limit := a.maxRetries
+	if tool, ok := a.tools.Get(toolName); ok && tool.MaxRetries != 0 {
+		limit = tool.MaxRetries
+	}
+	return max(limit, 0)
+}
+
+// selectNextTask moves to the first pending task
+func (a *Agent) selectNextTask() {
+	for i := range a.State.Tasks {
+		nextTask := &a.State.Tasks[i]
+		if nextTask.Status != StatusPending {
+			continue
+		}
+		a.log.Info("moving to next task", logging.KeyTask, nextTask.Name)
+		a.State.Config.Task = &nextTask.Uuid
+		a.State.Config.Action = nil
+		a.State.Config.Actions = nil
+		if len(nextTask.Actions) > 0 {
+			a.State.Config.Action = &nextTask.Actions[0].Uuid
+		}
+		return
+	}
+	a.log.Info("no more pending tasks")
+	a.State.Config.Task = nil
+	a.State.Config.Action = nil
+	a.State.Config.Actions = nil
+}
+
+// CallTool validates the payload, runs a registered tool and records it as a tool span.
+// An invalid payload returns a *ValidationError and a result describing what to fix,
+// a call denied by policy or rejected by the operator a *RejectedError.
+// In ModeSelect a call answers a message like Run does, so it returns an *AbortError once the run
+// is out of steps or budget.
+func (a *Agent) CallTool(name string, payload map[string]interface{}) (ToolResult, error) {
+	// a call outside Run belongs to the run it is recorded in, e.g. of a ModeSelect conversation
+	if a.runID == "" {
+		a.startRun()
+	}
+	if a.mode == ModeSelect {
+		if err := a.selectLimits(); err != nil {
+			return ErrorResult(err), err
+		}
+	}
+	result, _, err := a.callTool(a.ctx, name, payload)
+	return result, err
+}
+
+// callTool is CallTool that also returns the payload the tool ran with, the operator may have edited it
+func (a *Agent) callTool(ctx context.Context, name string, payload map[string]interface{}) (ToolResult, map[string]interface{}, error) {
+	_, span := trace.Start(ctx, name, trace.KindTool, logging.KeyAgent, a.name, logging.KeyTool, name, "payload", payload)
+	defer span.End()
+
+	tool, ok := a.tools.Get(name)
+	if !ok {
+		err := fmt.Errorf("unknown tool %q", name)
+		span.SetError(err)
+		return ErrorResult(err), payload, err
+	}
+	if err := tool.Validate(payload); err != nil {
+		span.SetError(err)
+		return invalidPayloadResult(tool, err), payload, err
+	}
+	payload, err := a.approve(tool, payload)
+	if err != nil {
+		span.SetError(err)
+		var rejected *RejectedError
+		var validationErr *ValidationError
+		switch {
+		case errors.As(err, &rejected):
+			return ToolResult{Status: ToolError, Data: rejectionMessage(rejected)}, payload, err
+		case errors.As(err, &validationErr):
+			return invalidPayloadResult(tool, err), payload, err
+		}
+		return ErrorResult(err), payload, err
+	}
+	result, err := tool.Handler(payload)
+	if err != nil {
+		span.SetError(err)
+		return result, payload, err
+	}
+	span.Set("result", result.Data)
+	return result, payload, nil
+}
+
+// invalidPayloadResult sends the schema back with the problems so the model can fix the payload
+func invalidPayloadResult(tool Tool, err error) ToolResult {
+	return ToolResult{Status: ToolError, Data: fmt.Sprintf("%v. The payload must match this JSON schema: %s", err, schemaJSON(*tool.Schema))}
+}
+
+// policy returns the policy of a tool, Oversight.Policies win over Tool.Policy
+func (a *Agent) policy(tool Tool) Policy {
+	if policy, ok := a.oversight.Policies[tool.Name]; ok {
+		return policy
+	}
+	if tool.Policy != "" {
+		return tool.Policy
+	}
+	return PolicyAuto
+}
+
+// approve applies the policy of a tool to a call and returns the payload to run it with
+func (a *Agent) approve(tool Tool, payload map[string]interface{}) (map[string]interface{}, error) {
+	log := a.log.With(logging.KeyTool, tool.Name)
+	switch a.policy(tool) {
+	case PolicyDeny:
+		log.Warn("tool denied by policy")
+		return payload, &RejectedError{Tool: tool.Name, Note: "the tool is not allowed to run"}
+	case PolicyConfirm:
+		if a.oversight.Approver == nil {
+			// agents confirming by default, e.g. serce, still run unattended
+			log.Warn("tool needs confirmation but there is no approver, running it without confirmation")
+			return payload, nil
+		}
+	default:
+		return payload, nil
+	}
+
+	a.approvals.Lock()
+	defer a.approvals.Unlock()
+	approval, err := a.oversight.Approver.Approve(ApprovalRequest{Agent: a.name, Tool: tool.Name, Payload: payload})
+	if err != nil {
+		return payload, fmt.Errorf("error asking for approval: %w", err)
+	}
+	switch approval.Decision {
+	case DecisionApprove:
+		log.Info("tool call approved")
+		return payload, nil
+	case DecisionEdit:
+		log.Info("tool payload edited by operator")
+		logging.Dump(log, "edited payload", "payload", prettyPrint(approval.Payload))
+		if err := tool.Validate(approval.Payload); err != nil {
+			return approval.Payload, err
+		}
+		return approval.Payload, nil
+	default:
+		log.Info("tool call rejected", "note", approval.Note)
+		return payload, &RejectedError{Tool: tool.Name, Note: approval.Note}
+	}
+}
+
+// rejectionMessage is the failed result the model sees for a rejected call
+func rejectionMessage(err *RejectedError) string {
+	msg := fmt.Sprintf("The call of tool %s was rejected.", err.Tool)
+	if err.Note != "" {
+		msg += " Note: " + err.Note + "."
+	}
+	return msg + " Do not repeat the same call, follow the note or choose a different action."
+}
+
+// complete sends a phase prompt in JSON mode and returns the reply, within the limits of the run.
+// ctx carries the span of the phase the call belongs to.
+func (a *Agent) complete(ctx context.Context, systemPrompt, userMessage string) (string, error) {
+	response, err := a.AI().WithContext(ctx).ChatCompletion(ai.ChatCompletionConfig{
+		Model: a.model,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: systemPrompt},
+			{Role: openai.ChatMessageRoleUser, Content: userMessage},
+		},
+		JSONMode: true,
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(response.Choices) == 0 {
+		return "", errors.New("no choices in response")
+	}
+	return response.Choices[0].Message.Content, nil
+}
+
+func (a *Agent) executeSelectPhase(userMessage string) error {
+	log := a.log.With(logging.KeyPhase, "thinking")
+	ctx, span := trace.Start(a.ctx, "thinking", trace.KindPhase, logging.KeyPhase, "thinking")
+	defer span.End()
+	log.Info("selecting tool", "tools", len(a.State.Tools))
+
+	content, err := a.complete(ctx, a.prompts.Select(&a.State), userMessage)
+	if err != nil {
+		log.Error("error from AI service", "error", err)
+		span.SetError(err)
+		return err
+	}
+	selection, err := unmarshalResponse[ToolSelectionResponse](content)
+	if err != nil {
+		log.Error("error parsing AI response", "error", err, "response", content)
+		span.SetError(err)
+		return err
+	}
+
+	log.Info("selected tool", logging.KeyTool, selection.Result.Tool, "thinking", selection.Thinking)
+	logging.Dump(log, "tool payload", "payload", prettyPrint(selection.Result.Payload))
+	a.State.Thoughts.Tool = selection.Result.Tool
+	a.State.Thoughts.Payload = selection.Result.Payload
+	return nil
+}
+
+func (a *Agent) executeThinkingPhase(question string) {
+	log := a.log.With(logging.KeyPhase, "thinking")
+	ctx, span := trace.Start(a.ctx, "thinking", trace.KindPhase, logging.KeyPhase, "thinking", "step", a.State.Config.Step+1)
+	defer span.End()
+	log.Info("analyzing available tools")
+
+	content, err := a.complete(ctx, a.prompts.Thinking(&a.State), question)
+	if err != nil {
+		log.Error("error from AI service", "error", err)
+		span.SetError(err)
+		return
+	}
+	toolsAnalysis, err := unmarshalResponse[ToolsAnalysisResponse](content)
+	if err != nil {
+		log.Error("error analyzing tools", "error", err)
+		span.SetError(err)
+		return
+	}
+
+	log.Info("tools analysis completed", "tools", len(toolsAnalysis.Result))
+	var toolsStr []string
+	for _, tool := range toolsAnalysis.Result {
+		log.Debug("relevant tool", logging.KeyTool, tool.Tool, "query", tool.Query)
+		toolsStr = append(toolsStr, fmt.Sprintf(`{"query": "%s", "tool": "%s"}`, tool.Query, tool.Tool))
+	}
+	a.State.Thoughts.Tools = strings.Join(toolsStr, "\n")
+}
+
+func (a *Agent) executePlanningPhase(userMessage string) error {
+	log := a.log.With(logging.KeyPhase, "planning")
+	ctx, span := trace.Start(a.ctx, "planning", trace.KindPhase, logging.KeyPhase, "planning", "step", a.State.Config.Step+1)
+	defer span.End()
+	log.Info("creating execution plan")
+
+	content, err := a.complete(ctx, a.prompts.Tasks(&a.State), userMessage)
+	if err != nil {
+		log.Error("error from AI service", "error", err)
+		span.SetError(err)
+		return err
+	}
+	taskThoughts, err := unmarshalResponse[TaskThoughtsResponse](content)
+	if err != nil {
+		log.Error("error analyzing tasks", "error", err)
+		span.SetError(err)
+		return err
+	}
+
+	log.Info("task analysis completed", "tasks", len(taskThoughts.Result))
+	a.updateTasks(log, taskThoughts.Result)
+
+	for _, task := range a.State.Tasks {
+		if task.Status == StatusPending {
+			log.Info("selected first pending task", logging.KeyTask, task.Name)
+			a.State.Config.Task = &task.Uuid
+			break
+		}
+	}
+
+	log.Info("planning actions for current task")
+	content, err = a.complete(ctx, a.prompts.Action(&a.State), userMessage)
+	if err != nil {
+		log.Error("error from AI service", "error", err)
+		span.SetError(err)
+		return err
+	}
+	actionThoughts, err := unmarshalResponse[ActionThoughtsResponse](content)
+	if err != nil {
+		log.Error("error analyzing actions", "error", err)
+		span.SetError(err)
+		return err
+	}
+
+	batch := independentActions(log, actionThoughts.Result)
+	if len(batch) == 0 {
+		err := errors.New("no action planned")
+		log.Error("error analyzing actions", "error", err)
+		span.SetError(err)
+		return err
+	}
+	log.Info("action analysis completed", "actions", len(batch))
+	a.State.Config.Actions = nil
+	for _, thought := range batch {
+		if err := a.addAction(log, thought); err != nil {
+			log.Error("error adding action", "error", err)
+			span.SetError(err)
+			return err
+		}
+	}
+	// the first action of the batch stays the current one
+	first := a.State.PlannedActions()[0]
+	a.State.Config.Task, a.State.Config.Action = &first.TaskUuid, &first.Uuid
+
+	taskName, actionDescription := "none", "none"
+	currentTask := a.State.CurrentTask()
+	if currentTask != nil {
+		taskName = currentTask.Name
+	}
+	if currentAction := a.State.CurrentAction(currentTask); currentAction != nil {
+		actionDescription = currentAction.Description
+	}
+	log.Info("current execution state", logging.KeyTask, taskName, "action", actionDescription)
+	return nil
+}
+
+// updateTasks applies the planner output: known pending tasks are updated, tasks without uuid are created
+func (a *Agent) updateTasks(log *slog.Logger, thoughts []TaskThoughts) {
+	for _, thought := range thoughts {
+		log.Debug("planned task", logging.KeyTask, thought.Name, "description", thought.Description)
+		if thought.Uuid != nil {
+			for i, existingTask := range a.State.Tasks {
+				if existingTask.Uuid == thought.Uuid && existingTask.Status == StatusPending {
+					log.Info("updating existing task", logging.KeyTask, thought.Name)
+					a.State.Tasks[i].Name = thought.Name
+					a.State.Tasks[i].Description = thought.Description
+					a.State.Tasks[i].Updated_at = time.Now()
+					break
+				}
+			}
+			continue
+		}
+
+		log.Info("creating new task", logging.KeyTask, thought.Name)
+		a.State.Tasks = append(a.State.Tasks, Task{
+			Uuid:              uuid.New().String(),
+			Conversation_uuid: uuid.New().String(),
+			Status:            StatusPending,
+			Name:              thought.Name,
+			Description:       thought.Description,
+			Actions:           []Action{},
+			Created_at:        time.Now(),
+			Updated_at:        time.Now(),
+		})
+	}
+}
+
+// addAction makes the planned action the current action of its task
+func (a *Agent) addAction(log *slog.Logger, thought ActionThoughts) error {
+	var task *Task
+	for i := range a.State.Tasks {
+		if a.State.Tasks[i].Uuid == thought.TaskUuid {
+			task = &a.State.Tasks[i]
+			break
+		}
+	}
+	if task == nil {
+		return fmt.Errorf("action %q is planned for unknown task %v", thought.Description, thought.TaskUuid)
+	}
+
+	log.Info("creating new action", logging.KeyTask, task.Name, logging.KeyTool, thought.ToolName, "action", thought.Description)
+	newAction := Action{
+		Uuid:        uuid.New().String(),
+		TaskUuid:    task.Uuid,
+		Description: thought.Description,
+		ToolName:    thought.ToolName,
+		Status:      StatusPending,
+		Result:      nil,
+		Payload:     make(map[string]interface{}),
+		Sequence:    len(task.Actions) + 1,
+	}
+	// failed attempts stay on the task so the prompts show what went wrong
+	task.Actions = append(task.Actions, newAction)
+	task.Updated_at = time.Now()
+	a.State.Config.Task = &task.Uuid
+	a.State.Config.Action = &newAction.Uuid
+	a.State.Config.Actions = append(a.State.Config.Actions, newAction.Uuid)
+	return nil
+}
+
+// independentActions keeps one action per task, final_answer only runs on its own after everything else
+func independentActions(log *slog.Logger, batch ActionBatch) ActionBatch {
+	others := false
+	for _, thought := range batch {
+		others = others || thought.ToolName != FinalAnswer
+	}
+
+	var actions ActionBatch
+	seen := make(map[string]bool)
+	for _, thought := range batch {
+		key := fmt.Sprint(thought.TaskUuid)
+		if seen[key] {
+			log.Warn("skipping second action for the same task", logging.KeyTool, thought.ToolName, "action", thought.Description)
+			continue
+		}
+		if thought.ToolName == FinalAnswer && others {
+			log.Warn("postponing final answer until the other actions are done")
+			continue
+		}
+		// a batch of final answers keeps the first one
+		if thought.ToolName == FinalAnswer && len(actions) > 0 {
+			log.Warn("skipping second final answer", "action", thought.Description)
+			continue
+		}
+		seen[key] = true
+		actions = append(actions, thought)
+	}
+	return actions
+}
+
+func (a *Agent) executeActionPhase(userMessage string) {
+	log := a.log.With(logging.KeyPhase, "action")
+	ctx, span := trace.Start(a.ctx, "action", trace.KindPhase, logging.KeyPhase, "action", "step", a.State.Config.Step+1)
+	defer span.End()
+
+	batch := a.State.PlannedActions()
+	if len(batch) == 0 {
+		log.Warn("no task or action to execute")
+		return
+	}
+	span.Set("actions", len(batch))
+
+	// prompts are built before anything runs, so every action of the batch sees the same state
+	jobs := make([]actionJob, len(batch))
+	for i, action := range batch {
+		view := a.State
+		view.Config.Task, view.Config.Action = &action.TaskUuid, &action.Uuid
+		taskName := ""
+		if task := a.State.Task(action.TaskUuid); task != nil {
+			taskName = task.Name
+		}
+		jobs[i] = actionJob{
+			action: *action,
+			prompt: a.prompts.Use(&view),
+			log:    log.With(logging.KeyTask, taskName, logging.KeyTool, action.ToolName),
+		}
+	}
+
+	outcomes := make([]actionOutcome, len(jobs))
+	semaphore := make(chan struct{}, a.concurrency)
+	var wg sync.WaitGroup
+	for i := range jobs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			outcomes[i] = a.executeAction(ctx, jobs[i], userMessage)
+		}(i)
+	}
+	wg.Wait()
+
+	// outcomes are merged in plan order, whatever order the actions finished in
+	for i, action := range batch {
+		action.Payload = outcomes[i].payload
+		action.Result = outcomes[i].result
+		action.Status = outcomes[i].status
+		span.SetError(outcomes[i].err)
+	}
+}
+
+// actionJob is an action of the batch with the payload prompt built for it
+type actionJob struct {
+	action Action
+	prompt string
+	log    *slog.Logger
+}
+
+type actionOutcome struct {
+	payload map[string]interface{}
+	result  *ActionResult
+	status  string
+	err     error
+}
+
+// executeAction writes the payload of an action and runs its tool. It only reads the job, so
+// actions of a batch can run at the same time; every failure becomes the result of the action
+// so the next planning step sees it.
+func (a *Agent) executeAction(ctx context.Context, job actionJob, userMessage string) actionOutcome {
+	log := job.log
+	outcome := actionOutcome{payload: job.action.Payload}
+	fail := func(msg string, err error) actionOutcome {
+		log.Error(msg, "error", err)
+		outcome.result = &ActionResult{Status: ToolError, Data: fmt.Sprintf("%s: %v", msg, err)}
+		outcome.status, outcome.err = StatusFailed, err
+		return outcome
+	}
+
+	log.Info("preparing to execute action")
+	content, err := a.complete(ctx, job.prompt, userMessage)
+	if err != nil {
+		return fail("error from AI service", err)
+	}
+	useThoughts, err := unmarshalResponse[UseThoughtsResponse](content)
+	if err != nil {
+		return fail("error parsing tool payload", err)
+	}
+
+	outcome.payload = useThoughts.Result
+	log.Info("using tool")
+	logging.Dump(log, "tool payload", "payload", prettyPrint(useThoughts.Result))
+
+	toolResult, payload, err := a.callTool(ctx, job.action.ToolName, useThoughts.Result)
+	outcome.payload = payload
+	var validationErr *ValidationError
+	var rejected *RejectedError
+	if errors.As(err, &validationErr) || errors.As(err, &rejected) {
+		// the result explains the problems with the schema or the operator's note, keep it as is
+		log.Warn("tool call not run", "error", err)
+		outcome.result = &ActionResult{Status: ToolError, Data: toolResult.Data}
+		outcome.status, outcome.err = StatusFailed, err
+		return outcome
+	}
+	if err != nil {
+		return fail("error executing tool", err)
+	}
+	if toolResult.Status == ToolError {
+		return fail("tool returned an error", fmt.Errorf("%v", toolResult.Data))
+	}
+
+	outcome.result = &ActionResult{Status: StatusCompleted, Data: toolResult.Data}
+	outcome.status = StatusCompleted
+	log.Info("action completed", "action", job.action.Description)
+	return outcome
+}
+func prettyPrint(v interface{}) string {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return redact.String(fmt.Sprintf("%+v", v))
+	}
+	return redact.String(string(b))
+}
@@ -0,0 +1,156 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/crowmw/ai_devs3/pkg/ai"
+	"github.com/crowmw/ai_devs3/pkg/env"
+	"github.com/sashabaranov/go-openai"
+)
+
+// echoAI answers every chat completion with its system prompt, so the prompts of a test are the script of the AI
+type echoAI struct{}
+
+func (echoAI) RoundTrip(r *http.Request) (*http.Response, error) {
+	var request openai.ChatCompletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{{
+			Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: request.Messages[0].Content},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request:    r,
+	}, nil
+}
+
+// plannedAction is an action the scripted planner returns, task is the index of its task
+type plannedAction struct {
+	task int
+	tool string
+	text string
+}
+
+// scriptedPrompts plan the tasks first and second, then the batch, every payload carries the action's description
+func scriptedPrompts(batch []plannedAction) Prompts {
+	return Prompts{
+		Tasks: func(state *State) string {
+			return `{"result": [{"uuid": null, "name": "first", "description": "first task"}, {"uuid": null, "name": "second", "description": "second task"}]}`
+		},
+		Action: func(state *State) string {
+			var response ActionThoughtsResponse
+			for _, action := range batch {
+				response.Result = append(response.Result, ActionThoughts{Description: action.text, ToolName: action.tool, TaskUuid: state.Tasks[action.task].Uuid})
+			}
+			content, _ := json.Marshal(response)
+			return string(content)
+		},
+		Use: func(state *State) string {
+			action := state.CurrentAction(state.CurrentTask())
+			key := "text"
+			if action.ToolName == FinalAnswer {
+				key = "answer"
+			}
+			content, _ := json.Marshal(map[string]interface{}{"result": map[string]string{key: action.Description}})
+			return string(content)
+		},
+	}
+}
+
+func TestPlannedBatch(t *testing.T) {
+	transport := http.DefaultTransport
+	http.DefaultTransport = echoAI{}
+	t.Cleanup(func() { http.DefaultTransport = transport })
+
+	envSvc, err := env.NewServiceFromConfig(env.Config{OpenAIKey: "test-key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	aiSvc, err := ai.NewService(envSvc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// slow finishes after echo, so the results only come in plan order when they are merged by position
+	type payload struct {
+		Text string `json:"text"`
+	}
+	slow := NewTool("slow", "echoes the text after a while", func(p payload) (ToolResult, error) {
+		time.Sleep(50 * time.Millisecond)
+		return ToolResult{Status: ToolSuccess, Data: p.Text}, nil
+	})
+
+	tests := []struct {
+		name  string
+		batch []plannedAction
+		want  []string
+	}{
+		{
+			name:  "results merged in plan order",
+			batch: []plannedAction{{0, "slow", "one"}, {1, "echo", "two"}},
+			want:  []string{"slow one", "echo two"},
+		},
+		{
+			name:  "second action for the same task dropped",
+			batch: []plannedAction{{0, "echo", "one"}, {0, "echo", "again"}, {1, "echo", "two"}},
+			want:  []string{"echo one", "echo two"},
+		},
+		{
+			name:  "final answer postponed",
+			batch: []plannedAction{{0, FinalAnswer, "done"}, {1, "echo", "two"}},
+			want:  []string{"echo two"},
+		},
+		{
+			name:  "batch of final answers keeps the first",
+			batch: []plannedAction{{0, FinalAnswer, "done"}, {1, FinalAnswer, "other"}},
+			want:  []string{"final_answer done"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := New(aiSvc, Options{
+				Name:        "test",
+				Model:       "test-model",
+				Concurrency: 2,
+				Tools:       []Tool{echoTool("echo", ""), slow},
+				Prompts:     scriptedPrompts(tt.batch),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			a.startRun()
+			a.ctx = context.Background()
+
+			if err := a.executePlanningPhase("question"); err != nil {
+				t.Fatal(err)
+			}
+			a.executeActionPhase("question")
+
+			var got []string
+			for _, action := range a.State.PlannedActions() {
+				if action.Status == StatusFailed {
+					t.Errorf("action %s failed: %v", action.ToolName, action.Result.Data)
+				}
+				got = append(got, fmt.Sprintf("%s %v", action.ToolName, action.Result.Data))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
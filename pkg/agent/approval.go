@@ -0,0 +1,158 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Policy decides whether a tool call needs the operator
+type Policy string
+
+const (
+	// PolicyAuto runs the tool without asking, the default
+	PolicyAuto Policy = "auto"
+	// PolicyConfirm asks the approver before every call, without an approver the tool runs as auto with a warning
+	PolicyConfirm Policy = "confirm"
+	// PolicyDeny never runs the tool, the model is told to choose another one
+	PolicyDeny Policy = "deny"
+)
+
+// ParsePolicies parses "tool=policy" pairs separated by commas, as accepted by --tool-policy
+func ParsePolicies(s string) (map[string]Policy, error) {
+	policies := map[string]Policy{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		tool, policy, found := strings.Cut(pair, "=")
+		if !found || tool == "" {
+			return nil, fmt.Errorf("invalid tool policy %q, expected tool=policy", pair)
+		}
+		switch p := Policy(policy); p {
+		case PolicyAuto, PolicyConfirm, PolicyDeny:
+			policies[tool] = p
+		default:
+			return nil, fmt.Errorf("invalid policy %q for tool %s, expected auto, confirm or deny", policy, tool)
+		}
+	}
+	return policies, nil
+}
+
+// Decisions of the operator
+const (
+	DecisionApprove = "approve"
+	DecisionEdit    = "edit"
+	DecisionReject  = "reject"
+)
+
+// ApprovalRequest is a tool call waiting for the operator
+type ApprovalRequest struct {
+	Agent   string
+	Tool    string
+	Payload map[string]interface{}
+}
+
+// Approval is the operator's answer, Payload replaces the proposed one when edited
+// and Note tells the model why the call was rejected
+type Approval struct {
+	Decision string
+	Payload  map[string]interface{}
+	Note     string
+}
+
+// Approver asks the operator about tool calls of confirm tools
+type Approver interface {
+	Approve(request ApprovalRequest) (Approval, error)
+}
+
+// Oversight configures approval of tool calls: Policies override Tool.Policy by tool name
+// and Approver is asked about confirm tools
+type Oversight struct {
+	Policies map[string]Policy
+	Approver Approver
+}
+
+// RejectedError is returned by CallTool for calls denied by policy or rejected by the operator
+type RejectedError struct {
+	Tool string
+	Note string
+}
+
+func (e *RejectedError) Error() string {
+	if e.Note == "" {
+		return fmt.Sprintf("tool %s rejected", e.Tool)
+	}
+	return fmt.Sprintf("tool %s rejected: %s", e.Tool, e.Note)
+}
+
+// TerminalApprover shows proposed tool calls in the terminal and reads the operator's decision
+type TerminalApprover struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// NewTerminalApprover reads decisions from in, usually os.Stdin, and writes prompts to out
+func NewTerminalApprover(in io.Reader, out io.Writer) *TerminalApprover {
+	return &TerminalApprover{in: bufio.NewReader(in), out: out}
+}
+
+func (t *TerminalApprover) Approve(request ApprovalRequest) (Approval, error) {
+	payload, err := json.MarshalIndent(request.Payload, "", "  ")
+	if err != nil {
+		return Approval{}, fmt.Errorf("error marshaling payload: %w", err)
+	}
+	fmt.Fprintf(t.out, "\n[%s] wants to call %s with payload:\n%s\n", request.Agent, request.Tool, payload)
+
+	for {
+		answer, err := t.ask("[a]pprove, [e]dit payload or [r]eject? ")
+		if err != nil {
+			return Approval{}, err
+		}
+		switch strings.ToLower(answer) {
+		case "a", "approve", "y", "yes":
+			return Approval{Decision: DecisionApprove}, nil
+		case "e", "edit":
+			edited, err := t.editPayload()
+			if err != nil {
+				return Approval{}, err
+			}
+			return Approval{Decision: DecisionEdit, Payload: edited}, nil
+		case "r", "reject", "n", "no":
+			note, err := t.ask("Note for the model (optional): ")
+			if err != nil {
+				return Approval{}, err
+			}
+			return Approval{Decision: DecisionReject, Note: note}, nil
+		}
+	}
+}
+
+// editPayload reads a JSON object on one line until it parses
+func (t *TerminalApprover) editPayload() (map[string]interface{}, error) {
+	for {
+		line, err := t.ask("New payload as JSON on one line: ")
+		if err != nil {
+			return nil, err
+		}
+		var payload map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &payload); err != nil {
+			fmt.Fprintf(t.out, "invalid JSON object: %v\n", err)
+			continue
+		}
+		return payload, nil
+	}
+}
+
+func (t *TerminalApprover) ask(prompt string) (string, error) {
+	fmt.Fprint(t.out, prompt)
+	line, err := t.in.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", fmt.Errorf("error reading operator input: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -0,0 +1,133 @@
+package agent
+
+import (
+	"errors"
+	"testing"
+)
+
+type scriptedApprover struct {
+	approval Approval
+	asked    int
+}
+
+func (s *scriptedApprover) Approve(request ApprovalRequest) (Approval, error) {
+	s.asked++
+	return s.approval, nil
+}
+
+func echoTool(name string, policy Policy) Tool {
+	type payload struct {
+		Text string `json:"text"`
+	}
+	tool := NewTool(name, "echoes the text", func(p payload) (ToolResult, error) {
+		return ToolResult{Status: ToolSuccess, Data: p.Text}, nil
+	})
+	tool.Policy = policy
+	return tool
+}
+
+func TestParsePolicies(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    map[string]Policy
+		wantErr bool
+	}{
+		{"", map[string]Policy{}, false},
+		{"gps=confirm, data_memory=deny", map[string]Policy{"gps": PolicyConfirm, "data_memory": PolicyDeny}, false},
+		{"gps", nil, true},
+		{"=deny", nil, true},
+		{"gps=ask", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePolicies(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePolicies(%q) error = %v, want error %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("ParsePolicies(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+		for tool, policy := range tt.want {
+			if got[tool] != policy {
+				t.Errorf("ParsePolicies(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestNewValidatesPolicies(t *testing.T) {
+	tests := []struct {
+		name     string
+		policies map[string]Policy
+		wantErr  bool
+	}{
+		{"known tool", map[string]Policy{"echo": PolicyDeny}, false},
+		{"final answer of a planning agent", map[string]Policy{FinalAnswer: PolicyConfirm}, false},
+		{"misspelled tool", map[string]Policy{"ecko": PolicyDeny}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(nil, Options{Name: "test", Tools: []Tool{echoTool("echo", "")}, Oversight: Oversight{Policies: tt.policies}})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApprove(t *testing.T) {
+	proposed := map[string]interface{}{"text": "proposed"}
+	edited := map[string]interface{}{"text": "edited"}
+
+	tests := []struct {
+		name         string
+		policy       Policy
+		override     Policy
+		approver     *scriptedApprover
+		wantPayload  map[string]interface{}
+		wantRejected bool
+		wantInvalid  bool
+		wantAsked    int
+	}{
+		{"auto runs", PolicyAuto, "", &scriptedApprover{}, proposed, false, false, 0},
+		{"deny rejects", PolicyDeny, "", &scriptedApprover{}, proposed, true, false, 0},
+		{"override wins", PolicyAuto, PolicyDeny, nil, proposed, true, false, 0},
+		{"confirm without approver runs", PolicyConfirm, "", nil, proposed, false, false, 0},
+		{"confirm approved", PolicyConfirm, "", &scriptedApprover{approval: Approval{Decision: DecisionApprove}}, proposed, false, false, 1},
+		{"confirm edited", PolicyConfirm, "", &scriptedApprover{approval: Approval{Decision: DecisionEdit, Payload: edited}}, edited, false, false, 1},
+		{"confirm edited invalid", PolicyConfirm, "", &scriptedApprover{approval: Approval{Decision: DecisionEdit, Payload: map[string]interface{}{}}}, map[string]interface{}{}, false, true, 1},
+		{"confirm rejected", PolicyConfirm, "", &scriptedApprover{approval: Approval{Decision: DecisionReject, Note: "not now"}}, proposed, true, false, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oversight := Oversight{}
+			if tt.override != "" {
+				oversight.Policies = map[string]Policy{"echo": tt.override}
+			}
+			if tt.approver != nil {
+				oversight.Approver = tt.approver
+			}
+			tool := echoTool("echo", tt.policy)
+			a, err := New(nil, Options{Name: "test", Tools: []Tool{tool}, Oversight: oversight})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			payload, err := a.approve(tool, proposed)
+			var rejected *RejectedError
+			var invalid *ValidationError
+			if errors.As(err, &rejected) != tt.wantRejected || errors.As(err, &invalid) != tt.wantInvalid {
+				t.Errorf("approve() error = %v", err)
+			}
+			if !tt.wantRejected && !tt.wantInvalid && err != nil {
+				t.Errorf("approve() error = %v, want nil", err)
+			}
+			if payload["text"] != tt.wantPayload["text"] {
+				t.Errorf("approve() payload = %v, want %v", payload, tt.wantPayload)
+			}
+			if tt.approver != nil && tt.approver.asked != tt.wantAsked {
+				t.Errorf("approver asked %d times, want %d", tt.approver.asked, tt.wantAsked)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/crowmw/ai_devs3/pkg/ai"
+)
+
+// Limits bound a run, zero values are unlimited except MaxSteps, which defaults to 10
+type Limits struct {
+	// MaxSteps limits the planning loop in ModePlan and the messages of a ModeSelect run
+	MaxSteps int
+	// Timeout is the wall-clock limit of a run, counted from its start or from Resume and Restore
+	Timeout time.Duration
+	// MaxTokens and MaxCost (estimated USD) limit all LLM calls of the run,
+	// including the calls tool handlers make through Agent.AI
+	MaxTokens int
+	MaxCost   float64
+}
+
+// Override returns the limits with the non-zero limits of o, e.g. set from the command line
+func (l Limits) Override(o Limits) Limits {
+	if o.MaxSteps != 0 {
+		l.MaxSteps = o.MaxSteps
+	}
+	if o.Timeout != 0 {
+		l.Timeout = o.Timeout
+	}
+	if o.MaxTokens != 0 {
+		l.MaxTokens = o.MaxTokens
+	}
+	if o.MaxCost != 0 {
+		l.MaxCost = o.MaxCost
+	}
+	return l
+}
+
+// Termination is why a run ended without an answer
+type Termination string
+
+const (
+	// TerminationFailed is a run that failed too many times, see AbortError.Reason
+	TerminationFailed    Termination = "failed"
+	TerminationMaxSteps  Termination = "max_steps"
+	TerminationDeadline  Termination = "deadline"
+	TerminationMaxTokens Termination = "max_tokens"
+	TerminationMaxCost   Termination = "max_cost"
+)
+
+var budgetTerminations = map[string]Termination{
+	ai.LimitDeadline: TerminationDeadline,
+	ai.LimitTokens:   TerminationMaxTokens,
+	ai.LimitCost:     TerminationMaxCost,
+}
+
+// AI returns the AI service of the current run, its calls count against the limits of the run.
+// Tool handlers that call the model use it so the limits cover them too, during a run
+// its calls are traced as children of the run.
+func (a *Agent) AI() *ai.Service {
+	if a.runAI == nil {
+		a.startBudget()
+	}
+	if a.ctx != nil {
+		return a.runAI.WithContext(a.ctx)
+	}
+	return a.runAI
+}
+
+// Usage returns the tokens and estimated cost of the current run
+func (a *Agent) Usage() ai.Usage {
+	return a.AI().Spent()
+}
+
+// startBudget starts the limits of the current run, usage continues from the state so resumed runs
+// keep what they spent before
+func (a *Agent) startBudget() {
+	budget := ai.Budget{
+		MaxTokens: a.limits.MaxTokens,
+		MaxCost:   a.limits.MaxCost,
+		Spent:     a.State.Config.Usage,
+	}
+	if a.limits.Timeout > 0 {
+		budget.Deadline = time.Now().Add(a.limits.Timeout)
+	}
+	a.runAI = a.aiSvc.WithBudget(budget)
+}
+
+// exceeded returns an *AbortError once the deadline, token or cost limit of the run is reached
+func (a *Agent) exceeded() error {
+	return a.budgetAbort(a.AI().CheckBudget())
+}
+
+// budgetAbort turns an error caused by a limit of the run into an *AbortError, other errors into nil
+func (a *Agent) budgetAbort(err error) error {
+	var budgetErr *ai.BudgetError
+	switch {
+	case errors.As(err, &budgetErr):
+		return a.abort(budgetTerminations[budgetErr.Limit], budgetErr.Error(), nil)
+	case errors.Is(err, context.DeadlineExceeded):
+		// the deadline passed while a call was running
+		return a.abort(TerminationDeadline, "deadline exceeded", err)
+	}
+	return nil
+}
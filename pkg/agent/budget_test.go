@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/crowmw/ai_devs3/pkg/ai"
+)
+
+func TestCallToolLimits(t *testing.T) {
+	tests := []struct {
+		name            string
+		mode            Mode
+		limits          Limits
+		recorded        int
+		wantTermination Termination
+	}{
+		{"select within limits", ModeSelect, Limits{MaxSteps: 2}, 1, ""},
+		{"select out of steps", ModeSelect, Limits{MaxSteps: 2}, 2, TerminationMaxSteps},
+		{"select past the deadline", ModeSelect, Limits{Timeout: time.Nanosecond}, 0, TerminationDeadline},
+		{"plan steps are counted by the loop", ModePlan, Limits{MaxSteps: 2}, 2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := New(&ai.Service{}, Options{Name: "test", Mode: tt.mode, Limits: tt.limits, Tools: []Tool{echoTool("echo", "")}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			a.startRun()
+			for i := 0; i < tt.recorded; i++ {
+				a.Record("question", "answer")
+			}
+			// the deadline counts from the start of the run
+			time.Sleep(time.Millisecond)
+
+			result, err := a.CallTool("echo", map[string]interface{}{"text": "hi"})
+			var abortErr *AbortError
+			if tt.wantTermination == "" {
+				if err != nil || result.Data != "hi" {
+					t.Errorf("CallTool() = %+v, %v, want the tool result", result, err)
+				}
+				return
+			}
+			if !errors.As(err, &abortErr) || abortErr.Termination != tt.wantTermination {
+				t.Errorf("CallTool() error = %v, want %s abort", err, tt.wantTermination)
+			}
+			if result.Status != ToolError {
+				t.Errorf("CallTool() = %+v, want an error result", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Run statuses of a checkpoint
+const (
+	RunRunning   = "running"
+	RunCompleted = "completed"
+	RunAborted   = "aborted"
+)
+
+// Checkpoint is the state of an agent run after a step
+type Checkpoint struct {
+	RunID string `json:"run_id"`
+	Agent string `json:"agent"`
+	// ForkedFrom is "<run id>@<step>" for runs continued from a step of another run
+	ForkedFrom string      `json:"forked_from,omitempty"`
+	Step       int         `json:"step"`
+	Status     string      `json:"status"`
+	Answer     interface{} `json:"answer,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	StartedAt  time.Time   `json:"started_at"`
+	CreatedAt  time.Time   `json:"created_at"`
+	State      State       `json:"state"`
+}
+
+// RunSummary describes a run by its latest checkpoint
+type RunSummary struct {
+	RunID       string
+	Agent       string
+	ForkedFrom  string
+	Status      string
+	Steps       int
+	UserMessage string
+	Error       string
+	StartedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Store keeps the checkpoints of agent runs
+type Store interface {
+	Save(checkpoint *Checkpoint) error
+	// Load returns the checkpoint of a run after the given step, a negative step loads the latest one
+	Load(runID string, step int) (*Checkpoint, error)
+	// Checkpoints returns all checkpoints of a run, oldest first
+	Checkpoints(runID string) ([]*Checkpoint, error)
+	// Runs lists past runs, the most recent first
+	Runs() ([]RunSummary, error)
+}
+
+// FileStore keeps each checkpoint as JSON in <dir>/<run id>/step-NNN.json
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a store in dir, usually <cache-dir>/agents
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+func (s *FileStore) checkpointFile(runID string, step int) string {
+	return filepath.Join(s.dir, runID, fmt.Sprintf("step-%03d.json", step))
+}
+
+func (s *FileStore) Save(checkpoint *Checkpoint) error {
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling checkpoint: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Join(s.dir, checkpoint.RunID), 0755); err != nil {
+		return fmt.Errorf("error creating checkpoint directory: %w", err)
+	}
+	// Not redacted, a resumed run needs tool results exactly as they were
+	if err := os.WriteFile(s.checkpointFile(checkpoint.RunID, checkpoint.Step), data, 0600); err != nil {
+		return fmt.Errorf("error writing checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStore) Load(runID string, step int) (*Checkpoint, error) {
+	if runID == "" || runID != filepath.Base(runID) {
+		return nil, fmt.Errorf("invalid run ID %q", runID)
+	}
+	if step < 0 {
+		steps, err := s.steps(runID)
+		if err != nil {
+			return nil, err
+		}
+		if len(steps) == 0 {
+			return nil, fmt.Errorf("no checkpoints for run %q", runID)
+		}
+		step = steps[len(steps)-1]
+	}
+
+	data, err := os.ReadFile(s.checkpointFile(runID, step))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no checkpoint for step %d of run %q", step, runID)
+		}
+		return nil, fmt.Errorf("error reading checkpoint: %w", err)
+	}
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("error parsing checkpoint %s@%d: %w", runID, step, err)
+	}
+	return &checkpoint, nil
+}
+
+func (s *FileStore) Checkpoints(runID string) ([]*Checkpoint, error) {
+	steps, err := s.steps(runID)
+	if err != nil {
+		return nil, err
+	}
+	checkpoints := make([]*Checkpoint, 0, len(steps))
+	for _, step := range steps {
+		checkpoint, err := s.Load(runID, step)
+		if err != nil {
+			return nil, err
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+	return checkpoints, nil
+}
+
+func (s *FileStore) Runs() ([]RunSummary, error) {
+	dirs, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error listing runs: %w", err)
+	}
+
+	var runs []RunSummary
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		checkpoint, err := s.Load(dir.Name(), -1)
+		if err != nil {
+			logger.Warn("skipping run", "run_id", dir.Name(), "error", err)
+			continue
+		}
+		runs = append(runs, RunSummary{
+			RunID:       checkpoint.RunID,
+			Agent:       checkpoint.Agent,
+			ForkedFrom:  checkpoint.ForkedFrom,
+			Status:      checkpoint.Status,
+			Steps:       checkpoint.Step,
+			UserMessage: checkpoint.State.UserMessage,
+			Error:       checkpoint.Error,
+			StartedAt:   checkpoint.StartedAt,
+			UpdatedAt:   checkpoint.CreatedAt,
+		})
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].UpdatedAt.After(runs[j].UpdatedAt) })
+	return runs, nil
+}
+
+// steps returns the checkpointed steps of a run in order
+func (s *FileStore) steps(runID string) ([]int, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, runID, "step-*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("error listing checkpoints: %w", err)
+	}
+	var steps []int
+	for _, file := range files {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(file), "step-"), ".json")
+		step, err := strconv.Atoi(name)
+		if err != nil {
+			continue
+		}
+		steps = append(steps, step)
+	}
+	sort.Ints(steps)
+	return steps, nil
+}
+
+// ParseRunRef splits "<run id>@<step>" as accepted by --resume, the step is -1 when it is missing
+func ParseRunRef(ref string) (string, int, error) {
+	runID, stepStr, found := strings.Cut(ref, "@")
+	if runID == "" {
+		return "", 0, fmt.Errorf("missing run ID in %q", ref)
+	}
+	if !found {
+		return runID, -1, nil
+	}
+	step, err := strconv.Atoi(stepStr)
+	if err != nil || step < 0 {
+		return "", 0, fmt.Errorf("invalid step in %q", ref)
+	}
+	return runID, step, nil
+}
@@ -0,0 +1,380 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Prompts build the system prompt of each phase from the state, nil fields use the defaults
+type Prompts struct {
+	// Version names the prompt set in eval reports, bump it with every prompt change
+	Version string
+	// Thinking lists queries for the tools that may help, run once before planning
+	Thinking func(state *State) string
+	// Tasks creates and updates the task list
+	Tasks func(state *State) string
+	// Action picks the next tool for the current task
+	Action func(state *State) string
+	// Use writes the payload for the chosen tool
+	Use func(state *State) string
+	// Select picks a tool together with its payload, used by ModeSelect
+	Select func(state *State) string
+	// Synthesis writes the answer of a finished run matching the schema from the evidence, used by Synthesize
+	Synthesis func(state *State, schema string, evidence []Evidence) string
+}
+
+// withDefaults fills unset prompts with the generic ones
+func (p Prompts) withDefaults() Prompts {
+	if p.Thinking == nil {
+		p.Thinking = DefaultThinkingPrompt
+	}
+	if p.Tasks == nil {
+		p.Tasks = DefaultTasksPrompt
+	}
+	if p.Action == nil {
+		p.Action = DefaultActionPrompt
+	}
+	if p.Use == nil {
+		p.Use = DefaultUsePrompt
+	}
+	if p.Select == nil {
+		p.Select = DefaultSelectPrompt
+	}
+	if p.Synthesis == nil {
+		p.Synthesis = DefaultSynthesisPrompt
+	}
+	return p
+}
+
+// FormatTools renders tools as <tool name="...">description</tool> lines, typed tools include their payload schema
+func FormatTools(tools []Tool) string {
+	var b strings.Builder
+	for _, tool := range tools {
+		if tool.Schema != nil {
+			fmt.Fprintf(&b, "<tool name=\"%s\">%s\n<payload_schema>%s</payload_schema></tool>\n", tool.Name, tool.Description, schemaJSON(*tool.Schema))
+			continue
+		}
+		fmt.Fprintf(&b, "<tool name=\"%s\">%s</tool>\n", tool.Name, tool.Description)
+	}
+	return b.String()
+}
+
+// FormatToolInstructions renders tools with the payload instruction or schema of each
+func FormatToolInstructions(tools []Tool) string {
+	var b strings.Builder
+	for _, tool := range tools {
+		fmt.Fprintf(&b, "- %s: %s\n", tool.Name, tool.Description)
+		if tool.Instruction != "" {
+			fmt.Fprintf(&b, "  Instruction: %s\n", tool.Instruction)
+		}
+		if tool.Schema != nil {
+			fmt.Fprintf(&b, "  Payload schema: %s\n", schemaJSON(*tool.Schema))
+		}
+	}
+	return b.String()
+}
+
+// FormatToolPayload describes the payload a tool expects: its schema, instruction or both
+func FormatToolPayload(tools []Tool, name string) string {
+	for _, tool := range tools {
+		if tool.Name != name {
+			continue
+		}
+		var parts []string
+		if tool.Instruction != "" {
+			parts = append(parts, tool.Instruction)
+		}
+		if tool.Schema != nil {
+			parts = append(parts, "The payload MUST match this JSON schema: "+schemaJSON(*tool.Schema))
+		}
+		return strings.Join(parts, "\n")
+	}
+	return ""
+}
+
+// FormatTaskList renders tasks with their uuids for the task planner, failed attempts are listed with their errors
+func FormatTaskList(tasks []Task) string {
+	var b strings.Builder
+	for _, task := range tasks {
+		fmt.Fprintf(&b, "<task uuid=\"%s\" name=\"%s\" status=\"%s\">\n<description>%s</description>\n", task.Uuid, task.Name, task.Status, task.Description)
+		for _, action := range task.Actions {
+			if action.Status == StatusFailed {
+				fmt.Fprintf(&b, "<failed_action tool=\"%s\">%s</failed_action>\n", action.ToolName, formatResult(action.Result))
+			}
+		}
+		b.WriteString("</task>\n")
+	}
+	return b.String()
+}
+
+// FormatTaskActions renders tasks with uuids and their actions for the action planner
+func FormatTaskActions(tasks []Task) string {
+	var b strings.Builder
+	for _, task := range tasks {
+		var actionsList string
+		for _, action := range task.Actions {
+			actionsList += fmt.Sprintf("<action name=\"%s\" tool=\"%s\" status=\"%s\"><result>%s</result></action>\n", action.Description, action.ToolName, action.Status, formatResult(action.Result))
+		}
+		fmt.Fprintf(&b, "<task uuid=\"%s\" name=\"%s\" status=\"%s\"><description>%s</description><actions>%s</actions></task>\n", task.Uuid, task.Name, task.Status, task.Description, actionsList)
+	}
+	return b.String()
+}
+
+// FormatTasks renders the task history with action results
+func FormatTasks(tasks []Task) string {
+	var result string
+	for _, task := range tasks {
+		result += fmt.Sprintf("<task name=\"%s\" status=\"%s\">\n", task.Name, task.Status)
+		result += fmt.Sprintf("<description>%s</description>\n", task.Description)
+
+		for _, action := range task.Actions {
+			result += fmt.Sprintf("<action tool=\"%s\" status=\"%s\">\n", action.ToolName, action.Status)
+			if action.Result != nil {
+				result += fmt.Sprintf("<result>%s</result>\n", formatResult(action.Result))
+			}
+			result += "</action>\n"
+		}
+		result += "</task>\n"
+	}
+	return result
+}
+
+// formatResult renders the data of an action result, strings as is and anything else as JSON
+func formatResult(result *ActionResult) string {
+	if result == nil {
+		return ""
+	}
+	if s, ok := result.Data.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(result.Data)
+	return string(b)
+}
+
+// FormatEvidence lists completed actions as <evidence> elements with the ids the answer cites
+func FormatEvidence(evidence []Evidence) string {
+	var b strings.Builder
+	for _, e := range evidence {
+		payload, _ := json.Marshal(e.Payload)
+		result := formatResult(&ActionResult{Data: e.Result})
+		fmt.Fprintf(&b, "<evidence id=\"%d\" task=\"%s\" tool=\"%s\">\npayload: %s\nresult: %s\n</evidence>\n", e.ID, e.Task, e.Tool, payload, result)
+	}
+	return b.String()
+}
+
+// FormatContext joins the initial messages given to the agent, e.g. its system prompt
+func FormatContext(state *State) string {
+	var parts []string
+	for _, message := range state.Messages {
+		if message.Content != "" {
+			parts = append(parts, message.Content)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func DefaultThinkingPrompt(state *State) string {
+	return fmt.Sprintf(thinkingPrompt, time.Now().Format(time.RFC3339), FormatContext(state), FormatTools(state.Tools))
+}
+
+const thinkingPrompt = `Your task is to analyze the conversation context and generate relevant queries for using available tools.
+
+<prompt_objective>
+Process the conversation context and output a JSON object containing the internal reasoning and an array of independent queries for appropriate tools.
+Consider both general context and environment context to write more relevant queries.
+
+Current datetime: %s
+</prompt_objective>
+
+<prompt_rules>
+- ALWAYS output a valid JSON object with "_thinking" and "result" properties
+- The "_thinking" property MUST contain your concise internal thought process
+- The "result" property MUST be an array of objects, each with "query" and "tool" properties
+- In the "result" array:
+  - May be empty if no relevant tools are found
+  - The "query" property MUST contain a specific instruction or query for the tool
+  - The "tool" property MUST contain the name of the relevant tool
+- ONLY use tools that are explicitly defined in the tools list
+- Ensure all queries are independent and can be executed concurrently
+- Avoid making assumptions about information not explicitly mentioned
+- FORBIDDEN: Creating queries for tools that don't exist or aren't mentioned in the tools list
+</prompt_rules>
+
+<dynamic_context>
+<context>
+%s
+</context>
+
+<tools>
+%s</tools>
+</dynamic_context>`
+
+func DefaultTasksPrompt(state *State) string {
+	return fmt.Sprintf(tasksPrompt, FormatContext(state), state.Thoughts.Tools, FormatTools(state.Tools), FormatTaskList(state.Tasks))
+}
+
+const tasksPrompt = `You are an AI assistant responsible for maintaining and updating a list of tasks based on ongoing conversations with the user. Your goal is to ensure an accurate and relevant task list that reflects the user's current needs and progress.
+
+<prompt_objective>
+Analyze the conversation context, including the user's latest request, completed tasks, and pending tasks. Create or update tasks to fulfill the user's request.
+</prompt_objective>
+
+<prompt_rules>
+- ALWAYS output a valid JSON object with "_thinking" and "result" properties
+- The "_thinking" property MUST contain your detailed internal thought process
+- The "result" property MUST be an array of task objects with "uuid", "name", "description" and "status" properties
+- Use "uuid": null for new tasks and the existing uuid for tasks you update
+- A task listing failed actions is still pending, change its description or approach so the next attempt can succeed
+- Create a final_answer task last, only when all data needed for the answer can be collected by earlier tasks
+- Task names MUST be descriptive and use underscores
+- Task descriptions MUST be precise and actionable
+- ONLY use values returned by previous tool results, never make them up
+- NEVER modify completed tasks
+- NEVER create duplicate tasks
+- NEVER create tasks with empty descriptions
+</prompt_rules>
+
+<dynamic_context>
+<context>
+%s
+</context>
+
+<initial_thoughts_about_tools_needed note="These are your initial thoughts you had when you received the user's message. Some of them might be outdated.">
+%s
+</initial_thoughts_about_tools_needed>
+
+<tools>
+%s</tools>
+
+<current_tasks>
+%s
+</current_tasks>
+</dynamic_context>`
+
+func DefaultActionPrompt(state *State) string {
+	return fmt.Sprintf(actionPrompt, FormatTools(state.Tools), FormatTaskActions(state.Tasks))
+}
+
+const actionPrompt = `You are an AI assistant responsible for determining the next immediate action to take based on the ongoing conversation and current tasks. Your goal is to decide on the most appropriate next step.
+
+<prompt_objective>
+Analyze the current tasks and their dependencies to determine the next action. Select a tool and associate it with the relevant task. Output a JSON object containing your reasoning and a detailed action object.
+</prompt_objective>
+
+<prompt_rules>
+- ALWAYS output a valid JSON object with "_thinking" and "result" properties
+- The "result" property MUST be an object with:
+  - "description": Clear action description
+  - "tool_name": One of the available tools
+  - "task_uuid": UUID of the associated task
+- Pick the first pending task whose dependencies are met
+- When several pending tasks do not depend on each other or on pending results, "result" MAY be an array of such objects, one per task. The actions run at the same time
+- Use final_answer only when the results of all other tasks are known, never in an array
+</prompt_rules>
+
+<dynamic_context>
+<available_tools>
+%s</available_tools>
+
+<current_tasks>
+%s
+</current_tasks>
+</dynamic_context>`
+
+func DefaultUsePrompt(state *State) string {
+	tool, description := "unknown", "unknown"
+	instruction := ""
+	if action := state.CurrentAction(state.CurrentTask()); action != nil {
+		tool, description = action.ToolName, action.Description
+		instruction = FormatToolPayload(state.Tools, action.ToolName)
+	}
+	return fmt.Sprintf(usePrompt, tool, description, instruction, FormatTasks(state.Tasks))
+}
+
+const usePrompt = `You are preparing to execute a tool action. Your task is to generate a valid payload matching the tool's required format.
+
+<current_action>
+Tool: %s
+Description: %s
+Instruction: %s
+</current_action>
+
+<prompt_rules>
+- Output MUST be a valid JSON object with "_thinking" and "result" properties
+- The "_thinking" property MUST contain your detailed reasoning process
+- The "result" property MUST be the payload and match the tool instruction exactly
+- If an earlier attempt of the action failed, fix the payload as its result describes
+- Use exact values from the results of performed tasks, never modify or make them up
+- NEVER return null or empty payloads
+</prompt_rules>
+
+<performed_tasks>
+%s
+</performed_tasks>`
+
+func DefaultSynthesisPrompt(state *State, schema string, evidence []Evidence) string {
+	return fmt.Sprintf(synthesisPrompt, FormatContext(state), schema, FormatEvidence(evidence))
+}
+
+const synthesisPrompt = `You are writing the final answer to the user's message from the results gathered by the tools.
+
+<prompt_objective>
+Answer the user's message using ONLY the evidence below. Output a JSON object with your reasoning, the answer matching the answer schema and the ids of the evidence that supports it.
+</prompt_objective>
+
+<prompt_rules>
+- ALWAYS output a valid JSON object with "_thinking", "answer" and "evidence" properties
+- The "answer" property MUST match the answer schema exactly: the same field names, types and structure, no extra fields
+- Rename fields of the tool results to the names of the schema when they mean the same thing
+- Copy values from the evidence exactly, never round, modify or make them up
+- Leave out everything the user asked to skip or exclude
+- The "evidence" property MUST be an array with the ids of every evidence the answer is based on
+- If a previous answer was invalid, fix the problems it lists
+</prompt_rules>
+
+<context>
+%s
+</context>
+
+<answer_schema>
+%s
+</answer_schema>
+
+<evidence_list>
+%s</evidence_list>`
+
+func DefaultSelectPrompt(state *State) string {
+	return fmt.Sprintf(selectPrompt, time.Now().Format("2006-01-02 15:04:05"), FormatContext(state), FormatToolInstructions(state.Tools))
+}
+
+const selectPrompt = `Your task is to analyze the conversation context and select the most appropriate tool with its payload.
+
+<prompt_objective>
+Process the conversation context and output a JSON object containing:
+1. Your internal reasoning (_thinking)
+2. A single tool selection with its payload (result)
+
+Current datetime: %s
+</prompt_objective>
+
+<prompt_rules>
+- ALWAYS output a valid JSON object with "_thinking" and "result" properties
+- The "_thinking" property MUST contain your concise internal thought process
+- The "result" property MUST be an object with "tool" and "payload" properties
+- The "tool" property MUST contain the name of the selected tool
+- The "payload" property MUST be an object containing the required parameters for the tool
+- ONLY use tools that are explicitly defined in the tools list
+- Ensure the payload matches the tool's instruction format exactly
+- FORBIDDEN: Creating payloads for tools that don't exist or aren't mentioned in the tools list
+</prompt_rules>
+
+<dynamic_context>
+<context>
+%s
+</context>
+
+<tools>
+%s</tools>
+</dynamic_context>`
@@ -0,0 +1,328 @@
+package agent
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai/jsonschema"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// SchemaFor generates the JSON schema of a payload type from its json tags.
+// Fields are required unless tagged omitempty, `description:"..."` documents a field
+// and `enum:"a,b"` restricts a string field to the listed values.
+// time.Time is a date-time string, a struct containing itself is an object of any shape where it recurs.
+func SchemaFor(v any) jsonschema.Definition {
+	return schemaForType(reflect.TypeOf(v))
+}
+
+func schemaForType(t reflect.Type) jsonschema.Definition {
+	return schemaFor(t, map[reflect.Type]bool{})
+}
+
+// schemaFor generates the schema of t, visiting holds the structs on the path from the payload type
+// so recursive types end instead of recursing forever
+func schemaFor(t reflect.Type, visiting map[reflect.Type]bool) jsonschema.Definition {
+	if t == nil {
+		return jsonschema.Definition{}
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		// jsonschema.Definition has no format, the description tells the model what to send
+		return jsonschema.Definition{Type: jsonschema.String, Description: "date-time in RFC 3339 format, e.g. 2024-11-12T14:30:00Z"}
+	case implements(t, textMarshalerType) && !implements(t, jsonMarshalerType):
+		// encoding/json marshals these as strings
+		return jsonschema.Definition{Type: jsonschema.String}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return jsonschema.Definition{Type: jsonschema.String}
+	case reflect.Bool:
+		return jsonschema.Definition{Type: jsonschema.Boolean}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return jsonschema.Definition{Type: jsonschema.Integer}
+	case reflect.Float32, reflect.Float64:
+		return jsonschema.Definition{Type: jsonschema.Number}
+	case reflect.Slice, reflect.Array:
+		items := schemaFor(t.Elem(), visiting)
+		return jsonschema.Definition{Type: jsonschema.Array, Items: &items}
+	case reflect.Map:
+		// jsonschema.Definition has no additionalProperties, the value schema is described instead
+		d := jsonschema.Definition{Type: jsonschema.Object}
+		if value := schemaFor(t.Elem(), visiting); value.Type != "" {
+			d.Description = "keys map to values matching " + schemaJSON(value)
+		}
+		return d
+	case reflect.Struct:
+		if visiting[t] {
+			// without properties any field is accepted
+			return jsonschema.Definition{Type: jsonschema.Object, Description: "a nested " + t.Name() + ", same schema as its parent " + t.Name()}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		d := jsonschema.Definition{Type: jsonschema.Object, Properties: map[string]jsonschema.Definition{}}
+		addFields(&d, t, visiting)
+		return d
+	}
+	// interfaces and anything else accept any value
+	return jsonschema.Definition{}
+}
+
+// addFields adds the exported fields of a struct, embedded structs are flattened like encoding/json does
+func addFields(d *jsonschema.Definition, t reflect.Type, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				// a struct embedding a pointer to itself adds its fields once
+				if !visiting[embedded] {
+					visiting[embedded] = true
+					addFields(d, embedded, visiting)
+					delete(visiting, embedded)
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := schemaFor(field.Type, visiting)
+		if description := field.Tag.Get("description"); description != "" {
+			if property.Description != "" {
+				// keep what the type says, e.g. the date-time format
+				description += ", " + property.Description
+			}
+			property.Description = description
+		}
+		if enum := field.Tag.Get("enum"); enum != "" {
+			property.Enum = strings.Split(enum, ",")
+		}
+		d.Properties[name] = property
+		if !strings.Contains(opts, "omitempty") {
+			d.Required = append(d.Required, name)
+		}
+	}
+}
+
+// implements reports whether t or a pointer to it implements iface, encoding/json uses both
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PointerTo(t).Implements(iface)
+}
+
+// ValidationError lists everything wrong with a payload, it is shown to the model so it can fix the payload
+type ValidationError struct {
+	Tool     string
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid payload for tool %s: %s", e.Tool, strings.Join(e.Problems, "; "))
+}
+
+// ValidatePayload checks a payload chosen by the model against the schema of a tool
+func ValidatePayload(tool string, schema jsonschema.Definition, payload map[string]interface{}) error {
+	var problems []string
+	validateValue(schema, payload, "payload", &problems)
+	if len(problems) > 0 {
+		return &ValidationError{Tool: tool, Problems: problems}
+	}
+	return nil
+}
+
+// validateType checks a value against the Go type it is decoded into, map values are checked
+// against the schema of the map's element type, which jsonschema.Definition can't express
+func validateType(t reflect.Type, value any, path string, problems *[]string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Map {
+		validateValue(schemaForType(t), value, path, problems)
+		return
+	}
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		*problems = append(*problems, fmt.Sprintf("%s: expected object, got %s", path, jsonType(value)))
+		return
+	}
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		validateType(t.Elem(), object[key], path+"."+key, problems)
+	}
+}
+
+func validateValue(d jsonschema.Definition, value any, path string, problems *[]string) {
+	if d.Type == "" {
+		return
+	}
+	if value == nil {
+		*problems = append(*problems, fmt.Sprintf("%s: expected %s, got null", path, d.Type))
+		return
+	}
+
+	switch d.Type {
+	case jsonschema.Object:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for _, name := range d.Required {
+			if _, ok := object[name]; !ok {
+				*problems = append(*problems, fmt.Sprintf("%s.%s: required field is missing", path, name))
+			}
+		}
+		// sorted so the same payload always gives the same message
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, known := d.Properties[name]
+			if !known {
+				// maps have no properties and accept any key
+				if d.Properties != nil {
+					*problems = append(*problems, fmt.Sprintf("%s.%s: unknown field", path, name))
+				}
+				continue
+			}
+			validateValue(property, object[name], path+"."+name, problems)
+		}
+		return
+	case jsonschema.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			break
+		}
+		if d.Items != nil {
+			for i, item := range items {
+				validateValue(*d.Items, item, fmt.Sprintf("%s[%d]", path, i), problems)
+			}
+		}
+		return
+	case jsonschema.String:
+		s, ok := value.(string)
+		if !ok {
+			break
+		}
+		if len(d.Enum) > 0 && !slices.Contains(d.Enum, s) {
+			*problems = append(*problems, fmt.Sprintf("%s: %q is not one of %s", path, s, strings.Join(d.Enum, ", ")))
+		}
+		return
+	case jsonschema.Integer:
+		if n, ok := number(value); ok && n == math.Trunc(n) {
+			return
+		}
+	case jsonschema.Number:
+		if _, ok := number(value); ok {
+			return
+		}
+	case jsonschema.Boolean:
+		if _, ok := value.(bool); ok {
+			return
+		}
+	default:
+		return
+	}
+	*problems = append(*problems, fmt.Sprintf("%s: expected %s, got %s", path, d.Type, jsonType(value)))
+}
+
+func number(value any) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// jsonType names the JSON type of a decoded value for error messages
+func jsonType(value any) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64, int, json.Number:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// schemaJSON renders a schema for prompts, without the empty "properties" jsonschema.Definition adds to every type
+func schemaJSON(d jsonschema.Definition) string {
+	b, err := json.Marshal(compactSchema(d))
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func compactSchema(d jsonschema.Definition) map[string]any {
+	m := map[string]any{}
+	if d.Type != "" {
+		m["type"] = d.Type
+	}
+	if d.Description != "" {
+		m["description"] = d.Description
+	}
+	if len(d.Enum) > 0 {
+		m["enum"] = d.Enum
+	}
+	if len(d.Properties) > 0 {
+		properties := make(map[string]any, len(d.Properties))
+		for name, property := range d.Properties {
+			properties[name] = compactSchema(property)
+		}
+		m["properties"] = properties
+	}
+	if len(d.Required) > 0 {
+		m["required"] = d.Required
+	}
+	if d.Items != nil {
+		m["items"] = compactSchema(*d.Items)
+	}
+	return m
+}
@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai/jsonschema"
+)
+
+type testPayload struct {
+	Query  string            `json:"query" description:"what to look for"`
+	Mode   string            `json:"mode" enum:"fast,full"`
+	Limit  int               `json:"limit,omitempty"`
+	Score  float64           `json:"score,omitempty"`
+	Tags   []string          `json:"tags,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Since  time.Time         `json:"since,omitempty" description:"oldest entry"`
+	Nested *struct {
+		Flag bool `json:"flag"`
+	} `json:"nested,omitempty"`
+}
+
+type treeNode struct {
+	Name     string     `json:"name"`
+	Children []treeNode `json:"children,omitempty"`
+	Parent   *treeNode  `json:"parent,omitempty"`
+}
+
+type selfEmbedding struct {
+	*selfEmbedding
+	Name string `json:"name"`
+}
+
+func TestSchemaFor(t *testing.T) {
+	schema := SchemaFor(testPayload{})
+	if got := strings.Join(schema.Required, ","); got != "query,mode" {
+		t.Errorf("got required %s, want query,mode", got)
+	}
+	since := schema.Properties["since"]
+	if since.Type != jsonschema.String || !strings.Contains(since.Description, "oldest entry") || !strings.Contains(since.Description, "RFC 3339") {
+		t.Errorf("got since %+v, want a described date-time string", since)
+	}
+
+	tree := SchemaFor(treeNode{})
+	children := tree.Properties["children"]
+	if children.Type != jsonschema.Array || children.Items.Type != jsonschema.Object || children.Items.Properties != nil {
+		t.Errorf("got children %+v, want an array of objects of any shape", children)
+	}
+	if parent := tree.Properties["parent"]; parent.Type != jsonschema.Object {
+		t.Errorf("got parent %+v, want an object", parent)
+	}
+
+	if got := SchemaFor(selfEmbedding{}); len(got.Properties) != 1 {
+		t.Errorf("got %+v, want the name only", got.Properties)
+	}
+}
+
+func TestValidatePayload(t *testing.T) {
+	schema := SchemaFor(testPayload{})
+	tests := []struct {
+		name    string
+		payload map[string]interface{}
+		want    []string
+	}{
+		{"valid", map[string]interface{}{"query": "ELBLAG", "mode": "fast", "limit": 3.0, "tags": []interface{}{"a"}, "since": "2024-11-12T14:30:00Z"}, nil},
+		{"integer as int", map[string]interface{}{"query": "x", "mode": "full", "limit": 3}, nil},
+		{"missing required", map[string]interface{}{"mode": "fast"}, []string{"payload.query: required field is missing"}},
+		{"unknown field", map[string]interface{}{"query": "x", "mode": "fast", "extra": 1.0}, []string{"payload.extra: unknown field"}},
+		{"wrong enum", map[string]interface{}{"query": "x", "mode": "slow"}, []string{`payload.mode: "slow" is not one of fast, full`}},
+		{"fraction for integer", map[string]interface{}{"query": "x", "mode": "fast", "limit": 2.5}, []string{"payload.limit: expected integer, got number"}},
+		{"null", map[string]interface{}{"query": nil, "mode": "fast"}, []string{"payload.query: expected string, got null"}},
+		{"wrong item", map[string]interface{}{"query": "x", "mode": "fast", "tags": []interface{}{"a", 1.0}}, []string{"payload.tags[1]: expected string, got number"}},
+		{"map keeps any key", map[string]interface{}{"query": "x", "mode": "fast", "labels": map[string]interface{}{"any": "v"}}, nil},
+		{"nested", map[string]interface{}{"query": "x", "mode": "fast", "nested": map[string]interface{}{"flag": "yes"}}, []string{"payload.nested.flag: expected boolean, got string"}},
+		{"time as number", map[string]interface{}{"query": "x", "mode": "fast", "since": 1700000000.0}, []string{"payload.since: expected string, got number"}},
+		{"several problems in order", map[string]interface{}{"score": "high", "mode": 1.0}, []string{
+			"payload.query: required field is missing",
+			"payload.mode: expected string, got number",
+			"payload.score: expected number, got string",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePayload("search", schema, tt.payload)
+			var validationErr *ValidationError
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("ValidatePayload() = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("ValidatePayload() = %v, want a *ValidationError", err)
+			}
+			if got := strings.Join(validationErr.Problems, "\n"); got != strings.Join(tt.want, "\n") {
+				t.Errorf("got problems\n%s\nwant\n%s", got, strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+
+	if err := ValidatePayload("tree", SchemaFor(treeNode{}), map[string]interface{}{
+		"name":     "root",
+		"children": []interface{}{map[string]interface{}{"name": "leaf", "children": []interface{}{}}},
+	}); err != nil {
+		t.Errorf("ValidatePayload() of a recursive payload = %v", err)
+	}
+}
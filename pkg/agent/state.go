@@ -0,0 +1,215 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/crowmw/ai_devs3/pkg/ai"
+	"github.com/sashabaranov/go-openai"
+)
+
+// Task and action statuses
+const (
+	StatusPending   = "pending"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// State is everything the agent knows during a run, prompts are built from it
+type State struct {
+	Config      Config                         `json:"config"`
+	Tasks       []Task                         `json:"tasks"`
+	Tools       []Tool                         `json:"tools"`
+	Documents   []string                       `json:"documents"`
+	Messages    []openai.ChatCompletionMessage `json:"messages"`
+	Thoughts    Thoughts                       `json:"thoughts"`
+	UserMessage string                         `json:"user_message"`
+}
+
+type ActionResult struct {
+	Status string      `json:"status"`
+	Data   interface{} `json:"data"`
+}
+
+type Action struct {
+	Uuid        string                 `json:"uuid"`
+	TaskUuid    string                 `json:"task_uuid"`
+	ToolName    string                 `json:"tool_name"`
+	Payload     map[string]interface{} `json:"payload"`
+	Result      *ActionResult          `json:"result"`
+	Status      string                 `json:"status"` // "pending", "completed", "failed"
+	Sequence    int                    `json:"sequence"`
+	Description string                 `json:"description"`
+}
+
+type Task struct {
+	Uuid              string
+	Conversation_uuid string
+	Status            string
+	Name              string
+	Actions           []Action
+	Description       string
+	Created_at        time.Time
+	Updated_at        time.Time
+}
+
+// Config is the position of the run: the step and the current task and action
+type Config struct {
+	MaxSteps int     `json:"max_steps"`
+	Step     int     `json:"step"`
+	Task     *string `json:"task"`
+	Action   *string `json:"action"`
+	// Actions are the independent actions planned for the step, Action is the first of them
+	Actions []string `json:"actions,omitempty"`
+	// Usage is what the run spent so far, a resumed run counts its limits from it
+	Usage ai.Usage `json:"usage"`
+}
+
+type Thoughts struct {
+	Tools string `json:"tools"`
+	// Tool and Payload are chosen by the selection phase
+	Tool    string                 `json:"tool"`
+	Payload map[string]interface{} `json:"payload"`
+}
+
+// CurrentTask returns the task selected by planning
+func (s *State) CurrentTask() *Task {
+	if s.Config.Task == nil {
+		return nil
+	}
+	for i := range s.Tasks {
+		if s.Tasks[i].Uuid == *s.Config.Task {
+			return &s.Tasks[i]
+		}
+	}
+	return nil
+}
+
+// Task returns a task by uuid
+func (s *State) Task(uuid string) *Task {
+	for i := range s.Tasks {
+		if s.Tasks[i].Uuid == uuid {
+			return &s.Tasks[i]
+		}
+	}
+	return nil
+}
+
+// PlannedActions returns the actions planned for the step in plan order
+func (s *State) PlannedActions() []*Action {
+	if len(s.Config.Actions) == 0 {
+		if action := s.CurrentAction(s.CurrentTask()); action != nil {
+			return []*Action{action}
+		}
+		return nil
+	}
+
+	var actions []*Action
+	for _, uuid := range s.Config.Actions {
+		for i := range s.Tasks {
+			for j := range s.Tasks[i].Actions {
+				if s.Tasks[i].Actions[j].Uuid == uuid {
+					actions = append(actions, &s.Tasks[i].Actions[j])
+				}
+			}
+		}
+	}
+	return actions
+}
+
+// CurrentAction returns the action selected for the given task
+func (s *State) CurrentAction(task *Task) *Action {
+	if task == nil || s.Config.Action == nil {
+		return nil
+	}
+	for i := range task.Actions {
+		if task.Actions[i].Uuid == *s.Config.Action {
+			return &task.Actions[i]
+		}
+	}
+	return nil
+}
+
+// Responses of the phase prompts
+
+// ToolsAnalysis represents a single tool analysis result
+type ToolsAnalysis struct {
+	Query string `json:"query"`
+	Tool  string `json:"tool"`
+}
+
+// ToolsAnalysisResponse represents the complete analysis response
+type ToolsAnalysisResponse struct {
+	Thinking string          `json:"_thinking"`
+	Result   []ToolsAnalysis `json:"result"`
+}
+
+type TaskThoughts struct {
+	Uuid        interface{} `json:"uuid"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Status      string      `json:"status"`
+}
+
+type TaskThoughtsResponse struct {
+	Thinking string         `json:"_thinking"`
+	Result   []TaskThoughts `json:"result"`
+}
+
+type ActionThoughts struct {
+	Description string      `json:"description"`
+	ToolName    string      `json:"tool_name"`
+	TaskUuid    interface{} `json:"task_uuid"`
+}
+
+// ActionBatch is the result of the action planner: one action, or a list of independent
+// actions for different tasks that can run at the same time
+type ActionBatch []ActionThoughts
+
+func (b *ActionBatch) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var actions []ActionThoughts
+		if err := json.Unmarshal(trimmed, &actions); err != nil {
+			return err
+		}
+		*b = actions
+		return nil
+	}
+	var action ActionThoughts
+	if err := json.Unmarshal(data, &action); err != nil {
+		return err
+	}
+	*b = ActionBatch{action}
+	return nil
+}
+
+type ActionThoughtsResponse struct {
+	Thinking string      `json:"_thinking"`
+	Result   ActionBatch `json:"result"`
+}
+
+type UseThoughtsResponse struct {
+	Thinking string                 `json:"_thinking"`
+	Result   map[string]interface{} `json:"result"`
+}
+
+// ToolSelection is a tool picked together with its payload
+type ToolSelection struct {
+	Tool    string                 `json:"tool"`
+	Payload map[string]interface{} `json:"payload"`
+}
+
+type ToolSelectionResponse struct {
+	Thinking string        `json:"_thinking"`
+	Result   ToolSelection `json:"result"`
+}
+
+// unmarshalResponse parses a JSON mode response of a phase
+func unmarshalResponse[T any](content string) (*T, error) {
+	var response T
+	if err := json.Unmarshal([]byte(content), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
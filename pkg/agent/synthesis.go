@@ -0,0 +1,139 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/crowmw/ai_devs3/pkg/logging"
+	"github.com/crowmw/ai_devs3/pkg/trace"
+)
+
+// Evidence is a completed action the answer is based on
+type Evidence struct {
+	ID      int                    `json:"id"`
+	Task    string                 `json:"task"`
+	Tool    string                 `json:"tool"`
+	Payload map[string]interface{} `json:"payload"`
+	Result  interface{}            `json:"result"`
+}
+
+// Answer is the synthesized answer of a run with the evidence the model cited for it
+type Answer[T any] struct {
+	Answer   T
+	Evidence []Evidence
+}
+
+// SynthesisResponse is the reply of the synthesis phase
+type SynthesisResponse struct {
+	Thinking string          `json:"_thinking"`
+	Answer   json.RawMessage `json:"answer"`
+	Evidence []int           `json:"evidence"`
+}
+
+// Synthesize asks the model for the answer to the user message of a finished run in the shape of T,
+// from the results of all completed actions. The answer is validated against the schema of T
+// and the model gets the problems back until it is valid or the phase fails maxPhaseErrors times.
+func Synthesize[T any](a *Agent) (*Answer[T], error) {
+	log := a.log.With(logging.KeyPhase, "synthesis")
+	ctx, span := trace.Start(a.ctx, "synthesis", trace.KindPhase, logging.KeyPhase, "synthesis", "step", a.State.Config.Step)
+	defer span.End()
+
+	evidence := a.evidence()
+	if len(evidence) == 0 {
+		err := fmt.Errorf("no completed actions to synthesize an answer from")
+		span.SetError(err)
+		return nil, err
+	}
+
+	var zero T
+	t := reflect.TypeOf(&zero).Elem()
+	schema := schemaJSON(schemaForType(t))
+	prompt := a.prompts.Synthesis(&a.State, schema, evidence)
+	log.Info("synthesizing answer", "evidence", len(evidence))
+
+	userMessage := a.State.UserMessage
+	var lastErr error
+	for attempt := 1; attempt <= maxPhaseErrors; attempt++ {
+		content, err := a.complete(ctx, prompt, userMessage)
+		if abortErr := a.budgetAbort(err); abortErr != nil {
+			span.SetError(abortErr)
+			a.Checkpoint(nil, abortErr)
+			return nil, abortErr
+		}
+		if err != nil {
+			span.SetError(err)
+			return nil, fmt.Errorf("error from AI service: %w", err)
+		}
+		answer, err := parseSynthesis[T](t, content, evidence)
+		if err == nil {
+			log.Info("answer synthesized", "attempt", attempt, "evidence", len(answer.Evidence))
+			span.Set("answer", answer.Answer)
+			a.Checkpoint(answer.Answer, nil)
+			return answer, nil
+		}
+		lastErr = err
+		log.Warn("invalid answer", "attempt", attempt, "error", err)
+		// the problems go back with the question so the next attempt can fix them
+		userMessage = fmt.Sprintf("%s\n\nYour previous answer was invalid: %v\nPrevious answer: %s", a.State.UserMessage, err, content)
+	}
+	err := fmt.Errorf("no valid answer after %d attempts: %w", maxPhaseErrors, lastErr)
+	span.SetError(err)
+	return nil, err
+}
+
+// evidence collects the completed actions of the run, final_answer only repeats them
+func (a *Agent) evidence() []Evidence {
+	var evidence []Evidence
+	for _, task := range a.State.Tasks {
+		for _, action := range task.Actions {
+			if action.Status != StatusCompleted || action.ToolName == FinalAnswer || action.Result == nil {
+				continue
+			}
+			evidence = append(evidence, Evidence{
+				ID:      len(evidence) + 1,
+				Task:    task.Name,
+				Tool:    action.ToolName,
+				Payload: action.Payload,
+				Result:  action.Result.Data,
+			})
+		}
+	}
+	return evidence
+}
+
+func parseSynthesis[T any](t reflect.Type, content string, evidence []Evidence) (*Answer[T], error) {
+	response, err := unmarshalResponse[SynthesisResponse](content)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Answer) == 0 || string(response.Answer) == "null" {
+		return nil, fmt.Errorf("missing answer")
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(response.Answer, &value); err != nil {
+		return nil, fmt.Errorf("error parsing answer: %w", err)
+	}
+	var problems []string
+	validateType(t, value, "answer", &problems)
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("answer does not match the schema: %s", strings.Join(problems, "; "))
+	}
+
+	answer := &Answer[T]{}
+	if err := json.Unmarshal(response.Answer, &answer.Answer); err != nil {
+		return nil, fmt.Errorf("error decoding answer: %w", err)
+	}
+	for _, id := range response.Evidence {
+		if id < 1 || id > len(evidence) {
+			return nil, fmt.Errorf("evidence %d does not exist, cite ids from 1 to %d", id, len(evidence))
+		}
+		answer.Evidence = append(answer.Evidence, evidence[id-1])
+	}
+	if len(answer.Evidence) == 0 {
+		return nil, fmt.Errorf("no evidence cited for the answer")
+	}
+	return answer, nil
+}
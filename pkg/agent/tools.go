@@ -0,0 +1,176 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/crowmw/ai_devs3/pkg/logging"
+	"github.com/sashabaranov/go-openai/jsonschema"
+)
+
+// FinalAnswer is the tool that ends a planning run, its result is the answer of the agent
+const FinalAnswer = "final_answer"
+
+// Tool statuses
+const (
+	ToolSuccess = "success"
+	ToolError   = "error"
+)
+
+// Tool is something the agent can do, described to the model by name, description and instruction.
+// Tools declared with NewTool also carry the JSON schema of their payload, payloads are validated against it
+// before the handler runs.
+type Tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Instruction string                 `json:"instruction,omitempty"`
+	Schema      *jsonschema.Definition `json:"schema,omitempty"`
+	// MaxRetries overrides Options.MaxRetries for this tool, negative disables retries
+	MaxRetries int `json:"-"`
+	// Policy decides if a call needs the operator's approval, defaults to auto (see Oversight)
+	Policy  Policy      `json:"-"`
+	Handler ToolHandler `json:"-"`
+}
+
+// NewTool declares a tool with a typed payload, the schema shown to the model is generated from P
+// (see SchemaFor) and the handler receives the decoded payload
+func NewTool[P any](name, description string, handler func(payload P) (ToolResult, error)) Tool {
+	var zero P
+	schema := SchemaFor(zero)
+	return Tool{
+		Name:        name,
+		Description: description,
+		Schema:      &schema,
+		Handler: func(payload map[string]interface{}) (ToolResult, error) {
+			var p P
+			if err := DecodePayload(payload, &p); err != nil {
+				return ErrorResult(err), err
+			}
+			return handler(p)
+		},
+	}
+}
+
+// Validate checks a payload against the schema of the tool, tools without a schema accept any payload
+func (t Tool) Validate(payload map[string]interface{}) error {
+	if t.Schema == nil {
+		return nil
+	}
+	return ValidatePayload(t.Name, *t.Schema, payload)
+}
+
+type ToolResult struct {
+	Status string      `json:"status"`
+	Data   interface{} `json:"data"`
+}
+
+type ToolHandler func(payload map[string]interface{}) (ToolResult, error)
+
+// ErrorResult is returned by handlers together with err
+func ErrorResult(err error) ToolResult {
+	return ToolResult{Status: ToolError, Data: err.Error()}
+}
+
+// DecodePayload converts the payload chosen by the model into the handler's payload struct
+func DecodePayload(payload map[string]interface{}, v any) error {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error marshaling payload: %w", err)
+	}
+	if err := json.Unmarshal(payloadBytes, v); err != nil {
+		return fmt.Errorf("error unmarshaling payload: %w", err)
+	}
+	return nil
+}
+
+// Registry holds the tools of an agent
+type Registry struct {
+	mu    sync.RWMutex
+	tools map[string]Tool
+	order []string
+}
+
+// NewRegistry creates a registry with the given tools
+func NewRegistry(tools ...Tool) (*Registry, error) {
+	r := &Registry{tools: make(map[string]Tool)}
+	for _, tool := range tools {
+		if err := r.Register(tool); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds a tool, names must be unique and every tool needs a handler
+func (r *Registry) Register(tool Tool) error {
+	if tool.Name == "" || tool.Handler == nil {
+		return fmt.Errorf("tool %q needs a name and a handler", tool.Name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.tools[tool.Name]; exists {
+		return fmt.Errorf("tool %s registered twice", tool.Name)
+	}
+	r.tools[tool.Name] = tool
+	r.order = append(r.order, tool.Name)
+	return nil
+}
+
+// Get returns a tool by name
+func (r *Registry) Get(name string) (Tool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tool, ok := r.tools[name]
+	return tool, ok
+}
+
+// Tools returns the tools in registration order
+func (r *Registry) Tools() []Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tools := make([]Tool, 0, len(r.order))
+	for _, name := range r.order {
+		tools = append(tools, r.tools[name])
+	}
+	return tools
+}
+
+// FinalAnswerTool returns the default final_answer tool, it returns the "answer" field of the payload
+func FinalAnswerTool() Tool {
+	return Tool{
+		Name:        FinalAnswer,
+		Description: "Use this to answer the user question",
+		Instruction: `Provide a JSON payload with "answer" field containing the answer for the user, like: {"answer": "John was last seen in Warszawa"}`,
+		Handler:     handleFinalAnswer,
+	}
+}
+
+func handleFinalAnswer(payload map[string]interface{}) (ToolResult, error) {
+	log := logger.With(logging.KeyTool, FinalAnswer)
+
+	// First try to get answer from result.answer structure
+	if result, hasResult := payload["result"]; hasResult {
+		resultMap, ok := result.(map[string]interface{})
+		if !ok {
+			err := fmt.Errorf("'result' field must be an object")
+			return ErrorResult(err), err
+		}
+		answer, hasAnswer := resultMap["answer"]
+		if !hasAnswer {
+			err := fmt.Errorf("missing 'answer' field in result")
+			return ErrorResult(err), err
+		}
+		log.Info("generated response", "answer", prettyPrint(answer))
+		return ToolResult{Status: ToolSuccess, Data: answer}, nil
+	}
+
+	// If no result wrapper, try direct answer field
+	answer, hasAnswer := payload["answer"]
+	if !hasAnswer {
+		err := fmt.Errorf("missing either 'result.answer' or 'answer' field in payload")
+		return ErrorResult(err), err
+	}
+	log.Info("generated response", "answer", prettyPrint(answer))
+	return ToolResult{Status: ToolSuccess, Data: answer}, nil
+}
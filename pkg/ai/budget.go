@@ -0,0 +1,112 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Budget limits, reported by BudgetError
+const (
+	LimitDeadline = "deadline"
+	LimitTokens   = "tokens"
+	LimitCost     = "cost"
+)
+
+// Budget caps the calls of a service returned by WithBudget, zero values are unlimited.
+// Limits are checked before every call, so the call that crosses one still completes.
+type Budget struct {
+	// Deadline also cancels calls still running when it passes
+	Deadline  time.Time
+	MaxTokens int
+	// MaxCost is the estimated cost in USD
+	MaxCost float64
+	// Spent is usage from before the budget, e.g. of the steps a resumed run already took
+	Spent Usage
+}
+
+// BudgetError is returned instead of calling the model once a limit of the budget is reached
+type BudgetError struct {
+	// Limit is LimitDeadline, LimitTokens or LimitCost
+	Limit  string
+	Spent  Usage
+	Budget Budget
+}
+
+func (e *BudgetError) Error() string {
+	switch e.Limit {
+	case LimitTokens:
+		return fmt.Sprintf("token budget exceeded: %d of %d tokens used", e.Spent.TotalTokens(), e.Budget.MaxTokens)
+	case LimitCost:
+		return fmt.Sprintf("cost budget exceeded: $%.4f of $%.4f spent", e.Spent.Cost, e.Budget.MaxCost)
+	default:
+		return fmt.Sprintf("deadline %s exceeded", e.Budget.Deadline.Format(time.TimeOnly))
+	}
+}
+
+// budgetMeter counts the calls made within a budget
+type budgetMeter struct {
+	budget Budget
+	usage  usageMeter
+}
+
+// WithBudget returns a service sharing the client and usage of s whose calls are limited by the budget.
+// Agents make one for every run.
+func (s *Service) WithBudget(budget Budget) *Service {
+	budgeted := *s
+	budgeted.budget = &budgetMeter{budget: budget}
+	return &budgeted
+}
+
+// Spent returns the usage counted against the budget, Budget.Spent included.
+// Services without a budget return Usage.
+func (s *Service) Spent() Usage {
+	if s.budget == nil {
+		return s.Usage()
+	}
+	return s.budget.budget.Spent.Add(s.budget.usage.get())
+}
+
+// CheckBudget returns a *BudgetError once a limit of the budget is reached
+func (s *Service) CheckBudget() error {
+	if s.budget == nil {
+		return nil
+	}
+	budget := s.budget.budget
+	spent := s.Spent()
+	switch {
+	case !budget.Deadline.IsZero() && !time.Now().Before(budget.Deadline):
+		return &BudgetError{Limit: LimitDeadline, Spent: spent, Budget: budget}
+	case budget.MaxTokens > 0 && spent.TotalTokens() >= budget.MaxTokens:
+		return &BudgetError{Limit: LimitTokens, Spent: spent, Budget: budget}
+	case budget.MaxCost > 0 && spent.Cost >= budget.MaxCost:
+		return &BudgetError{Limit: LimitCost, Spent: spent, Budget: budget}
+	}
+	return nil
+}
+
+// begin checks the budget before a call and returns the context to make it with
+func (s *Service) begin() (context.Context, context.CancelFunc, error) {
+	if err := s.CheckBudget(); err != nil {
+		return nil, nil, err
+	}
+	ctx := s.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if s.budget == nil || s.budget.budget.Deadline.IsZero() {
+		return ctx, func() {}, nil
+	}
+	ctx, cancel := context.WithDeadline(ctx, s.budget.budget.Deadline)
+	return ctx, cancel, nil
+}
+
+// record adds the usage of a call to the service and to its budget
+func (s *Service) record(model string, usage openai.Usage) {
+	s.usage.add(model, usage)
+	if s.budget != nil {
+		s.budget.usage.add(model, usage)
+	}
+}
@@ -0,0 +1,48 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/crowmw/ai_devs3/pkg/logging"
+	"github.com/sashabaranov/go-openai"
+)
+
+var logger = logging.New("ai")
+
+// formatMessages renders messages for prompt dumps, image data is replaced by a placeholder
+func formatMessages(messages []openai.ChatCompletionMessage) string {
+	var b strings.Builder
+	for _, message := range messages {
+		fmt.Fprintf(&b, "[%s]\n", message.Role)
+		if message.Content != "" {
+			b.WriteString(message.Content)
+			b.WriteString("\n")
+		}
+		for _, part := range message.MultiContent {
+			switch part.Type {
+			case openai.ChatMessagePartTypeText:
+				b.WriteString(part.Text)
+			case openai.ChatMessagePartTypeImageURL:
+				b.WriteString("<image>")
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// logCompletion logs token usage and, when dumping is enabled, the prompt and the reply
+func logCompletion(model string, messages []openai.ChatCompletionMessage, resp openai.ChatCompletionResponse) {
+	logger.Debug("chat completion",
+		"model", model,
+		"messages", len(messages),
+		"prompt_tokens", resp.Usage.PromptTokens,
+		"completion_tokens", resp.Usage.CompletionTokens,
+	)
+	content := ""
+	if len(resp.Choices) > 0 {
+		content = resp.Choices[0].Message.Content
+	}
+	logging.Dump(logger, "chat completion dump", "model", model, "prompt", formatMessages(messages), "response", content)
+}
@@ -1,27 +1,38 @@
 package ai
 
 import (
-	"context"
 	"fmt"
 	"os"
 
-	"github.com/crowmw/ai_devs3/pkg/config"
-
+	"github.com/crowmw/ai_devs3/pkg/logging"
+	"github.com/crowmw/ai_devs3/pkg/trace"
 	openai "github.com/sashabaranov/go-openai"
 )
 
-func SendChatCompletion(model string, store bool, messages []openai.ChatCompletionMessage) (string, error) {
-	client := openai.NewClient(config.GetOpenAIKey())
+// SendChatCompletion sends messages to the model and returns the first choice content
+func (s *Service) SendChatCompletion(model string, store bool, messages []openai.ChatCompletionMessage) (string, error) {
+	if s.model != "" {
+		model = s.model
+	}
 
 	req := openai.ChatCompletionRequest{
 		Model:    model,
 		Messages: messages,
 	}
 
-	resp, err := client.CreateChatCompletion(context.Background(), req)
+	ctx, cancel, err := s.begin()
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+	ctx, span := startCompletionSpan(ctx, model, messages)
+	resp, err := s.openai.CreateChatCompletion(ctx, req)
+	endCompletionSpan(span, resp, err)
 	if err != nil {
 		return "", fmt.Errorf("error creating chat completion: %w", err)
 	}
+	s.record(model, resp.Usage)
+	logCompletion(model, messages, resp)
 
 	if len(resp.Choices) == 0 {
 		return "", fmt.Errorf("no choices in response")
@@ -31,9 +42,7 @@ func SendChatCompletion(model string, store bool, messages []openai.ChatCompleti
 }
 
 // TranscribeAudio transcribes an audio file using OpenAI's Whisper model
-func TranscribeAudio(audioFilePath string) (string, error) {
-	client := openai.NewClient(config.GetOpenAIKey())
-
+func (s *Service) TranscribeAudio(audioFilePath string) (string, error) {
 	// Open the audio file
 	audioFile, err := os.Open(audioFilePath)
 	if err != nil {
@@ -49,8 +58,17 @@ func TranscribeAudio(audioFilePath string) (string, error) {
 	}
 
 	// Send the request to OpenAI
-	fmt.Println("Sending audio to OpenAI...")
-	resp, err := client.CreateTranscription(context.Background(), req)
+	logger.Info("transcribing audio", "file", audioFilePath)
+	ctx, cancel, err := s.begin()
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+	ctx, span := trace.Start(ctx, "transcription", trace.KindLLM, "model", req.Model, "file", audioFilePath)
+	resp, err := s.openai.CreateTranscription(ctx, req)
+	span.SetError(err)
+	span.Set("response", resp.Text)
+	span.End()
 	if err != nil {
 		return "", fmt.Errorf("error creating transcription: %w", err)
 	}
@@ -59,8 +77,8 @@ func TranscribeAudio(audioFilePath string) (string, error) {
 }
 
 // TranscribeAudioAndFormat transcribes an audio file and formats the output with a header
-func TranscribeAudioAndFormat(audioFilePath string) (string, error) {
-	transcription, err := TranscribeAudio(audioFilePath)
+func (s *Service) TranscribeAudioAndFormat(audioFilePath string) (string, error) {
+	transcription, err := s.TranscribeAudio(audioFilePath)
 	if err != nil {
 		return "", fmt.Errorf("error transcribing audio: %w", err)
 	}
@@ -71,16 +89,15 @@ func TranscribeAudioAndFormat(audioFilePath string) (string, error) {
 }
 
 // DescribeImageAndFormat describes an image using GPT-4 Vision with proper format handling
-func DescribeImageAndFormat(imageBase64 string, format string) (string, error) {
+func (s *Service) DescribeImageAndFormat(imageBase64 string, format string) (string, error) {
 	prompt := GetImageAnalysisPrompt(imageBase64, format, "Please analyze these image, in a few sentences, do not include any other text in your response, just the analysis, in Polish language, add information before that said about this is an image description search for the subject of the image:")
-	return SendChatCompletion("gpt-4o-mini", false, prompt)
+	return s.SendChatCompletion("gpt-4o-mini", false, prompt)
 }
 
 // GenerateImageWithDalle generates an image using DALL-E 3 model
-func GenerateImageWithDalle(prompt string) (string, error) {
-	fmt.Println("Generating image with DALL-E 3...")
-	client := openai.NewClient(config.GetOpenAIKey())
-
+func (s *Service) GenerateImageWithDalle(prompt string) (string, error) {
+	logger.Info("generating image", "model", "dall-e-3")
+	logging.Dump(logger, "image prompt", "prompt", prompt)
 	req := openai.ImageRequest{
 		Model:   "dall-e-3",
 		Prompt:  prompt,
@@ -90,7 +107,15 @@ func GenerateImageWithDalle(prompt string) (string, error) {
 		Style:   "natural",
 	}
 
-	resp, err := client.CreateImage(context.Background(), req)
+	ctx, cancel, err := s.begin()
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+	ctx, span := trace.Start(ctx, "image generation", trace.KindLLM, "model", req.Model, "prompt", prompt)
+	resp, err := s.openai.CreateImage(ctx, req)
+	span.SetError(err)
+	span.End()
 	if err != nil {
 		return "", fmt.Errorf("error creating image: %w", err)
 	}
@@ -103,9 +128,8 @@ func GenerateImageWithDalle(prompt string) (string, error) {
 }
 
 // DescribeImage describes an image using GPT-4 Vision
-func DescribeImage(imageBase64 string, format string, userText string) (string, error) {
-	fmt.Println("Describing image...")
+func (s *Service) DescribeImage(imageBase64 string, format string, userText string) (string, error) {
+	logger.Info("describing image", "format", format)
 	prompt := GetImageAnalysisPrompt(imageBase64, format, userText)
-	fmt.Println("Prompt:", prompt)
-	return SendChatCompletion("gpt-4o-mini", true, prompt)
+	return s.SendChatCompletion("gpt-4o-mini", true, prompt)
 }
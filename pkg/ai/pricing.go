@@ -0,0 +1,42 @@
+package ai
+
+import "strings"
+
+// price is USD per million input and output tokens
+type price struct {
+	input  float64
+	output float64
+}
+
+// prices lists OpenAI and Jina list prices, the longest matching model prefix wins.
+// Costs are estimates, check the billing page for real numbers.
+var prices = map[string]price{
+	"gpt-4o-mini":            {0.15, 0.60},
+	"gpt-4o":                 {2.50, 10.00},
+	"gpt-4.1-nano":           {0.10, 0.40},
+	"gpt-4.1-mini":           {0.40, 1.60},
+	"gpt-4.1":                {2.00, 8.00},
+	"gpt-4-turbo":            {10.00, 30.00},
+	"gpt-4":                  {30.00, 60.00},
+	"gpt-3.5-turbo":          {0.50, 1.50},
+	"o1-mini":                {1.10, 4.40},
+	"o1":                     {15.00, 60.00},
+	"text-embedding-3-small": {0.02, 0},
+	"text-embedding-3-large": {0.13, 0},
+	"jina-embeddings-v3":     {0.05, 0},
+}
+
+// EstimateCost returns the USD cost of a call from its token usage, zero for unknown models
+func EstimateCost(model string, promptTokens, completionTokens int) float64 {
+	var best string
+	for prefix := range prices {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return 0
+	}
+	p := prices[best]
+	return (float64(promptTokens)*p.input + float64(completionTokens)*p.output) / 1e6
+}
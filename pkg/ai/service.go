@@ -11,34 +11,64 @@ import (
 	"os"
 
 	"github.com/crowmw/ai_devs3/pkg/env"
+	"github.com/crowmw/ai_devs3/pkg/trace"
 	"github.com/sashabaranov/go-openai"
 )
 
 type Service struct {
 	openai *openai.Client
 	envSvc *env.Service
+	model  string
+	// usage is shared with the budgeted copies of the service
+	usage  *usageMeter
+	budget *budgetMeter
+	// ctx is the parent of the calls, see WithContext
+	ctx context.Context
 }
 
 func NewService(envSvc *env.Service) (*Service, error) {
+	if err := envSvc.Require(env.KeyOpenAIKey); err != nil {
+		return nil, err
+	}
+
 	client := openai.NewClient(envSvc.GetOpenAIKey())
 
 	return &Service{
 		openai: client,
 		envSvc: envSvc,
+		usage:  &usageMeter{},
 	}, nil
 }
 
+// WithContext returns a service sharing the client, usage and budget of s whose calls are made with ctx,
+// so their trace spans are children of the span ctx carries
+func (s *Service) WithContext(ctx context.Context) *Service {
+	scoped := *s
+	scoped.ctx = ctx
+	return &scoped
+}
+
 func (s *Service) CreateOpenAIEmbedding(text string) ([]float32, error) {
+	ctx, cancel, err := s.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	ctx, span := trace.Start(ctx, "embedding", trace.KindLLM, "model", "text-embedding-3-small", "chars", len(text))
+	defer span.End()
 	response, err := s.openai.CreateEmbeddings(
-		context.Background(),
+		ctx,
 		openai.EmbeddingRequest{
 			Input: []string{text},
 			Model: openai.EmbeddingModel("text-embedding-3-small"),
 		},
 	)
 	if err != nil {
+		span.SetError(err)
 		return nil, fmt.Errorf("error creating embedding: %w", err)
 	}
+	span.Set("prompt_tokens", response.Usage.PromptTokens)
+	s.record("text-embedding-3-small", response.Usage)
 	return response.Data[0].Embedding, nil
 }
 
@@ -55,9 +85,20 @@ type jinaEmbeddingResponse struct {
 	Data []struct {
 		Embedding []float32 `json:"embedding"`
 	} `json:"data"`
+	Usage struct {
+		PromptTokens int `json:"prompt_tokens"`
+		TotalTokens  int `json:"total_tokens"`
+	} `json:"usage"`
 }
 
 func (s *Service) CreateJinaEmbedding(text string) ([]float32, error) {
+	ctx, cancel, err := s.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	ctx, span := trace.Start(ctx, "embedding", trace.KindLLM, "model", "jina-embeddings-v3", "chars", len(text))
+	defer span.End()
 	reqBody := jinaEmbeddingRequest{
 		Model:         "jina-embeddings-v3",
 		Task:          "text-matching",
@@ -72,7 +113,7 @@ func (s *Service) CreateJinaEmbedding(text string) ([]float32, error) {
 		return nil, fmt.Errorf("error marshaling request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", "https://api.jina.ai/v1/embeddings", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.jina.ai/v1/embeddings", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
@@ -101,9 +142,16 @@ func (s *Service) CreateJinaEmbedding(text string) ([]float32, error) {
 		return nil, fmt.Errorf("empty embedding in response")
 	}
 
+	span.Set("prompt_tokens", result.Usage.PromptTokens)
+	s.record("jina-embeddings-v3", openai.Usage{PromptTokens: result.Usage.PromptTokens, TotalTokens: result.Usage.TotalTokens})
 	return result.Data[0].Embedding, nil
 }
 
+// SetModel forces every chat completion to use the given model, an empty string restores per-call models
+func (s *Service) SetModel(model string) {
+	s.model = model
+}
+
 // ChatCompletionConfig holds the configuration for chat completion
 type ChatCompletionConfig struct {
 	Messages  []openai.ChatCompletionMessage
@@ -116,6 +164,9 @@ type ChatCompletionConfig struct {
 // ChatCompletion handles chat completions with configurable options
 func (s *Service) ChatCompletion(config ChatCompletionConfig) (openai.ChatCompletionResponse, error) {
 	// Set default values
+	if s.model != "" {
+		config.Model = s.model
+	}
 	if config.Model == "" {
 		config.Model = "gpt-4"
 	}
@@ -143,10 +194,20 @@ func (s *Service) ChatCompletion(config ChatCompletionConfig) (openai.ChatComple
 	}
 
 	// Make the request
-	response, err := s.openai.CreateChatCompletion(context.Background(), req)
+	ctx, cancel, err := s.begin()
+	if err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+	defer cancel()
+	ctx, span := startCompletionSpan(ctx, config.Model, config.Messages)
+	span.Set("json_mode", config.JSONMode)
+	response, err := s.openai.CreateChatCompletion(ctx, req)
+	endCompletionSpan(span, response, err)
 	if err != nil {
 		return openai.ChatCompletionResponse{}, fmt.Errorf("error in OpenAI completion: %w", err)
 	}
+	s.record(config.Model, response.Usage)
+	logCompletion(config.Model, config.Messages, response)
 
 	return response, nil
 }
@@ -159,18 +220,17 @@ type OCRImageResult struct {
 
 // ProcessImage processes an image using GPT-4 Vision
 func (s *Service) OCRImage(imagePath string) (*OCRImageResult, error) {
+	logger.Info("reading text from image", "file", imagePath)
+
 	// Read the image file
-	fmt.Println("Reading image file...")
 	imageData, err := os.ReadFile(imagePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read image file: %w", err)
 	}
 
-	fmt.Println("Converting image to base64...")
 	// Convert image to base64
 	base64Image := base64.StdEncoding.EncodeToString(imageData)
 
-	fmt.Println("Creating chat completion request...")
 	// Create the chat completion request
 	response, err := s.ChatCompletion(ChatCompletionConfig{
 		Model: "gpt-4.1",
@@ -205,18 +265,17 @@ func (s *Service) OCRImage(imagePath string) (*OCRImageResult, error) {
 
 // ProcessImage processes an image using GPT-4 Vision
 func (s *Service) ImageAnalysis(imagePath string) (string, error) {
+	logger.Info("analyzing image", "file", imagePath)
+
 	// Read the image file
-	fmt.Println("Reading image file...")
 	imageData, err := os.ReadFile(imagePath)
 	if err != nil {
 		return "", fmt.Errorf("failed to read image file: %w", err)
 	}
 
-	fmt.Println("Converting image to base64...")
 	// Convert image to base64
 	base64Image := base64.StdEncoding.EncodeToString(imageData)
 
-	fmt.Println("Creating chat completion request...")
 	// Create the chat completion request
 	response, err := s.ChatCompletion(ChatCompletionConfig{
 		Model: "gpt-4.1",
@@ -247,7 +306,7 @@ func (s *Service) ImageAnalysis(imagePath string) (string, error) {
 }
 
 func (s *Service) AudioAnalysis(audioFileUrl string) (string, error) {
-	fmt.Printf("🎵 [AUDIO] Starting audio analysis for URL: %s\n", audioFileUrl)
+	logger.Info("starting audio analysis", "url", audioFileUrl)
 
 	// Create a temporary file to store the audio
 	tempFile, err := os.CreateTemp("", "audio-*.mp3")
@@ -257,7 +316,7 @@ func (s *Service) AudioAnalysis(audioFileUrl string) (string, error) {
 	defer os.Remove(tempFile.Name()) // Clean up the temp file when done
 	defer tempFile.Close()
 
-	fmt.Println("📥 [AUDIO] Downloading audio file...")
+	logger.Debug("downloading audio file")
 	// Download the audio file
 	httpResp, err := http.Get(audioFileUrl)
 	if err != nil {
@@ -280,7 +339,7 @@ func (s *Service) AudioAnalysis(audioFileUrl string) (string, error) {
 		return "", fmt.Errorf("error syncing temp file: %w", err)
 	}
 
-	fmt.Println("🤖 [AUDIO] Sending audio to OpenAI for transcription...")
+	logger.Debug("sending audio for transcription")
 	// Create the transcription request
 	req := openai.AudioRequest{
 		Model:    openai.Whisper1,
@@ -289,11 +348,20 @@ func (s *Service) AudioAnalysis(audioFileUrl string) (string, error) {
 	}
 
 	// Send the request to OpenAI
-	transcriptionResp, err := s.openai.CreateTranscription(context.Background(), req)
+	ctx, cancel, err := s.begin()
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+	ctx, span := trace.Start(ctx, "transcription", trace.KindLLM, "model", req.Model, "url", audioFileUrl)
+	transcriptionResp, err := s.openai.CreateTranscription(ctx, req)
+	span.SetError(err)
+	span.Set("response", transcriptionResp.Text)
+	span.End()
 	if err != nil {
 		return "", fmt.Errorf("error creating transcription: %w", err)
 	}
 
-	fmt.Println("✅ [AUDIO] Successfully transcribed audio")
+	logger.Info("audio transcribed", "chars", len(transcriptionResp.Text))
 	return transcriptionResp.Text, nil
 }
@@ -0,0 +1,65 @@
+package ai
+
+import (
+	"context"
+
+	"github.com/crowmw/ai_devs3/pkg/trace"
+	"github.com/sashabaranov/go-openai"
+)
+
+// traceMessage is a chat message as stored in traces, image data is replaced by a placeholder
+type traceMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+func traceMessages(messages []openai.ChatCompletionMessage) []traceMessage {
+	result := make([]traceMessage, 0, len(messages))
+	for _, message := range messages {
+		content := message.Content
+		for _, part := range message.MultiContent {
+			switch part.Type {
+			case openai.ChatMessagePartTypeText:
+				content += part.Text
+			case openai.ChatMessagePartTypeImageURL:
+				content += "<image>"
+			}
+		}
+		result = append(result, traceMessage{Role: message.Role, Content: content})
+	}
+	return result
+}
+
+// startCompletionSpan opens an llm span with the request, finish it with endCompletionSpan
+func startCompletionSpan(ctx context.Context, model string, messages []openai.ChatCompletionMessage) (context.Context, *trace.Span) {
+	if !trace.Enabled() {
+		return ctx, nil
+	}
+	return trace.Start(ctx, "chat completion", trace.KindLLM,
+		"model", model,
+		"messages", traceMessages(messages),
+	)
+}
+
+// endCompletionSpan records the response, token usage and error of a chat completion
+func endCompletionSpan(span *trace.Span, resp openai.ChatCompletionResponse, err error) {
+	if span == nil {
+		return
+	}
+	defer span.End()
+	if err != nil {
+		span.SetError(err)
+		return
+	}
+	span.Set(
+		"prompt_tokens", resp.Usage.PromptTokens,
+		"completion_tokens", resp.Usage.CompletionTokens,
+		"total_tokens", resp.Usage.TotalTokens,
+	)
+	if len(resp.Choices) > 0 {
+		span.Set(
+			"response", resp.Choices[0].Message.Content,
+			"finish_reason", string(resp.Choices[0].FinishReason),
+		)
+	}
+}
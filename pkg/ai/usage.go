@@ -0,0 +1,66 @@
+package ai
+
+import (
+	"sync"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Usage is the token count and estimated cost of the calls made by a service
+type Usage struct {
+	Calls            int
+	PromptTokens     int
+	CompletionTokens int
+	Cost             float64
+}
+
+// TotalTokens returns prompt and completion tokens together
+func (u Usage) TotalTokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// Sub returns the usage since an earlier snapshot
+func (u Usage) Sub(earlier Usage) Usage {
+	return Usage{
+		Calls:            u.Calls - earlier.Calls,
+		PromptTokens:     u.PromptTokens - earlier.PromptTokens,
+		CompletionTokens: u.CompletionTokens - earlier.CompletionTokens,
+		Cost:             u.Cost - earlier.Cost,
+	}
+}
+
+// Add returns the usage of both
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		Calls:            u.Calls + other.Calls,
+		PromptTokens:     u.PromptTokens + other.PromptTokens,
+		CompletionTokens: u.CompletionTokens + other.CompletionTokens,
+		Cost:             u.Cost + other.Cost,
+	}
+}
+
+// usageMeter sums the usage of every call, services are shared by concurrent agent actions
+type usageMeter struct {
+	mu    sync.Mutex
+	usage Usage
+}
+
+func (m *usageMeter) add(model string, usage openai.Usage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usage.Calls++
+	m.usage.PromptTokens += usage.PromptTokens
+	m.usage.CompletionTokens += usage.CompletionTokens
+	m.usage.Cost += EstimateCost(model, usage.PromptTokens, usage.CompletionTokens)
+}
+
+func (m *usageMeter) get() Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.usage
+}
+
+// Usage returns the usage of all calls made by the service so far
+func (s *Service) Usage() Usage {
+	return s.usage.get()
+}
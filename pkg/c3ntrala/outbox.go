@@ -0,0 +1,175 @@
+package c3ntrala
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/crowmw/ai_devs3/pkg/logging"
+)
+
+// OutboxEntry is a report held back by dry-run mode until it is submitted
+type OutboxEntry struct {
+	ID         string          `json:"id"`
+	Task       string          `json:"task"`
+	Answer     json.RawMessage `json:"answer"`
+	JustUpdate bool            `json:"justUpdate,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	// Endpoint is the verify URL of a report sent outside C3ntrala, empty for the /report of C3ntrala
+	Endpoint string `json:"endpoint,omitempty"`
+	// Warnings are validation notes that did not stop the report, e.g. an answer that was already tried
+	Warnings    []string      `json:"warnings,omitempty"`
+	SubmittedAt *time.Time    `json:"submittedAt,omitempty"`
+	Result      *ReportResult `json:"result,omitempty"`
+}
+
+// Submitted reports whether the entry was already sent
+func (e *OutboxEntry) Submitted() bool {
+	return e.SubmittedAt != nil
+}
+
+// ValidateAnswer checks an answer before it is sent or saved to the outbox.
+// Errors mean the answer cannot be sent, warnings are worth a look before submitting.
+func (s *Service) ValidateAnswer(task string, answer interface{}) ([]string, error) {
+	if strings.TrimSpace(task) == "" {
+		return nil, fmt.Errorf("task name is empty")
+	}
+
+	answerJSON, err := json.Marshal(answer)
+	if err != nil {
+		return nil, fmt.Errorf("answer is not valid JSON: %w", err)
+	}
+	switch strings.TrimSpace(string(answerJSON)) {
+	case "null", `""`, "[]", "{}":
+		return nil, fmt.Errorf("answer is empty")
+	}
+
+	var warnings []string
+	if attempt, tried, err := s.WasTried(task, answer); err != nil {
+		warnings = append(warnings, fmt.Sprintf("could not read report history: %v", err))
+	} else if tried {
+		warning := "same answer was already sent on " + attempt.Timestamp.Format(time.RFC3339)
+		if attempt.Result != nil {
+			warning += ": " + attempt.Result.String()
+		}
+		warnings = append(warnings, warning)
+	}
+	return warnings, nil
+}
+
+func (s *Service) outboxFile(id string) string {
+	return filepath.Join(s.outboxDir, id+".json")
+}
+
+// saveToOutbox validates the answer, prints a preview and stores it in the outbox
+func (s *Service) saveToOutbox(endpoint, task string, answer interface{}, justUpdate bool) (*OutboxEntry, error) {
+	warnings, err := s.ValidateAnswer(task, answer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid answer for %s: %w", task, err)
+	}
+
+	answerJSON, err := json.MarshalIndent(answer, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling answer: %w", err)
+	}
+
+	now := time.Now()
+	entry := &OutboxEntry{
+		ID:         fmt.Sprintf("%s-%s", task, now.Format("20060102-150405.000")),
+		Endpoint:   endpoint,
+		Task:       task,
+		Answer:     answerJSON,
+		JustUpdate: justUpdate,
+		CreatedAt:  now,
+		Warnings:   warnings,
+	}
+	if err := s.writeOutboxEntry(entry); err != nil {
+		return nil, err
+	}
+
+	logger.Info("dry run, report saved to outbox", logging.KeyTask, task, "id", entry.ID, "answer", string(answerJSON))
+	for _, warning := range warnings {
+		logger.Warn(warning, logging.KeyTask, task)
+	}
+	logger.Info("send it with: aidevs submit " + entry.ID)
+	return entry, nil
+}
+
+func (s *Service) writeOutboxEntry(entry *OutboxEntry) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling outbox entry: %w", err)
+	}
+	if err := os.MkdirAll(s.outboxDir, 0755); err != nil {
+		return fmt.Errorf("error creating outbox directory: %w", err)
+	}
+	// Not redacted, answers may carry the API key (s01e03) and must be sent back unchanged
+	if err := os.WriteFile(s.outboxFile(entry.ID), data, 0600); err != nil {
+		return fmt.Errorf("error writing outbox entry: %w", err)
+	}
+	return nil
+}
+
+// OutboxEntry returns a saved report by its ID
+func (s *Service) OutboxEntry(id string) (*OutboxEntry, error) {
+	data, err := os.ReadFile(s.outboxFile(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no outbox entry %q", id)
+		}
+		return nil, fmt.Errorf("error reading outbox entry: %w", err)
+	}
+	var entry OutboxEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("error parsing outbox entry %s: %w", id, err)
+	}
+	return &entry, nil
+}
+
+// Outbox returns all saved reports, oldest first
+func (s *Service) Outbox() ([]OutboxEntry, error) {
+	files, err := filepath.Glob(filepath.Join(s.outboxDir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("error listing outbox: %w", err)
+	}
+
+	entries := []OutboxEntry{}
+	for _, file := range files {
+		entry, err := s.OutboxEntry(strings.TrimSuffix(filepath.Base(file), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].CreatedAt.Before(entries[j].CreatedAt) })
+	return entries, nil
+}
+
+// Submit sends a reviewed outbox entry to C3ntrala or its verify endpoint, even in dry-run mode, and records the result.
+// Entries that were already sent are refused unless force is set.
+func (s *Service) Submit(id string, force bool) (*ReportResult, error) {
+	entry, err := s.OutboxEntry(id)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Submitted() && !force {
+		return entry.Result, fmt.Errorf("outbox entry %s was already sent on %s", id, entry.SubmittedAt.Format(time.RFC3339))
+	}
+
+	// Keep the answer as raw JSON so it is sent exactly as reviewed
+	result, err := s.sendReport(entry.Endpoint, entry.Task, entry.Answer, entry.JustUpdate)
+	s.recordAttempt(entry.Task, entry.Answer, result)
+	if result != nil {
+		now := time.Now()
+		entry.SubmittedAt = &now
+		entry.Result = result
+		if saveErr := s.writeOutboxEntry(entry); saveErr != nil {
+			logger.Warn("could not update outbox entry", "id", id, "error", saveErr)
+		}
+	}
+	return result, err
+}
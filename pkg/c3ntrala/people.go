@@ -0,0 +1,124 @@
+package c3ntrala
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/crowmw/ai_devs3/pkg/http"
+	"github.com/crowmw/ai_devs3/pkg/textpl"
+)
+
+const (
+	restrictedDataMarker = "[**RESTRICTED DATA**]"
+	restrictedDataToken  = "\x00RESTRICTED\x00"
+)
+
+// APIError is returned when C3ntrala responds with a non-zero code
+type APIError struct {
+	Code    int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("c3ntrala error %d: %s", e.Code, e.Message)
+}
+
+// SightingEntry is a single name returned by the /people or /places endpoint
+type SightingEntry struct {
+	Name       string `json:"name"`
+	Restricted bool   `json:"restricted"`
+}
+
+// SightingsResult is the parsed response of the /people or /places endpoint
+type SightingsResult struct {
+	Query   string          `json:"query"`
+	Entries []SightingEntry `json:"entries"`
+	// RestrictedCount is the number of entries hidden behind the restricted data marker
+	RestrictedCount int    `json:"restricted_count"`
+	RawMessage      string `json:"raw_message"`
+}
+
+// Names returns normalized, deduplicated names that are not restricted
+func (r *SightingsResult) Names() []string {
+	names := make([]string, 0, len(r.Entries))
+	for _, entry := range r.Entries {
+		if !entry.Restricted {
+			names = append(names, entry.Name)
+		}
+	}
+	return names
+}
+
+// NormalizeName converts a person or place name to the form used by C3ntrala: uppercase without Polish characters
+func NormalizeName(name string) string {
+	return textpl.Upper(name)
+}
+
+// parseSightings parses a people/places response body into a typed result
+func parseSightings(query string, body string) (*SightingsResult, error) {
+	var response struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		return nil, fmt.Errorf("error parsing response: %w", err)
+	}
+	if response.Code != 0 {
+		return nil, &APIError{Code: response.Code, Message: response.Message}
+	}
+
+	result := &SightingsResult{
+		Query:      query,
+		Entries:    []SightingEntry{},
+		RawMessage: response.Message,
+	}
+
+	// The marker contains a space and may be glued to a name, so swap it for a single token before splitting
+	message := strings.ReplaceAll(response.Message, restrictedDataMarker, " "+restrictedDataToken+" ")
+	seen := make(map[string]bool)
+	for _, token := range strings.Fields(message) {
+		if token == restrictedDataToken {
+			result.RestrictedCount++
+			result.Entries = append(result.Entries, SightingEntry{Restricted: true})
+			continue
+		}
+
+		name := NormalizeName(token)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		result.Entries = append(result.Entries, SightingEntry{Name: name})
+	}
+
+	return result, nil
+}
+
+// querySightings sends a query to the /people or /places endpoint
+func (s *Service) querySightings(endpoint string, query string) (*SightingsResult, error) {
+	query = NormalizeName(query)
+	body, err := http.SendPost(s.baseUrl+endpoint, map[string]interface{}{
+		"apikey": s.apiKey,
+		"query":  query,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := parseSightings(query, body)
+	if err != nil {
+		return nil, fmt.Errorf("%s query %q: %w", endpoint, query, err)
+	}
+	return result, nil
+}
+
+// QueryPeople returns the places where the person with the given first name was seen
+func (s *Service) QueryPeople(firstName string) (*SightingsResult, error) {
+	return s.querySightings("/people", firstName)
+}
+
+// QueryPlaces returns the people who were seen in the given city
+func (s *Service) QueryPlaces(city string) (*SightingsResult, error) {
+	return s.querySightings("/places", city)
+}
@@ -0,0 +1,76 @@
+package c3ntrala
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseSightings(t *testing.T) {
+	restricted := SightingEntry{Restricted: true}
+	tests := []struct {
+		name           string
+		body           string
+		want           []SightingEntry
+		wantRestricted int
+		wantCode       int
+	}{
+		{
+			name: "names normalized",
+			body: `{"code":0,"message":"Kraków  warszawa"}`,
+			want: []SightingEntry{{Name: "KRAKOW"}, {Name: "WARSZAWA"}},
+		},
+		{
+			name: "duplicates dropped",
+			body: `{"code":0,"message":"BARBARA ALEKSANDER barbara Barbara"}`,
+			want: []SightingEntry{{Name: "BARBARA"}, {Name: "ALEKSANDER"}},
+		},
+		{
+			name:           "restricted marker is one entry",
+			body:           `{"code":0,"message":"RAFAL [**RESTRICTED DATA**] AZAZEL"}`,
+			want:           []SightingEntry{{Name: "RAFAL"}, restricted, {Name: "AZAZEL"}},
+			wantRestricted: 1,
+		},
+		{
+			name:           "restricted marker glued to names",
+			body:           `{"code":0,"message":"RAFAL[**RESTRICTED DATA**][**RESTRICTED DATA**]AZAZEL"}`,
+			want:           []SightingEntry{{Name: "RAFAL"}, restricted, restricted, {Name: "AZAZEL"}},
+			wantRestricted: 2,
+		},
+		{
+			name: "empty message",
+			body: `{"code":0,"message":""}`,
+			want: []SightingEntry{},
+		},
+		{
+			name:     "non-zero code",
+			body:     `{"code":-200,"message":"unknown query"}`,
+			wantCode: -200,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseSightings("QUERY", tt.body)
+			if tt.wantCode != 0 {
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) || apiErr.Code != tt.wantCode {
+					t.Fatalf("got error %v, want APIError with code %d", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result.Entries, tt.want) {
+				t.Errorf("got entries %+v, want %+v", result.Entries, tt.want)
+			}
+			if result.RestrictedCount != tt.wantRestricted {
+				t.Errorf("got %d restricted, want %d", result.RestrictedCount, tt.wantRestricted)
+			}
+		})
+	}
+
+	if _, err := parseSightings("QUERY", "not json"); err == nil {
+		t.Error("want an error for a body that is not JSON")
+	}
+}
@@ -0,0 +1,270 @@
+package c3ntrala
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/crowmw/ai_devs3/pkg/http"
+	"github.com/crowmw/ai_devs3/pkg/logging"
+	"github.com/crowmw/ai_devs3/pkg/redact"
+)
+
+// flagPattern matches flags like {{FLG:SOMETHING}}
+var flagPattern = regexp.MustCompile(`\{\{FLG:([^}]+)\}\}`)
+
+// ReportResult is the parsed response of the /report endpoint
+type ReportResult struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`
+	Debug   string `json:"debug,omitempty"`
+	// Flag is the flag name found anywhere in the response, e.g. "SOMETHING" for {{FLG:SOMETHING}}
+	Flag string `json:"flag,omitempty"`
+	Raw  string `json:"raw"`
+	// DryRun marks the placeholder result of an answer saved to the outbox instead of being sent
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// HasFlag reports whether the response contains a flag
+func (r *ReportResult) HasFlag() bool {
+	return r.Flag != ""
+}
+
+// Decode unmarshals the raw response into v, for tasks returning extra fields
+func (r *ReportResult) Decode(v interface{}) error {
+	return json.Unmarshal([]byte(r.Raw), v)
+}
+
+func (r *ReportResult) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "code=%d message=%q", r.Code, r.Message)
+	if r.Hint != "" {
+		fmt.Fprintf(&b, " hint=%q", r.Hint)
+	}
+	if r.Debug != "" {
+		fmt.Fprintf(&b, " debug=%q", r.Debug)
+	}
+	if r.Flag != "" {
+		fmt.Fprintf(&b, " flag=%s", r.Flag)
+	}
+	return b.String()
+}
+
+// parseReportResult parses a /report response body. Hint and debug may be any JSON value.
+func parseReportResult(body string) (*ReportResult, error) {
+	var response struct {
+		Code    int             `json:"code"`
+		Message json.RawMessage `json:"message"`
+		Hint    json.RawMessage `json:"hint"`
+		Debug   json.RawMessage `json:"debug"`
+	}
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		return nil, fmt.Errorf("error parsing report response: %w (body: %s)", err, body)
+	}
+
+	result := &ReportResult{
+		Code:    response.Code,
+		Message: rawToString(response.Message),
+		Hint:    rawToString(response.Hint),
+		Debug:   rawToString(response.Debug),
+		Raw:     body,
+	}
+	if matches := flagPattern.FindStringSubmatch(body); len(matches) > 1 {
+		result.Flag = matches[1]
+	}
+	return result, nil
+}
+
+// rawToString returns JSON strings unquoted and any other JSON value as text
+func rawToString(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
+
+// PostReport sends an answer for the task to C3ntrala.
+// In dry-run mode the answer is validated and saved to the outbox instead, see Submit.
+// Negative response codes are returned as *APIError together with the parsed result.
+func (s *Service) PostReport(task string, answer interface{}, justUpdate bool) (*ReportResult, error) {
+	return s.post("", task, answer, justUpdate)
+}
+
+// PostVerify sends an answer to a verify endpoint outside C3ntrala, e.g. the poligon's /verify,
+// with the dry-run mode, outbox and history of PostReport
+func (s *Service) PostVerify(endpoint, task string, answer interface{}) (*ReportResult, error) {
+	return s.post(endpoint, task, answer, false)
+}
+
+// post sends a report to the endpoint, an empty endpoint is the /report of C3ntrala
+func (s *Service) post(endpoint, task string, answer interface{}, justUpdate bool) (*ReportResult, error) {
+	if s.dryRun {
+		entry, err := s.saveToOutbox(endpoint, task, answer, justUpdate)
+		if err != nil {
+			return nil, err
+		}
+		return &ReportResult{Message: "dry run, saved to outbox as " + entry.ID, DryRun: true}, nil
+	}
+	if _, err := s.ValidateAnswer(task, answer); err != nil {
+		return nil, fmt.Errorf("invalid answer for %s: %w", task, err)
+	}
+	result, err := s.sendReport(endpoint, task, answer, justUpdate)
+	s.recordAttempt(task, answer, result)
+	return result, err
+}
+
+// Converse sends a command of a task's dialogue with C3ntrala, e.g. "START" to get the photos.
+// Commands are not answers: they are sent even in dry-run mode and kept out of the report history.
+// Negative response codes are returned as *APIError together with the parsed result.
+func (s *Service) Converse(task string, command interface{}, justUpdate bool) (*ReportResult, error) {
+	return s.sendReport("", task, command, justUpdate)
+}
+
+func (s *Service) sendReport(endpoint, task string, answer interface{}, justUpdate bool) (*ReportResult, error) {
+	if endpoint == "" {
+		endpoint = s.baseUrl + "/report"
+	}
+	logger.Info("sending report", logging.KeyTask, task, "endpoint", endpoint, "just_update", justUpdate)
+
+	postData := map[string]interface{}{
+		"task":       task,
+		"answer":     answer,
+		"apikey":     s.apiKey,
+		"justUpdate": justUpdate,
+	}
+
+	logging.Dump(logger, "report payload", "payload", redact.Sprint(postData))
+
+	resp, err := http.SendPost(endpoint, postData)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := parseReportResult(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.HasFlag() {
+		logger.Info("flag found", logging.KeyTask, task, "flag", result.Flag)
+	}
+
+	if result.Code < 0 {
+		return result, &APIError{Code: result.Code, Message: result.Message}
+	}
+	return result, nil
+}
+
+// ReportAttempt is a single report submission saved in the history log
+type ReportAttempt struct {
+	Timestamp time.Time       `json:"timestamp"`
+	Task      string          `json:"task"`
+	Answer    json.RawMessage `json:"answer"`
+	Result    *ReportResult   `json:"result"`
+}
+
+func (s *Service) historyFile(task string) string {
+	return filepath.Join(s.historyDir, task+".jsonl")
+}
+
+// recordAttempt adds a sent answer to the history, result is nil when nothing came back
+func (s *Service) recordAttempt(task string, answer interface{}, result *ReportResult) {
+	if result == nil {
+		return
+	}
+	if err := s.appendHistory(task, answer, result); err != nil {
+		logger.Warn("could not save report history", "error", err)
+	}
+}
+
+// appendHistory adds the submission to the task history log
+func (s *Service) appendHistory(task string, answer interface{}, result *ReportResult) error {
+	answerJSON, err := json.Marshal(answer)
+	if err != nil {
+		return fmt.Errorf("error marshaling answer: %w", err)
+	}
+
+	line, err := json.Marshal(ReportAttempt{
+		Timestamp: time.Now(),
+		Task:      task,
+		Answer:    answerJSON,
+		Result:    result,
+	})
+	if err != nil {
+		return fmt.Errorf("error marshaling history entry: %w", err)
+	}
+
+	if err := os.MkdirAll(s.historyDir, 0755); err != nil {
+		return fmt.Errorf("error creating history directory: %w", err)
+	}
+
+	f, err := os.OpenFile(s.historyFile(task), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening history file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(redact.Bytes(line), '\n')); err != nil {
+		return fmt.Errorf("error writing history file: %w", err)
+	}
+	return nil
+}
+
+// History returns all previous submissions for the task, oldest first
+func (s *Service) History(task string) ([]ReportAttempt, error) {
+	f, err := os.Open(s.historyFile(task))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []ReportAttempt{}, nil
+		}
+		return nil, fmt.Errorf("error opening history file: %w", err)
+	}
+	defer f.Close()
+
+	attempts := []ReportAttempt{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var attempt ReportAttempt
+		if err := json.Unmarshal(scanner.Bytes(), &attempt); err != nil {
+			return nil, fmt.Errorf("error parsing history entry: %w", err)
+		}
+		attempts = append(attempts, attempt)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading history file: %w", err)
+	}
+	return attempts, nil
+}
+
+// WasTried returns the last submission of exactly the same answer for the task, if any
+func (s *Service) WasTried(task string, answer interface{}) (*ReportAttempt, bool, error) {
+	answerJSON, err := json.Marshal(answer)
+	if err != nil {
+		return nil, false, fmt.Errorf("error marshaling answer: %w", err)
+	}
+
+	attempts, err := s.History(task)
+	if err != nil {
+		return nil, false, err
+	}
+	for i := len(attempts) - 1; i >= 0; i-- {
+		if string(attempts[i].Answer) == string(answerJSON) {
+			return &attempts[i], true, nil
+		}
+	}
+	return nil, false, nil
+}
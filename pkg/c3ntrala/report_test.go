@@ -0,0 +1,114 @@
+package c3ntrala
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func newTestService(t *testing.T, dryRun bool) (*Service, *[]string) {
+	t.Helper()
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload struct {
+			Answer interface{} `json:"answer"`
+		}
+		json.Unmarshal(body, &payload)
+		answer, _ := json.Marshal(payload.Answer)
+		received = append(received, string(answer))
+		w.Write([]byte(`{"code":0,"message":"IMG_1.PNG"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	return &Service{
+		baseUrl:    server.URL,
+		apiKey:     "test-key",
+		historyDir: filepath.Join(dir, "reports"),
+		outboxDir:  filepath.Join(dir, "outbox"),
+		dryRun:     dryRun,
+	}, &received
+}
+
+func TestConverseSkipsDryRunAndHistory(t *testing.T) {
+	s, received := newTestService(t, true)
+
+	result, err := s.Converse("photos", "START", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DryRun || result.Message != "IMG_1.PNG" {
+		t.Errorf("got result %+v, want the server response", result)
+	}
+	if len(*received) != 1 {
+		t.Fatalf("server got %d requests, want 1", len(*received))
+	}
+
+	attempts, err := s.History("photos")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attempts) != 0 {
+		t.Errorf("got %d history entries, want commands kept out of history", len(attempts))
+	}
+}
+
+func TestPostReportDryRunAndHistory(t *testing.T) {
+	s, received := newTestService(t, true)
+	result, err := s.PostReport("photos", "answer", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.DryRun || len(*received) != 0 {
+		t.Errorf("dry run sent %d requests, result %+v", len(*received), result)
+	}
+
+	s.SetDryRun(false)
+	if _, err := s.PostReport("photos", "answer", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, tried, err := s.WasTried("photos", "answer"); err != nil || !tried {
+		t.Errorf("WasTried = %v, %v, want the sent answer in history", tried, err)
+	}
+	if _, tried, _ := s.WasTried("photos", "START"); tried {
+		t.Error("WasTried(START) = true, want false")
+	}
+}
+
+func TestPostVerifyGoesThroughOutbox(t *testing.T) {
+	s, received := newTestService(t, true)
+	verify := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/verify" {
+			t.Errorf("got request to %s, want /verify", r.URL.Path)
+		}
+		w.Write([]byte(`{"code":0,"message":"OK"}`))
+	}))
+	defer verify.Close()
+
+	result, err := s.PostVerify(verify.URL+"/verify", "POLIGON", []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.DryRun {
+		t.Fatalf("got result %+v, want the answer saved to the outbox", result)
+	}
+
+	entries, err := s.Outbox()
+	if err != nil || len(entries) != 1 || entries[0].Endpoint != verify.URL+"/verify" {
+		t.Fatalf("got outbox %+v, %v, want one entry for the verify endpoint", entries, err)
+	}
+	result, err = s.Submit(entries[0].ID, false)
+	if err != nil || result.Message != "OK" {
+		t.Errorf("Submit = %+v, %v, want the verify response", result, err)
+	}
+	if len(*received) != 0 {
+		t.Errorf("C3ntrala got %d requests, want none", len(*received))
+	}
+	if _, tried, _ := s.WasTried("POLIGON", []string{"a", "b"}); !tried {
+		t.Error("WasTried = false, want the submitted answer in history")
+	}
+}
@@ -0,0 +1,302 @@
+package c3ntrala
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/crowmw/ai_devs3/pkg/http"
+	"github.com/crowmw/ai_devs3/pkg/processor"
+	"github.com/crowmw/ai_devs3/pkg/redact"
+)
+
+// Named resources published by C3ntrala. Names starting with "dane/" are public,
+// all others are served from the API key scoped /data/<apikey>/ directory.
+const (
+	ResourceBarbaraNote   = "dane/barbara.txt"
+	ResourceInterrogation = "dane/przesluchania.zip"
+	ResourceArxivDraft    = "dane/arxiv-draft.html"
+	ResourceRafalNotebook = "dane/notatnik-rafala.pdf"
+	ResourceCensorship    = "cenzura.txt"
+	ResourceCalibration   = "json.txt"
+	ResourceRobotID       = "robotid.json"
+	ResourceArxivQuestion = "arxiv.txt"
+	ResourceFactoryFiles  = "pliki_z_fabryki.zip"
+	ResourceSofto         = "softo.json"
+	ResourceNotes         = "notes.json"
+	ResourcePhoneSorted   = "phone_sorted.json"
+	ResourcePhoneQuestion = "phone_questions.json"
+	ResourceGpsLogs       = "gps.txt"
+	ResourceGpsQuestion   = "gps_question.json"
+)
+
+// DefaultMirrorDir is where fetched resources are stored
+var DefaultMirrorDir = filepath.Join("data", "mirror")
+
+// ResourceVersion is a single stored copy of a resource
+type ResourceVersion struct {
+	Version   int       `json:"version"`
+	File      string    `json:"file"`
+	SHA256    string    `json:"sha256"`
+	Size      int       `json:"size"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// resourceMeta is kept next to the stored versions of a resource
+type resourceMeta struct {
+	Name         string            `json:"name"`
+	ETag         string            `json:"etag,omitempty"`
+	LastModified string            `json:"last_modified,omitempty"`
+	CheckedAt    time.Time         `json:"checked_at"`
+	Versions     []ResourceVersion `json:"versions"`
+}
+
+// Resources fetches named C3ntrala resources into a versioned local mirror.
+// Every fetch is a conditional request; a new version is stored only when the content changes.
+// When C3ntrala is unreachable or fails with a 5xx the latest mirrored version is used,
+// a 4xx (e.g. a wrong API key or a removed resource) is returned as an error.
+// Every server has its own mirror, so a run against the mock never falls back to data of another server.
+type Resources struct {
+	baseUrl   string
+	apiKey    string
+	mirrorDir string
+	// server names the mirror of baseUrl under mirrorDir
+	server string
+}
+
+// NewResources creates a resource fetcher, an empty mirrorDir means DefaultMirrorDir
+func NewResources(baseUrl, apiKey, mirrorDir string) *Resources {
+	if mirrorDir == "" {
+		mirrorDir = DefaultMirrorDir
+	}
+	baseUrl = strings.TrimSuffix(baseUrl, "/")
+	return &Resources{
+		baseUrl:   baseUrl,
+		apiKey:    apiKey,
+		mirrorDir: mirrorDir,
+		server:    serverDir(baseUrl),
+	}
+}
+
+// serverDir names the mirror of a server by its host and a hash of its URL, e.g. "c3ntrala.ag3nts.org-1f2e3d4c"
+func serverDir(baseUrl string) string {
+	host := baseUrl
+	if u, err := url.Parse(baseUrl); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	host = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, host)
+	sum := sha256.Sum256([]byte(strings.ToLower(baseUrl)))
+	return host + "-" + hex.EncodeToString(sum[:4])
+}
+
+// Resources returns the resource fetcher bound to the service's C3ntrala URL and API key
+func (s *Service) Resources() *Resources {
+	return s.resources
+}
+
+// URL returns the remote URL of a named resource
+func (r *Resources) URL(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	if strings.HasPrefix(name, "dane/") {
+		return r.baseUrl + "/" + name
+	}
+	return r.baseUrl + "/data/" + r.apiKey + "/" + name
+}
+
+// dir returns the mirror directory of a named resource
+func (r *Resources) dir(name string) string {
+	return filepath.Join(r.mirrorDir, r.server, filepath.FromSlash(path.Clean("/"+name)))
+}
+
+func (r *Resources) loadMeta(name string) (*resourceMeta, error) {
+	data, err := os.ReadFile(filepath.Join(r.dir(name), "meta.json"))
+	if os.IsNotExist(err) {
+		return &resourceMeta{Name: name}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading mirror metadata: %w", err)
+	}
+
+	var meta resourceMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("error parsing mirror metadata: %w", err)
+	}
+	return &meta, nil
+}
+
+func (r *Resources) saveMeta(meta *resourceMeta) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling mirror metadata: %w", err)
+	}
+	return os.WriteFile(filepath.Join(r.dir(meta.Name), "meta.json"), data, 0644)
+}
+
+func (meta *resourceMeta) latest() *ResourceVersion {
+	if len(meta.Versions) == 0 {
+		return nil
+	}
+	return &meta.Versions[len(meta.Versions)-1]
+}
+
+// sync makes sure the mirror holds the current version of the resource and returns it
+func (r *Resources) sync(name string) (*ResourceVersion, error) {
+	meta, err := r.loadMeta(name)
+	if err != nil {
+		return nil, err
+	}
+	latest := meta.latest()
+
+	etag, lastModified := meta.ETag, meta.LastModified
+	if latest == nil {
+		etag, lastModified = "", ""
+	}
+
+	resp, err := http.FetchConditional(r.URL(name), etag, lastModified)
+	if err != nil {
+		status := 0
+		var statusErr *http.StatusError
+		if errors.As(err, &statusErr) {
+			status = statusErr.StatusCode
+		}
+		if latest != nil && (status < 400 || status >= 500) {
+			logger.Warn("could not refresh resource, using mirrored copy", "resource", name, "version", latest.Version, "status", status, "error", err)
+			return latest, nil
+		}
+		return nil, redact.Error(fmt.Errorf("error fetching resource %s: %w", name, err))
+	}
+
+	meta.CheckedAt = time.Now()
+	if resp.ETag != "" {
+		meta.ETag = resp.ETag
+	}
+	if resp.LastModified != "" {
+		meta.LastModified = resp.LastModified
+	}
+
+	if err := os.MkdirAll(r.dir(name), 0755); err != nil {
+		return nil, fmt.Errorf("error creating mirror directory: %w", err)
+	}
+
+	sum := sha256.Sum256(resp.Body)
+	hash := hex.EncodeToString(sum[:])
+	if resp.NotModified || (latest != nil && latest.SHA256 == hash) {
+		if err := r.saveMeta(meta); err != nil {
+			return nil, err
+		}
+		return latest, nil
+	}
+
+	version := ResourceVersion{
+		Version:   len(meta.Versions) + 1,
+		SHA256:    hash,
+		Size:      len(resp.Body),
+		FetchedAt: meta.CheckedAt,
+	}
+	version.File = fmt.Sprintf("v%d%s", version.Version, path.Ext(name))
+	if err := os.WriteFile(filepath.Join(r.dir(name), version.File), resp.Body, 0644); err != nil {
+		return nil, fmt.Errorf("error writing resource %s: %w", name, err)
+	}
+
+	meta.Versions = append(meta.Versions, version)
+	if err := r.saveMeta(meta); err != nil {
+		return nil, err
+	}
+	logger.Info("resource mirrored", "resource", name, "version", version.Version)
+	return &meta.Versions[len(meta.Versions)-1], nil
+}
+
+// Path returns the local path of the current version of a resource
+func (r *Resources) Path(name string) (string, error) {
+	version, err := r.sync(name)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(r.dir(name), version.File), nil
+}
+
+// Versions lists mirrored versions of a resource, oldest first
+func (r *Resources) Versions(name string) ([]ResourceVersion, error) {
+	meta, err := r.loadMeta(name)
+	if err != nil {
+		return nil, err
+	}
+	return meta.Versions, nil
+}
+
+// Fetch returns the content of the current version of a resource
+func (r *Resources) Fetch(name string) ([]byte, error) {
+	filePath, err := r.Path(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(filePath)
+}
+
+// LoadText returns the resource as a string
+func (r *Resources) LoadText(name string) (string, error) {
+	data, err := r.Fetch(name)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// LoadLines returns non-empty lines of a text resource
+func (r *Resources) LoadLines(name string) ([]string, error) {
+	data, err := r.Fetch(name)
+	if err != nil {
+		return nil, err
+	}
+	return processor.ReadLinesFromTextFile(data), nil
+}
+
+// LoadJSON decodes a JSON resource into v
+func (r *Resources) LoadJSON(name string, v any) error {
+	data, err := r.Fetch(name)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("error decoding resource %s: %w", name, err)
+	}
+	return nil
+}
+
+// ExtractZip extracts a zip resource and returns the target directory.
+// An empty dir extracts next to the mirrored version, so every version gets its own directory.
+func (r *Resources) ExtractZip(name, dir string) (string, error) {
+	filePath, err := r.Path(name)
+	if err != nil {
+		return "", err
+	}
+	if dir == "" {
+		dir = strings.TrimSuffix(filePath, filepath.Ext(filePath))
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("error reading resource %s: %w", name, err)
+	}
+	if err := processor.ExtractZipToDirectory(data, dir); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
@@ -0,0 +1,84 @@
+package c3ntrala
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	httpclient "github.com/crowmw/ai_devs3/pkg/http"
+)
+
+func TestSyncFallback(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		unreachable  bool
+		wantFallback bool
+	}{
+		{"server error uses mirror", http.StatusInternalServerError, false, true},
+		{"unreachable uses mirror", 0, true, true},
+		{"forbidden fails", http.StatusForbidden, false, false},
+		{"not found fails", http.StatusNotFound, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := http.StatusOK
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if status != http.StatusOK {
+					http.Error(w, "failed", status)
+					return
+				}
+				w.Write([]byte("first version"))
+			}))
+			defer server.Close()
+
+			r := NewResources(server.URL, "test-key", t.TempDir())
+			if _, err := r.LoadText(ResourceCensorship); err != nil {
+				t.Fatal(err)
+			}
+
+			status = tt.status
+			if tt.unreachable {
+				server.Close()
+			}
+			text, err := r.LoadText(ResourceCensorship)
+			if tt.wantFallback {
+				if err != nil || text != "first version" {
+					t.Errorf("got %q, %v, want the mirrored copy", text, err)
+				}
+				return
+			}
+			var statusErr *httpclient.StatusError
+			if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.status {
+				t.Errorf("got %q, %v, want status %d error", text, err, tt.status)
+			}
+		})
+	}
+}
+
+func TestMirrorPerServer(t *testing.T) {
+	mirror := t.TempDir()
+	mock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("mock fixture"))
+	}))
+	defer mock.Close()
+	if _, err := NewResources(mock.URL, "mock-api-key", mirror).LoadText(ResourceCensorship); err != nil {
+		t.Fatal(err)
+	}
+
+	// the real server is down, its mirror is empty and the mock's copy must not stand in for it
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "failed", http.StatusInternalServerError)
+	}))
+	defer down.Close()
+	text, err := NewResources(down.URL, "real-key", mirror).LoadText(ResourceCensorship)
+	if err == nil || text == "mock fixture" {
+		t.Errorf("got %q, %v, want an error instead of the mock's copy", text, err)
+	}
+
+	if a, b := serverDir("https://c3ntrala.ag3nts.org"), serverDir("http://localhost:8080"); a == b || !strings.HasPrefix(b, "localhost_8080-") {
+		t.Errorf("got mirrors %s and %s", a, b)
+	}
+}
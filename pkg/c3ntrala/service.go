@@ -1,145 +1,77 @@
 package c3ntrala
 
 import (
-	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"strings"
 
 	"github.com/crowmw/ai_devs3/pkg/env"
-	"github.com/crowmw/ai_devs3/pkg/http"
-	"github.com/crowmw/ai_devs3/pkg/processor"
+	"github.com/crowmw/ai_devs3/pkg/logging"
 )
 
+var logger = logging.New("c3ntrala")
+
 type Service struct {
-	envSvc  *env.Service
-	baseUrl string
-	apiKey  string
+	envSvc     *env.Service
+	baseUrl    string
+	apiKey     string
+	historyDir string
+	outboxDir  string
+	resources  *Resources
+	dryRun     bool
 }
 
 func NewService(envSvc *env.Service) (*Service, error) {
+	if err := envSvc.Require(env.KeyC3ntralaURL, env.KeyMyAPIKey); err != nil {
+		return nil, err
+	}
 	return &Service{
-		envSvc:  envSvc,
-		baseUrl: envSvc.GetC3ntralaURL(),
-		apiKey:  envSvc.GetMyAPIKey(),
+		envSvc:     envSvc,
+		baseUrl:    envSvc.GetC3ntralaURL(),
+		apiKey:     envSvc.GetMyAPIKey(),
+		historyDir: filepath.Join("data", "reports"),
+		outboxDir:  filepath.Join("data", "outbox"),
+		resources:  NewResources(envSvc.GetC3ntralaURL(), envSvc.GetMyAPIKey(), ""),
 	}, nil
 }
 
-func (s *Service) PostReport(task string, answer interface{}, justUpdate bool) (string, error) {
-	fmt.Println("Sending report to C3ntrala...")
-
-	postData := map[string]interface{}{
-		"task":       task,
-		"answer":     answer,
-		"apikey":     s.apiKey,
-		"justUpdate": justUpdate,
-	}
-
-	fmt.Println(postData)
-
-	resp, err := http.SendPost(
-		s.baseUrl+"/report",
-		postData,
-	)
+// SetDataDir moves report history, the outbox and the resource mirror under dir
+func (s *Service) SetDataDir(dir string) {
+	s.historyDir = filepath.Join(dir, "reports")
+	s.outboxDir = filepath.Join(dir, "outbox")
+	s.resources = NewResources(s.baseUrl, s.apiKey, filepath.Join(dir, "mirror"))
+}
 
-	return resp, err
+// SetDryRun makes PostReport save reports to the outbox instead of sending them
+func (s *Service) SetDryRun(dryRun bool) {
+	s.dryRun = dryRun
 }
 
 func (s *Service) GetBarbaraNote() (string, error) {
-	url := s.baseUrl + "/dane/barbara.txt"
-	body, err := http.FetchData(url)
-	if err != nil {
-		return "", err
-	}
-	return string(body), nil
+	return s.resources.LoadText(ResourceBarbaraNote)
 }
 
+// GetPlacesWhereSeen returns normalized names of places where the person was seen
 func (s *Service) GetPlacesWhereSeen(firstName string) ([]string, error) {
-	url := s.baseUrl + "/people"
-
-	body, err := http.SendPost(url, map[string]interface{}{
-		"apikey": s.apiKey,
-		"query":  firstName,
-	})
+	result, err := s.QueryPeople(firstName)
 	if err != nil {
 		return nil, err
 	}
-
-	// Remove [**RESTRICTED DATA**] before unmarshaling
-	cleanBody := strings.ReplaceAll(body, "[**RESTRICTED DATA**]", "")
-
-	var response struct {
-		Code    int    `json:"code"`
-		Message string `json:"message"`
-	}
-	if err := json.Unmarshal([]byte(cleanBody), &response); err != nil {
-		return nil, fmt.Errorf("error parsing response: %w", err)
-	}
-
-	return strings.Split(response.Message, " "), nil
+	return result.Names(), nil
 }
 
+// GetWhoWasSeenThere returns normalized names of people who were seen in the city
 func (s *Service) GetWhoWasSeenThere(city string) ([]string, error) {
-	url := s.baseUrl + "/places"
-
-	body, err := http.SendPost(url, map[string]interface{}{
-		"apikey": s.apiKey,
-		"query":  city,
-	})
+	result, err := s.QueryPlaces(city)
 	if err != nil {
 		return nil, err
 	}
-
-	// Remove [**RESTRICTED DATA**] before unmarshaling
-	cleanBody := strings.ReplaceAll(body, "[**RESTRICTED DATA**]", "")
-
-	var response struct {
-		Code    int    `json:"code"`
-		Message string `json:"message"`
-	}
-	if err := json.Unmarshal([]byte(cleanBody), &response); err != nil {
-		return nil, fmt.Errorf("error parsing response: %w", err)
-	}
-
-	// Convert Polish characters to ASCII
-	message := strings.NewReplacer(
-		"Ą", "A",
-		"Ć", "C",
-		"Ę", "E",
-		"Ł", "L",
-		"Ń", "N",
-		"Ó", "O",
-		"Ś", "S",
-		"Ź", "Z",
-		"Ż", "Z",
-		"ą", "a",
-		"ć", "c",
-		"ę", "e",
-		"ł", "l",
-		"ń", "n",
-		"ó", "o",
-		"ś", "s",
-		"ź", "z",
-		"ż", "z",
-	).Replace(response.Message)
-
-	return strings.Split(message, " "), nil
+	return result.Names(), nil
 }
 
 func (s *Service) GetPhotos() ([]string, error) {
-	url := s.baseUrl + "/report"
-
-	body, err := http.SendPost(url, map[string]interface{}{
-		"task":   "photos",
-		"apikey": s.apiKey,
-		"answer": "START",
-	})
-
-	var resp struct {
-		Code    int    `json:"code"`
-		Message string `json:"message"`
-	}
-	err = json.Unmarshal([]byte(body), &resp)
+	resp, err := s.Converse("photos", "START", false)
 	if err != nil {
 		return nil, err
 	}
@@ -149,79 +81,49 @@ func (s *Service) GetPhotos() ([]string, error) {
 	matches := re.FindAllString(resp.Message, -1)
 
 	// Create full URLs
-	baseURL := s.baseUrl + "/dane/barbara/"
 	urls := make([]string, len(matches))
 	for i, filename := range matches {
-		urls[i] = baseURL + filename
+		urls[i] = s.resources.URL("dane/barbara/" + filename)
 	}
 
 	return urls, nil
 }
 
 func (s *Service) FixPhoto(answer string) (string, error) {
-	url := s.baseUrl + "/report"
-
-	body, err := http.SendPost(url, map[string]interface{}{
-		"task":   "photos",
-		"apikey": s.apiKey,
-		"answer": answer,
-	})
-
-	var resp struct {
-		Code    int    `json:"code"`
-		Message string `json:"message"`
-	}
-	err = json.Unmarshal([]byte(body), &resp)
+	resp, err := s.Converse("photos", answer, false)
 	if err != nil {
 		return "", err
 	}
-	fmt.Println("❇️ Response:", resp.Message)
+	logger.Debug("fix photo response", "message", resp.Message)
 	// Extract PNG filename using regex
 	re := regexp.MustCompile(`IMG_\d+_[A-Z0-9]+\.PNG`)
 	matches := re.FindAllString(resp.Message, -1)
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no fixed photo in response: %s", resp.Message)
+	}
 
 	// Create full URL
-	baseURL := s.baseUrl + "/dane/barbara/"
 	urls := make([]string, len(matches))
 	for i, filename := range matches {
-		urls[i] = baseURL + filename
+		urls[i] = s.resources.URL("dane/barbara/" + filename)
 	}
 
-	fmt.Println("❇️ FIXED URL:", urls[0])
+	logger.Info("photo fixed", "url", urls[0])
 
 	return urls[0], nil
 }
 
-func (s *Service) GetQuestions(path string) (map[string]string, error) {
-	body, err := http.FetchData(s.baseUrl + "/data/" + s.apiKey + path)
-	if err != nil {
-		fmt.Println(err)
-		return nil, err
-	}
-
+// GetQuestions loads a question map resource, e.g. "phone_questions.json"
+func (s *Service) GetQuestions(name string) (map[string]string, error) {
 	var questions map[string]string
-	err = json.Unmarshal([]byte(body), &questions)
-	if err != nil {
-		fmt.Println(err)
+	if err := s.resources.LoadJSON(name, &questions); err != nil {
 		return nil, err
 	}
 	return questions, nil
 }
 
 func (s *Service) GetSoftoQuestions() (map[string]string, error) {
-	body, err := http.FetchData(s.baseUrl + "/data/" + s.apiKey + "/softo.json")
-	if err != nil {
-		fmt.Println(err)
-		return nil, err
-	}
-
-	var questions map[string]string
-	err = json.Unmarshal([]byte(body), &questions)
-	if err != nil {
-		fmt.Println(err)
-		return nil, err
-	}
-	return questions, nil
+	return s.GetQuestions(ResourceSofto)
 }
 
 type PhoneData struct {
@@ -233,43 +135,24 @@ type PhoneData struct {
 }
 
 func (s *Service) GetPhoneData() (PhoneData, error) {
-	body, err := http.FetchData(s.baseUrl + "/data/" + s.apiKey + "/phone_sorted.json")
-	if err != nil {
-		fmt.Println(err)
-		return PhoneData{}, err
-	}
-
 	var phoneData PhoneData
-	err = json.Unmarshal([]byte(body), &phoneData)
-	if err != nil {
-		fmt.Println(err)
+	if err := s.resources.LoadJSON(ResourcePhoneSorted, &phoneData); err != nil {
 		return PhoneData{}, err
 	}
 	return phoneData, nil
 }
 
 func (s *Service) GetLogs() (string, error) {
-	body, err := http.FetchData(s.baseUrl + "/data/" + s.apiKey + "/gps.txt")
+	lines, err := s.resources.LoadLines(ResourceGpsLogs)
 	if err != nil {
-		fmt.Println(err)
 		return "", err
 	}
-
-	logs := processor.ReadLinesFromTextFileAsString(body)
-	return logs, nil
+	return strings.Join(lines, "\n"), nil
 }
 
 func (s *Service) GetGpsQuestion() (string, error) {
-	body, err := http.FetchData(s.baseUrl + "/data/" + s.apiKey + "/gps_question.json")
-	if err != nil {
-		fmt.Println(err)
-		return "", err
-	}
-
 	var question map[string]string
-	err = json.Unmarshal([]byte(body), &question)
-	if err != nil {
-		fmt.Println(err)
+	if err := s.resources.LoadJSON(ResourceGpsQuestion, &question); err != nil {
 		return "", err
 	}
 	return question["question"], nil
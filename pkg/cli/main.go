@@ -0,0 +1,408 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/crowmw/ai_devs3/pkg/agent"
+	"github.com/crowmw/ai_devs3/pkg/env"
+	"github.com/crowmw/ai_devs3/pkg/eval"
+	"github.com/crowmw/ai_devs3/pkg/logging"
+	"github.com/crowmw/ai_devs3/pkg/trace"
+)
+
+const usage = `Usage: aidevs [flags] <command> [args]
+
+Commands:
+  run <task>                   run a registered task, e.g. aidevs run s03e05
+  list                         list registered tasks
+  report <task> <answer-file>  send an answer to C3ntrala, JSON files are sent as JSON
+  outbox                       list reports saved by --dry-run
+  submit <id>                  send a reviewed outbox entry, add --force to send it again
+  runs [run-id]                list agent runs, or the checkpoints of one run
+  eval [agent]                 run the eval scenarios of an agent --runs times, or list eval suites
+
+Flags:
+`
+
+// Main parses os.Args and runs the selected command, exiting with a non-zero code on error
+func Main() {
+	if err := Execute(os.Args[1:], os.Stdout); err != nil {
+		slog.Error("command failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// Execute runs a command with the given arguments. Flags are accepted before and after the command.
+func Execute(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("aidevs", flag.ContinueOnError)
+	var options Options
+	fs.BoolVar(&options.DryRun, "dry-run", false, "validate reports and save them to the outbox instead of sending them")
+	fs.StringVar(&options.Model, "model", "", "force every chat completion to use this model")
+	fs.StringVar(&options.CacheDir, "cache-dir", "data", "directory for mirrored resources and report history")
+	fs.BoolVar(&options.Verbose, "verbose", false, "print debug output, same as --log-level debug")
+	fs.StringVar(&options.LogLevel, "log-level", "info", "minimum log level: debug, info, warn or error")
+	fs.StringVar(&options.LogFormat, "log-format", logging.FormatPretty, "log output format: pretty or json")
+	fs.BoolVar(&options.DumpPrompts, "dump-prompts", false, "log whole prompts, model responses and payloads")
+	fs.BoolVar(&options.Trace, "trace", false, "record LLM, tool and HTTP calls of a run to <cache-dir>/traces")
+	fs.StringVar(&options.OTLPEndpoint, "otlp-endpoint", os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"), "also export run traces to an OTLP/HTTP collector, e.g. http://localhost:4318")
+	fs.StringVar(&options.Resume, "resume", "", "continue an agent run, <run-id> from its latest checkpoint or <run-id>@<step> as a fork")
+	fs.BoolVar(&options.Interactive, "interactive", false, "ask in the terminal to approve, edit or reject agent tool calls with the confirm policy")
+	fs.StringVar(&options.ToolPolicy, "tool-policy", "", "override agent tool policies, e.g. data_memory=deny,gps=confirm (auto, confirm or deny)")
+	fs.IntVar(&options.Limits.MaxSteps, "max-steps", 0, "limit the steps of agent runs, 0 keeps the agent's default")
+	fs.DurationVar(&options.Limits.Timeout, "timeout", 0, "limit the wall-clock time of agent runs, e.g. 2m")
+	fs.IntVar(&options.Limits.MaxTokens, "max-tokens", 0, "limit the tokens of all LLM calls of an agent run")
+	fs.Float64Var(&options.Limits.MaxCost, "max-cost", 0, "limit the estimated cost of all LLM calls of an agent run in USD")
+	force := fs.Bool("force", false, "submit an outbox entry even if it was already sent")
+	evalRuns := fs.Int("runs", 3, "how many times eval runs each scenario")
+	envFlags := env.RegisterFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := setupLogging(options); err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		fs.Usage()
+		return fmt.Errorf("missing command")
+	}
+
+	switch command := positional[0]; command {
+	case "list":
+		return listTasks(out)
+	case "run":
+		if len(positional) < 2 {
+			return fmt.Errorf("usage: aidevs run <task>")
+		}
+		task, ok := GetTask(positional[1])
+		if !ok {
+			return fmt.Errorf("unknown task %q, see aidevs list", positional[1])
+		}
+		app, err := newContext(options, envFlags, task.Name, task.Required, positional[2:])
+		if err != nil {
+			return err
+		}
+		app.Log.Debug("running task", "options", fmt.Sprintf("%+v", options))
+		return runTask(app, task)
+	case "report":
+		if len(positional) != 3 {
+			return fmt.Errorf("usage: aidevs report <task> <answer-file>")
+		}
+		app, err := newContext(options, envFlags, "", nil, nil)
+		if err != nil {
+			return err
+		}
+		return sendReport(app, positional[1], positional[2], out)
+	case "outbox":
+		app, err := newContext(options, envFlags, "", nil, nil)
+		if err != nil {
+			return err
+		}
+		return listOutbox(app, out)
+	case "submit":
+		if len(positional) != 2 {
+			return fmt.Errorf("usage: aidevs submit <id>")
+		}
+		app, err := newContext(options, envFlags, "", nil, nil)
+		if err != nil {
+			return err
+		}
+		return submitOutbox(app, positional[1], *force, out)
+	case "runs":
+		if len(positional) > 2 {
+			return fmt.Errorf("usage: aidevs runs [run-id]")
+		}
+		app, err := newContext(options, envFlags, "", nil, nil)
+		if err != nil {
+			return err
+		}
+		if len(positional) == 2 {
+			return listCheckpoints(app, positional[1], out)
+		}
+		return listRuns(app, out)
+	case "eval":
+		if len(positional) > 2 {
+			return fmt.Errorf("usage: aidevs eval [agent]")
+		}
+		if len(positional) == 1 {
+			return listSuites(out)
+		}
+		suite, ok := eval.GetSuite(positional[1])
+		if !ok {
+			return fmt.Errorf("unknown eval suite %q, see aidevs eval", positional[1])
+		}
+		app, err := newContext(options, envFlags, "", nil, nil)
+		if err != nil {
+			return err
+		}
+		return runEval(app, suite, *evalRuns, out)
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+// parseInterspersed parses flags that may appear between positional arguments
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func setupLogging(options Options) error {
+	level := options.LogLevel
+	if options.Verbose {
+		level = "debug"
+	}
+	return logging.Setup(logging.Options{
+		Level:       level,
+		Format:      options.LogFormat,
+		DumpPrompts: options.DumpPrompts,
+	})
+}
+
+func newContext(options Options, envFlags *env.Flags, taskName string, required []string, args []string) (*Context, error) {
+	envSvc, err := env.NewService(env.WithFlags(envFlags), env.WithRequired(required...))
+	if err != nil {
+		return nil, err
+	}
+	logger := logging.New("cli")
+	if taskName != "" {
+		logger = logger.With(logging.KeyTask, taskName)
+	}
+	return &Context{Env: envSvc, Options: options, Args: args, Log: logger}, nil
+}
+
+// runTask runs a task, recording a trace when --trace or --otlp-endpoint is set
+func runTask(app *Context, task Task) (err error) {
+	traceOptions := trace.Options{OTLPEndpoint: app.Options.OTLPEndpoint, Service: "aidevs"}
+	if app.Options.Trace {
+		name := fmt.Sprintf("%s-%s.jsonl", task.Name, time.Now().Format("20060102-150405"))
+		traceOptions.File = filepath.Join(app.Options.CacheDir, "traces", name)
+	}
+	tracer, shutdown, err := trace.Setup(traceOptions)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := shutdown(); err != nil {
+			app.Log.Warn("could not flush trace", "error", err)
+		}
+		if tracer != nil {
+			app.Log.Info("trace recorded", "trace_id", tracer.TraceID(), "file", traceOptions.File)
+		}
+	}()
+
+	_, span := trace.Start(context.Background(), "run "+task.Name, trace.KindRun, logging.KeyTask, task.Name, "model", app.Options.Model, "dry_run", app.Options.DryRun)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+
+	return task.Run(app)
+}
+
+func listTasks(out io.Writer) error {
+	for _, task := range Tasks() {
+		fmt.Fprintf(out, "%-10s %s\n", task.Name, task.Description)
+	}
+	return nil
+}
+
+// readAnswer loads an answer file, decoding it when it holds JSON
+func readAnswer(path string) (any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading answer file: %w", err)
+	}
+
+	var answer any
+	if err := json.Unmarshal(data, &answer); err == nil {
+		return answer, nil
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func sendReport(app *Context, task, answerFile string, out io.Writer) error {
+	answer, err := readAnswer(answerFile)
+	if err != nil {
+		return err
+	}
+
+	c3ntralaSvc, err := app.C3ntrala()
+	if err != nil {
+		return err
+	}
+
+	result, err := c3ntralaSvc.PostReport(task, answer, false)
+	if result != nil {
+		fmt.Fprintln(out, result)
+	}
+	return err
+}
+
+func listOutbox(app *Context, out io.Writer) error {
+	c3ntralaSvc, err := app.C3ntrala()
+	if err != nil {
+		return err
+	}
+
+	entries, err := c3ntralaSvc.Outbox()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(out, "Outbox is empty")
+		return nil
+	}
+	for _, entry := range entries {
+		status := "pending"
+		if entry.Submitted() {
+			status = "sent"
+			if entry.Result != nil {
+				status += fmt.Sprintf(" (code %d)", entry.Result.Code)
+			}
+		}
+		fmt.Fprintf(out, "%-32s %-10s %s\n", entry.ID, entry.Task, status)
+		if entry.Endpoint != "" {
+			fmt.Fprintln(out, "  sent to", entry.Endpoint)
+		}
+		for _, warning := range entry.Warnings {
+			fmt.Fprintln(out, "  ⚠️", warning)
+		}
+	}
+	return nil
+}
+
+func submitOutbox(app *Context, id string, force bool, out io.Writer) error {
+	c3ntralaSvc, err := app.C3ntrala()
+	if err != nil {
+		return err
+	}
+
+	result, err := c3ntralaSvc.Submit(id, force)
+	if result != nil {
+		fmt.Fprintln(out, result)
+	}
+	return err
+}
+
+func listRuns(app *Context, out io.Writer) error {
+	runs, err := app.AgentStore().Runs()
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		fmt.Fprintln(out, "No agent runs")
+		return nil
+	}
+	for _, run := range runs {
+		fmt.Fprintf(out, "%-32s %-10s %-9s %3d steps  %s  %s\n", run.RunID, run.Agent, run.Status, run.Steps, run.UpdatedAt.Format("2006-01-02 15:04:05"), truncate(run.UserMessage, 60))
+		if run.ForkedFrom != "" {
+			fmt.Fprintln(out, "  forked from", run.ForkedFrom)
+		}
+		if run.Error != "" {
+			fmt.Fprintln(out, "  ⚠️", run.Error)
+		}
+	}
+	return nil
+}
+
+func listCheckpoints(app *Context, runID string, out io.Writer) error {
+	checkpoints, err := app.AgentStore().Checkpoints(runID)
+	if err != nil {
+		return err
+	}
+	if len(checkpoints) == 0 {
+		return fmt.Errorf("no checkpoints for run %q", runID)
+	}
+	for _, checkpoint := range checkpoints {
+		completed := 0
+		for _, task := range checkpoint.State.Tasks {
+			if task.Status == agent.StatusCompleted {
+				completed++
+			}
+		}
+		fmt.Fprintf(out, "step %-3d %-9s %s  tasks %d/%d", checkpoint.Step, checkpoint.Status, checkpoint.CreatedAt.Format("15:04:05"), completed, len(checkpoint.State.Tasks))
+		if task := checkpoint.State.CurrentTask(); task != nil {
+			fmt.Fprintf(out, "  current %s", task.Name)
+		}
+		fmt.Fprintln(out)
+	}
+	fmt.Fprintf(out, "continue with --resume %s, fork with --resume %s@<step>\n", runID, runID)
+	return nil
+}
+
+func listSuites(out io.Writer) error {
+	suites := eval.Suites()
+	if len(suites) == 0 {
+		fmt.Fprintln(out, "No eval suites")
+		return nil
+	}
+	for _, suite := range suites {
+		fmt.Fprintf(out, "%-10s %-28s %s\n", suite.Agent, suite.Dir, suite.Description)
+	}
+	return nil
+}
+
+// runEval runs a suite, saves the report to <cache-dir>/evals and compares it with earlier prompt versions
+func runEval(app *Context, suite eval.Suite, runs int, out io.Writer) error {
+	aiSvc, err := app.AI()
+	if err != nil {
+		return err
+	}
+	report, err := eval.Run(aiSvc, suite, runs)
+	if err != nil {
+		return err
+	}
+	if app.Options.Model != "" {
+		report.Model = app.Options.Model
+	}
+
+	dir := filepath.Join(app.Options.CacheDir, "evals")
+	file, err := report.Save(dir)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(out, report.Format())
+	fmt.Fprintln(out, "saved to", file)
+
+	reports, err := eval.LoadReports(dir, suite.Agent)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out)
+	fmt.Fprint(out, eval.FormatComparison(eval.Compare(reports)))
+	return nil
+}
+
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len([]rune(s)) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "…"
+}
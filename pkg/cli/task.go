@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/crowmw/ai_devs3/pkg/agent"
+	"github.com/crowmw/ai_devs3/pkg/ai"
+	"github.com/crowmw/ai_devs3/pkg/c3ntrala"
+	"github.com/crowmw/ai_devs3/pkg/env"
+)
+
+// Task is a single runnable episode
+type Task struct {
+	// Name is used on the command line, e.g. "s03e05"
+	Name        string
+	Description string
+	// Required lists configuration keys the task reads directly, services check their own keys
+	Required []string
+	Run      func(app *Context) error
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]Task)
+)
+
+// Register adds a task to the CLI, usually from an init function of the episode package
+func Register(task Task) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if task.Name == "" || task.Run == nil {
+		panic("cli: task needs a name and a run function")
+	}
+	if _, exists := registry[task.Name]; exists {
+		panic(fmt.Sprintf("cli: task %s registered twice", task.Name))
+	}
+	registry[task.Name] = task
+}
+
+// Tasks returns all registered tasks sorted by name
+func Tasks() []Task {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	tasks := make([]Task, 0, len(registry))
+	for _, task := range registry {
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Name < tasks[j].Name })
+	return tasks
+}
+
+// GetTask returns a registered task by name
+func GetTask(name string) (Task, bool) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	task, ok := registry[name]
+	return task, ok
+}
+
+// Options are flags shared by every command
+type Options struct {
+	DryRun      bool
+	Model       string
+	CacheDir    string
+	Verbose     bool
+	LogLevel    string
+	LogFormat   string
+	DumpPrompts bool
+	// Trace writes spans of a run to <CacheDir>/traces
+	Trace        bool
+	OTLPEndpoint string
+	// Resume is "<run id>" or "<run id>@<step>" of an agent run to continue instead of starting a new one
+	Resume string
+	// Interactive asks in the terminal before agents call tools with the confirm policy
+	Interactive bool
+	// ToolPolicy overrides tool policies of agents, "tool=policy" pairs separated by commas
+	ToolPolicy string
+	// Limits override the non-zero step, time, token and cost limits of agent runs
+	Limits agent.Limits
+}
+
+// Context gives a task its configuration, shared options and services
+type Context struct {
+	Env     *env.Service
+	Options Options
+	// Args are command line arguments left after the task name
+	Args []string
+	// Log is tagged with the task name
+	Log *slog.Logger
+
+	aiSvc       *ai.Service
+	c3ntralaSvc *c3ntrala.Service
+}
+
+// AI returns the shared AI service with the --model override applied
+func (c *Context) AI() (*ai.Service, error) {
+	if c.aiSvc != nil {
+		return c.aiSvc, nil
+	}
+	aiSvc, err := ai.NewService(c.Env)
+	if err != nil {
+		return nil, err
+	}
+	aiSvc.SetModel(c.Options.Model)
+	c.aiSvc = aiSvc
+	return aiSvc, nil
+}
+
+// C3ntrala returns the shared C3ntrala service with --dry-run and --cache-dir applied
+func (c *Context) C3ntrala() (*c3ntrala.Service, error) {
+	if c.c3ntralaSvc != nil {
+		return c.c3ntralaSvc, nil
+	}
+	c3ntralaSvc, err := c3ntrala.NewService(c.Env)
+	if err != nil {
+		return nil, err
+	}
+	c3ntralaSvc.SetDataDir(c.Options.CacheDir)
+	c3ntralaSvc.SetDryRun(c.Options.DryRun)
+	c.c3ntralaSvc = c3ntralaSvc
+	return c3ntralaSvc, nil
+}
+
+// AgentStore returns the checkpoint store of agent runs under --cache-dir
+func (c *Context) AgentStore() *agent.FileStore {
+	return agent.NewFileStore(filepath.Join(c.Options.CacheDir, "agents"))
+}
+
+// AgentOversight returns the --tool-policy overrides and, with --interactive, a terminal approver for agents
+func (c *Context) AgentOversight() (agent.Oversight, error) {
+	policies, err := agent.ParsePolicies(c.Options.ToolPolicy)
+	if err != nil {
+		return agent.Oversight{}, err
+	}
+	oversight := agent.Oversight{Policies: policies}
+	if c.Options.Interactive {
+		// prompts go to stderr with the logs, stdout stays for results
+		oversight.Approver = agent.NewTerminalApprover(os.Stdin, os.Stderr)
+	}
+	return oversight, nil
+}
+
+// Resources returns the C3ntrala resource mirror under --cache-dir
+func (c *Context) Resources() (*c3ntrala.Resources, error) {
+	c3ntralaSvc, err := c.C3ntrala()
+	if err != nil {
+		return nil, err
+	}
+	return c3ntralaSvc.Resources(), nil
+}
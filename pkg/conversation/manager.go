@@ -0,0 +1,213 @@
+package conversation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/crowmw/ai_devs3/pkg/ai"
+	"github.com/crowmw/ai_devs3/pkg/logging"
+	"github.com/crowmw/ai_devs3/pkg/processor"
+	"github.com/sashabaranov/go-openai"
+)
+
+var logger = logging.New("conversation")
+
+// SummaryName marks the system message holding the summary of dropped turns
+const SummaryName = "conversation_summary"
+
+const summaryPrefix = "Summary of the earlier conversation: "
+
+// Summarizer condenses dropped turns into a summary, extending the previous one when there is one
+type Summarizer func(previous string, messages []openai.ChatCompletionMessage) (string, error)
+
+type Options struct {
+	// Model the conversation is sent to, picks the tokenizer
+	Model string
+	// MaxTokens is the budget of the whole conversation
+	MaxTokens int
+	// KeepLast is how many of the most recent turns are always kept, default 4
+	KeepLast int
+	// Summarize condenses older turns, without it they are dropped
+	Summarize Summarizer
+	// SummaryTokens is kept free for the summary when older turns are summarized, default MaxTokens/4
+	SummaryTokens int
+}
+
+// Truncation reports how a conversation was shortened to fit its budget
+type Truncation struct {
+	Dropped      int
+	Summarized   bool
+	TokensBefore int
+	TokensAfter  int
+	// OverBudget is set when only pinned and recent messages are left and they still don't fit
+	OverBudget bool
+}
+
+// Manager keeps a conversation within a token budget.
+// System and tool messages are pinned, the oldest of the other turns are summarized or dropped.
+type Manager struct {
+	opts      Options
+	tokenizer *processor.Tokenizer
+}
+
+func New(opts Options) (*Manager, error) {
+	if opts.MaxTokens <= 0 {
+		return nil, errors.New("conversation needs a token budget")
+	}
+	if opts.KeepLast <= 0 {
+		opts.KeepLast = 4
+	}
+	if opts.SummaryTokens <= 0 {
+		opts.SummaryTokens = opts.MaxTokens / 4
+	}
+	return &Manager{opts: opts, tokenizer: processor.NewTokenizer(opts.Model)}, nil
+}
+
+// Tokens counts the tokens of messages as sent to the model
+func (m *Manager) Tokens(messages []openai.ChatCompletionMessage) int {
+	tokens := 0
+	for _, message := range messages {
+		tokens += m.messageTokens(message)
+	}
+	return tokens
+}
+
+// messageTokens counts a message with the calls it makes, their arguments can be longer than its content
+func (m *Manager) messageTokens(message openai.ChatCompletionMessage) int {
+	text := message.Role + "\n" + message.Content
+	for _, call := range message.ToolCalls {
+		text += "\n" + call.Function.Name + " " + call.Function.Arguments
+	}
+	return m.tokenizer.Count(text)
+}
+
+// pinned messages are never dropped, a tool call must stay with its results
+func pinned(message openai.ChatCompletionMessage) bool {
+	return message.Role == openai.ChatMessageRoleSystem ||
+		message.Role == openai.ChatMessageRoleTool ||
+		len(message.ToolCalls) > 0
+}
+
+// Fit returns the conversation shortened to the budget and a report of what was removed,
+// the report is nil when the conversation already fits
+func (m *Manager) Fit(messages []openai.ChatCompletionMessage) ([]openai.ChatCompletionMessage, *Truncation, error) {
+	before := m.Tokens(messages)
+	if before <= m.opts.MaxTokens {
+		return messages, nil, nil
+	}
+
+	// the existing summary is replaced, the other turns are candidates oldest first
+	previous := ""
+	var candidates []int
+	for i, message := range messages {
+		if message.Role == openai.ChatMessageRoleSystem && message.Name == SummaryName {
+			previous = strings.TrimPrefix(message.Content, summaryPrefix)
+			continue
+		}
+		if !pinned(message) {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) > m.opts.KeepLast {
+		candidates = candidates[:len(candidates)-m.opts.KeepLast]
+	} else {
+		candidates = nil
+	}
+
+	budget := m.opts.MaxTokens
+	if m.opts.Summarize != nil {
+		budget -= m.opts.SummaryTokens
+	}
+	tokens := before
+	if previous != "" && m.opts.Summarize != nil {
+		// the new summary takes its place, without a summarizer it is kept as it is
+		tokens -= m.messageTokens(summaryMessage(previous))
+	}
+	drop := map[int]bool{}
+	var dropped []openai.ChatCompletionMessage
+	for _, i := range candidates {
+		// whole turns are dropped, the kept part starts with the role the conversation started with
+		if tokens <= budget && (len(dropped) == 0 || messages[i].Role == dropped[0].Role) {
+			break
+		}
+		drop[i] = true
+		dropped = append(dropped, messages[i])
+		tokens -= m.messageTokens(messages[i])
+	}
+
+	summary := previous
+	truncation := &Truncation{Dropped: len(dropped), TokensBefore: before}
+	if m.opts.Summarize != nil && len(dropped) > 0 {
+		var err error
+		summary, err = m.opts.Summarize(previous, dropped)
+		if err != nil {
+			return messages, nil, fmt.Errorf("error summarizing conversation: %w", err)
+		}
+		truncation.Summarized = true
+	}
+
+	fitted := make([]openai.ChatCompletionMessage, 0, len(messages)-len(dropped)+1)
+	summaryAdded := summary == ""
+	for i, message := range messages {
+		if drop[i] || message.Name == SummaryName && message.Role == openai.ChatMessageRoleSystem {
+			continue
+		}
+		// the summary goes after the leading system messages, where the dropped turns were
+		if !summaryAdded && message.Role != openai.ChatMessageRoleSystem {
+			fitted = append(fitted, summaryMessage(summary))
+			summaryAdded = true
+		}
+		fitted = append(fitted, message)
+	}
+	if !summaryAdded {
+		fitted = append(fitted, summaryMessage(summary))
+	}
+
+	truncation.TokensAfter = m.Tokens(fitted)
+	truncation.OverBudget = truncation.TokensAfter > m.opts.MaxTokens
+	log := logger.With("dropped", truncation.Dropped, "summarized", truncation.Summarized,
+		"tokens_before", truncation.TokensBefore, "tokens_after", truncation.TokensAfter, "budget", m.opts.MaxTokens)
+	if truncation.OverBudget {
+		log.Warn("conversation still over budget, only pinned and recent messages are left")
+	} else {
+		log.Info("conversation truncated")
+	}
+	return fitted, truncation, nil
+}
+
+func summaryMessage(summary string) openai.ChatCompletionMessage {
+	return openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleSystem,
+		Name:    SummaryName,
+		Content: summaryPrefix + summary,
+	}
+}
+
+// AISummarizer summarizes dropped turns with a chat model, keeping the facts needed to continue
+func AISummarizer(aiSvc *ai.Service, model string) Summarizer {
+	return func(previous string, messages []openai.ChatCompletionMessage) (string, error) {
+		var transcript strings.Builder
+		for _, message := range messages {
+			fmt.Fprintf(&transcript, "%s: %s\n", strings.ToUpper(message.Role), message.Content)
+		}
+		systemMessage := `You keep the summary of an earlier part of a conversation that no longer fits the context window.
+Extend the previous summary with the new messages. Keep every fact, name, number, identifier, key and result that may be needed to continue the conversation, drop small talk. Write short plain sentences in the language of the conversation, without any other text and markdown formatting.`
+		userMessage := "<previous_summary>" + previous + "</previous_summary>\n<messages>\n" + transcript.String() + "</messages>"
+
+		response, err := aiSvc.ChatCompletion(ai.ChatCompletionConfig{
+			Model: model,
+			Messages: []openai.ChatCompletionMessage{
+				{Role: openai.ChatMessageRoleSystem, Content: systemMessage},
+				{Role: openai.ChatMessageRoleUser, Content: userMessage},
+			},
+		})
+		if err != nil {
+			return "", err
+		}
+		if len(response.Choices) == 0 {
+			return "", errors.New("no choices in response")
+		}
+		return response.Choices[0].Message.Content, nil
+	}
+}
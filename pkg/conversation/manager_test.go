@@ -0,0 +1,176 @@
+package conversation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// testModel is unknown to the tokenizer, tokens are estimated from the length without loading an encoding
+const testModel = "test-model"
+
+func message(role, content string) openai.ChatCompletionMessage {
+	return openai.ChatCompletionMessage{Role: role, Content: content}
+}
+
+// chat is a system prompt followed by turns of a user question and an assistant answer
+func chat(turns int) []openai.ChatCompletionMessage {
+	messages := []openai.ChatCompletionMessage{message(openai.ChatMessageRoleSystem, "You are a helpful assistant.")}
+	for i := 1; i <= turns; i++ {
+		messages = append(messages,
+			message(openai.ChatMessageRoleUser, fmt.Sprintf("Question %d: %s", i, strings.Repeat("words of the question ", 10))),
+			message(openai.ChatMessageRoleAssistant, fmt.Sprintf("Answer %d: %s", i, strings.Repeat("words of the answer ", 10))))
+	}
+	return messages
+}
+
+func contents(messages []openai.ChatCompletionMessage) []string {
+	var got []string
+	for _, m := range messages {
+		got = append(got, m.Role+":"+strings.SplitN(m.Content, ":", 2)[0])
+	}
+	return got
+}
+
+func TestFit(t *testing.T) {
+	probe, _ := New(Options{Model: testModel, MaxTokens: 1})
+	turn := probe.Tokens(chat(1)[1:])
+	system := probe.Tokens(chat(0))
+
+	var gotPrevious string
+	var gotDropped []openai.ChatCompletionMessage
+	summarize := func(previous string, dropped []openai.ChatCompletionMessage) (string, error) {
+		gotPrevious, gotDropped = previous, dropped
+		return "the user asked earlier questions", nil
+	}
+
+	tests := []struct {
+		name       string
+		messages   []openai.ChatCompletionMessage
+		opts       Options
+		wantNil    bool
+		wantDrop   int
+		wantFirst  string
+		wantSumm   bool
+		wantOver   bool
+		wantErr    bool
+		wantPinned []string
+	}{
+		{name: "fits", messages: chat(3), opts: Options{MaxTokens: system + 3*turn}, wantNil: true},
+		{name: "drops whole oldest turns", messages: chat(6), opts: Options{MaxTokens: system + 3*turn + turn/2, KeepLast: 2},
+			wantDrop: 6, wantFirst: "user:Question 4"},
+		{name: "keeps the last turns over budget", messages: chat(6), opts: Options{MaxTokens: system + turn, KeepLast: 4},
+			wantDrop: 8, wantFirst: "user:Question 5", wantOver: true},
+		{name: "summarizes", messages: chat(6), opts: Options{MaxTokens: system + 4*turn, KeepLast: 2, SummaryTokens: turn},
+			wantDrop: 6, wantFirst: "system:Summary of the earlier conversation", wantSumm: true},
+		{name: "summarizer error", messages: chat(6), opts: Options{MaxTokens: system + 2*turn, Summarize: func(string, []openai.ChatCompletionMessage) (string, error) {
+			return "", errors.New("model unavailable")
+		}}, wantErr: true},
+		{name: "pins tool calls and results", messages: append(chat(1),
+			openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, ToolCalls: []openai.ToolCall{{ID: "1", Type: openai.ToolTypeFunction,
+				Function: openai.FunctionCall{Name: "search", Arguments: `{"query":"` + strings.Repeat("x ", 50) + `"}`}}}},
+			openai.ChatCompletionMessage{Role: openai.ChatMessageRoleTool, ToolCallID: "1", Content: "result: found"},
+			message(openai.ChatMessageRoleUser, "Question 2: next"), message(openai.ChatMessageRoleAssistant, "Answer 2: done")),
+			opts: Options{MaxTokens: system + turn, KeepLast: 2}, wantDrop: 2, wantFirst: "assistant:", wantPinned: []string{"tool:result"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantSumm {
+				tt.opts.Summarize = summarize
+			}
+			tt.opts.Model = testModel
+			m, err := New(tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fitted, truncation, err := m.Fit(tt.messages)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Fit() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if len(fitted) != len(tt.messages) {
+					t.Errorf("got %d messages on error, want the conversation unchanged", len(fitted))
+				}
+				return
+			}
+			if tt.wantNil {
+				if truncation != nil || len(fitted) != len(tt.messages) {
+					t.Errorf("Fit() = %v, %+v, want the conversation unchanged", contents(fitted), truncation)
+				}
+				return
+			}
+
+			got := contents(fitted)
+			if truncation.Dropped != tt.wantDrop {
+				t.Errorf("dropped %d, want %d: %v", truncation.Dropped, tt.wantDrop, got)
+			}
+			if got[0] != "system:You are a helpful assistant." {
+				t.Errorf("got %v, want the system prompt first", got)
+			}
+			if got[1] != tt.wantFirst {
+				t.Errorf("got %v, want %s after the system prompt", got, tt.wantFirst)
+			}
+			for _, want := range tt.wantPinned {
+				if !strings.Contains(strings.Join(got, "|"), want) {
+					t.Errorf("got %v, want %s kept", got, want)
+				}
+			}
+			if truncation.Summarized != tt.wantSumm || truncation.OverBudget != tt.wantOver {
+				t.Errorf("got %+v", truncation)
+			}
+			if truncation.TokensAfter != m.Tokens(fitted) || !tt.wantOver && truncation.TokensAfter > tt.opts.MaxTokens {
+				t.Errorf("got %+v for %d tokens, budget %d", truncation, m.Tokens(fitted), tt.opts.MaxTokens)
+			}
+			if tt.wantSumm && (len(gotDropped) != tt.wantDrop || gotDropped[0].Content != tt.messages[1].Content) {
+				t.Errorf("summarized %v, want the %d dropped messages", contents(gotDropped), tt.wantDrop)
+			}
+		})
+	}
+
+	t.Run("replaces the previous summary", func(t *testing.T) {
+		m, err := New(Options{Model: testModel, MaxTokens: system + 4*turn, KeepLast: 2, SummaryTokens: turn, Summarize: summarize})
+		if err != nil {
+			t.Fatal(err)
+		}
+		fitted, _, err := m.Fit(chat(6))
+		if err != nil {
+			t.Fatal(err)
+		}
+		fitted = append(fitted, chat(6)[1:]...)
+		fitted, _, err = m.Fit(fitted)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gotPrevious != "the user asked earlier questions" {
+			t.Errorf("got previous summary %q", gotPrevious)
+		}
+		summaries := 0
+		for _, message := range fitted {
+			if message.Name == SummaryName {
+				summaries++
+			}
+		}
+		if summaries != 1 {
+			t.Errorf("got %d summaries in %v, want 1", summaries, contents(fitted))
+		}
+	})
+
+	t.Run("keeps the previous summary without a summarizer", func(t *testing.T) {
+		summary := summaryMessage(strings.Repeat("an old summary ", 40))
+		messages := append([]openai.ChatCompletionMessage{chat(0)[0], summary}, chat(6)[1:]...)
+		m, err := New(Options{Model: testModel, MaxTokens: system + 3*turn + probe.Tokens([]openai.ChatCompletionMessage{summary}), KeepLast: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		fitted, truncation, err := m.Fit(messages)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fitted[1].Name != SummaryName || truncation.OverBudget {
+			t.Errorf("got %v, %+v, want the summary kept within the budget", contents(fitted), truncation)
+		}
+	})
+}
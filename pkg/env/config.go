@@ -0,0 +1,205 @@
+package env
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/crowmw/ai_devs3/pkg/redact"
+)
+
+// Config is the typed application configuration
+type Config struct {
+	OpenAIKey     string
+	XYZURL        string
+	MyAPIKey      string
+	PoligonURL    string
+	C3ntralaURL   string
+	QdrantURL     string
+	QdrantAPIKey  string
+	JinaAPIKey    string
+	Neo4jURL      string
+	Neo4jUsername string
+	Neo4jPassword string
+	SoftoURL      string
+	NGrokURL      string
+}
+
+// Configuration keys, named after their environment variables
+const (
+	KeyOpenAIKey     = "OPENAI_API_KEY"
+	KeyXYZURL        = "XYZ_URL"
+	KeyMyAPIKey      = "MY_API_KEY"
+	KeyPoligonURL    = "POLIGON_URL"
+	KeyC3ntralaURL   = "C3NTRALA_URL"
+	KeyQdrantURL     = "QDRANT_URL"
+	KeyQdrantAPIKey  = "QDRANT_API_KEY"
+	KeyJinaAPIKey    = "JINA_API_KEY"
+	KeyNeo4jURL      = "NEO4J_URL"
+	KeyNeo4jUsername = "NEO4J_USERNAME"
+	KeyNeo4jPassword = "NEO4J_PASSWORD"
+	KeySoftoURL      = "SOFTO_URL"
+	KeyNGrokURL      = "NGROK_URL"
+)
+
+// field describes a single configuration key
+type field struct {
+	key      string
+	usage    string
+	def      string
+	isURL    bool
+	isSecret bool
+	value    func(*Config) *string
+}
+
+var fields = []field{
+	{key: KeyOpenAIKey, usage: "OpenAI API key", isSecret: true, value: func(c *Config) *string { return &c.OpenAIKey }},
+	{key: KeyXYZURL, usage: "XYZ URL", isURL: true, value: func(c *Config) *string { return &c.XYZURL }},
+	{key: KeyMyAPIKey, usage: "C3ntrala API key", isSecret: true, value: func(c *Config) *string { return &c.MyAPIKey }},
+	{key: KeyPoligonURL, usage: "Poligon URL", isURL: true, value: func(c *Config) *string { return &c.PoligonURL }},
+	{key: KeyC3ntralaURL, usage: "C3ntrala URL", def: "https://c3ntrala.ag3nts.org", isURL: true, value: func(c *Config) *string { return &c.C3ntralaURL }},
+	{key: KeyQdrantURL, usage: "Qdrant host", value: func(c *Config) *string { return &c.QdrantURL }},
+	{key: KeyQdrantAPIKey, usage: "Qdrant API key", isSecret: true, value: func(c *Config) *string { return &c.QdrantAPIKey }},
+	{key: KeyJinaAPIKey, usage: "Jina API key", isSecret: true, value: func(c *Config) *string { return &c.JinaAPIKey }},
+	{key: KeyNeo4jURL, usage: "Neo4j URL", isURL: true, value: func(c *Config) *string { return &c.Neo4jURL }},
+	{key: KeyNeo4jUsername, usage: "Neo4j username", def: "neo4j", value: func(c *Config) *string { return &c.Neo4jUsername }},
+	{key: KeyNeo4jPassword, usage: "Neo4j password", isSecret: true, value: func(c *Config) *string { return &c.Neo4jPassword }},
+	{key: KeySoftoURL, usage: "Softo URL", isURL: true, value: func(c *Config) *string { return &c.SoftoURL }},
+	{key: KeyNGrokURL, usage: "public ngrok URL of local webhooks", isURL: true, value: func(c *Config) *string { return &c.NGrokURL }},
+}
+
+func findField(key string) (field, bool) {
+	for _, f := range fields {
+		if f.key == key {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// flagName turns a key like C3NTRALA_URL into c3ntrala-url
+func flagName(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "_", "-")
+}
+
+// Get returns the value of a configuration key
+func (c *Config) Get(key string) string {
+	if f, ok := findField(key); ok {
+		return *f.value(c)
+	}
+	return ""
+}
+
+// Set changes the value of a configuration key
+func (c *Config) Set(key, value string) error {
+	f, ok := findField(key)
+	if !ok {
+		return fmt.Errorf("unknown configuration key: %s", key)
+	}
+	*f.value(c) = value
+	return nil
+}
+
+// Validate checks that required keys are set and that URLs are well formed
+func (c *Config) Validate(required ...string) error {
+	var missing []string
+	for _, key := range required {
+		if _, ok := findField(key); !ok {
+			return fmt.Errorf("unknown configuration key: %s", key)
+		}
+		if c.Get(key) == "" {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("missing required configuration: %s", strings.Join(missing, ", "))
+	}
+
+	for _, f := range fields {
+		value := *f.value(c)
+		if !f.isURL || value == "" {
+			continue
+		}
+		parsed, err := url.Parse(value)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("invalid URL in %s: %q", f.key, value)
+		}
+	}
+	return nil
+}
+
+// registerSecrets makes secret values masked in logs and saved artifacts
+func (c *Config) registerSecrets() {
+	for _, f := range fields {
+		if f.isSecret {
+			redact.Register(*f.value(c))
+		}
+	}
+}
+
+// defaultConfig returns a configuration with default values
+func defaultConfig() Config {
+	var c Config
+	for _, f := range fields {
+		*f.value(&c) = f.def
+	}
+	return c
+}
+
+// applyFile overrides configuration with values from a JSON file keyed by environment variable names
+func (c *Config) applyFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+
+	var values map[string]string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+	for key, value := range values {
+		if err := c.Set(key, value); err != nil {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// applyEnv overrides configuration with non-empty environment variables
+func (c *Config) applyEnv() {
+	for _, f := range fields {
+		if value := os.Getenv(f.key); value != "" {
+			*f.value(c) = value
+		}
+	}
+}
+
+// Flags holds configuration flags registered on a flag set
+type Flags struct {
+	configFile string
+	values     map[string]*string
+}
+
+// RegisterFlags registers -config and one flag per configuration key, e.g. -c3ntrala-url
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	flags := &Flags{values: make(map[string]*string)}
+	fs.StringVar(&flags.configFile, "config", "", "path to a JSON config file")
+	for _, f := range fields {
+		flags.values[f.key] = fs.String(flagName(f.key), "", f.usage+" (overrides "+f.key+")")
+	}
+	return flags
+}
+
+// apply overrides configuration with flags that were set
+func (flags *Flags) apply(c *Config) {
+	for key, value := range flags.values {
+		if *value != "" {
+			c.Set(key, *value)
+		}
+	}
+}
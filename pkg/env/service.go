@@ -1,133 +1,163 @@
 package env
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
-	"strings"
 
 	"github.com/joho/godotenv"
 )
 
-// EnvService handles environment configuration
+// DefaultConfigFile is read when it exists and no other config file was given
+const DefaultConfigFile = "config.json"
+
+// Service handles application configuration.
+// Values are resolved from defaults, then a JSON config file, then the environment (.env included), then CLI flags.
 type Service struct {
-	openAIKey     string
-	xyzURL        string
-	myAPIKey      string
-	poligonURL    string
-	c3ntralaURL   string
-	qdrantURL     string
-	qdrantAPIKey  string
-	jinaAPIKey    string
-	neo4jURL      string
-	neo4jUsername string
-	neo4jPassword string
-	softoURL      string
-	ngrokURL      string
-}
-
-// validateEnv checks if all required environment variables are set
-func validateEnv(envVars map[string]string) error {
-	var missing []string
-	for name, value := range envVars {
-		if value == "" {
-			missing = append(missing, name)
-		}
+	config Config
+}
+
+type options struct {
+	configFile string
+	required   []string
+	flags      *Flags
+}
+
+// Option customizes how the configuration is loaded
+type Option func(*options)
+
+// WithRequired makes the given keys mandatory for the command
+func WithRequired(keys ...string) Option {
+	return func(o *options) {
+		o.required = append(o.required, keys...)
 	}
-	if len(missing) > 0 {
-		return fmt.Errorf("missing required environment variables: %s", strings.Join(missing, ", "))
+}
+
+// WithConfigFile reads configuration from the given JSON file
+func WithConfigFile(path string) Option {
+	return func(o *options) {
+		o.configFile = path
+	}
+}
+
+// WithFlags applies flags registered with RegisterFlags; call after flag parsing
+func WithFlags(flags *Flags) Option {
+	return func(o *options) {
+		o.flags = flags
 	}
-	return nil
 }
 
-// NewEnvService creates a new environment service
-func NewService() (*Service, error) {
-	if err := godotenv.Load(); err != nil {
+// NewService loads and validates the configuration
+func NewService(opts ...Option) (*Service, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.flags != nil && o.flags.configFile != "" {
+		o.configFile = o.flags.configFile
+	}
+	if o.configFile == "" {
+		o.configFile = os.Getenv("AI_DEVS_CONFIG")
+	}
+
+	config := defaultConfig()
+
+	configFile := o.configFile
+	if configFile == "" {
+		if _, err := os.Stat(DefaultConfigFile); err == nil {
+			configFile = DefaultConfigFile
+		}
+	}
+	if configFile != "" {
+		if err := config.applyFile(configFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
 		return nil, fmt.Errorf("error loading .env file: %w", err)
 	}
+	config.applyEnv()
 
-	envVars := map[string]string{
-		"OPENAI_API_KEY": os.Getenv("OPENAI_API_KEY"),
-		"XYZ_URL":        os.Getenv("XYZ_URL"),
-		"MY_API_KEY":     os.Getenv("MY_API_KEY"),
-		"POLIGON_URL":    os.Getenv("POLIGON_URL"),
-		"C3NTRALA_URL":   os.Getenv("C3NTRALA_URL"),
-		"QDRANT_URL":     os.Getenv("QDRANT_URL"),
-		"QDRANT_API_KEY": os.Getenv("QDRANT_API_KEY"),
-		"JINA_API_KEY":   os.Getenv("JINA_API_KEY"),
-		"NEO4J_URL":      os.Getenv("NEO4J_URL"),
-		"NEO4J_USERNAME": os.Getenv("NEO4J_USERNAME"),
-		"NEO4J_PASSWORD": os.Getenv("NEO4J_PASSWORD"),
-		"SOFTO_URL":      os.Getenv("SOFTO_URL"),
-		"NGROK_URL":      os.Getenv("NGROK_URL"),
+	if o.flags != nil {
+		o.flags.apply(&config)
 	}
 
-	if err := validateEnv(envVars); err != nil {
-		panic(fmt.Sprintf("Environment validation failed: %v", err))
+	if err := config.Validate(o.required...); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
 	}
 
-	return &Service{
-		openAIKey:     envVars["OPENAI_API_KEY"],
-		xyzURL:        envVars["XYZ_URL"],
-		myAPIKey:      envVars["MY_API_KEY"],
-		poligonURL:    envVars["POLIGON_URL"],
-		c3ntralaURL:   envVars["C3NTRALA_URL"],
-		qdrantURL:     envVars["QDRANT_URL"],
-		qdrantAPIKey:  envVars["QDRANT_API_KEY"],
-		jinaAPIKey:    envVars["JINA_API_KEY"],
-		neo4jURL:      envVars["NEO4J_URL"],
-		neo4jUsername: envVars["NEO4J_USERNAME"],
-		neo4jPassword: envVars["NEO4J_PASSWORD"],
-		softoURL:      envVars["SOFTO_URL"],
-		ngrokURL:      envVars["NGROK_URL"],
-	}, nil
+	config.registerSecrets()
+	return &Service{config: config}, nil
+}
+
+// NewServiceFromConfig creates a service from an already built configuration
+func NewServiceFromConfig(config Config) (*Service, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	config.registerSecrets()
+	return &Service{config: config}, nil
+}
+
+// Config returns a copy of the configuration
+func (s *Service) Config() Config {
+	return s.config
+}
+
+// Require checks that the given keys are set, so services can validate what they need
+func (s *Service) Require(keys ...string) error {
+	return s.config.Validate(keys...)
 }
 
 // GetOpenAIKey returns the OpenAI API key
 func (s *Service) GetOpenAIKey() string {
-	return s.openAIKey
+	return s.config.OpenAIKey
 }
 
 // GetXYZURL returns the XYZ URL
 func (s *Service) GetXYZURL() string {
-	return s.xyzURL
+	return s.config.XYZURL
 }
 
 // GetMyAPIKey returns the API key
 func (s *Service) GetMyAPIKey() string {
-	return s.myAPIKey
+	return s.config.MyAPIKey
 }
 
 // GetPoligonURL returns the Poligon URL
 func (s *Service) GetPoligonURL() string {
-	return s.poligonURL
+	return s.config.PoligonURL
 }
 
 // GetC3ntralaURL returns the C3ntrala URL
 func (s *Service) GetC3ntralaURL() string {
-	return s.c3ntralaURL
+	return s.config.C3ntralaURL
 }
 
 // GetQdrantURL returns the Qdrant URL
 func (s *Service) GetQdrantURL() string {
-	return s.qdrantURL
+	return s.config.QdrantURL
 }
 
 // GetQdrantAPIKey returns the Qdrant API key
 func (s *Service) GetQdrantAPIKey() string {
-	return s.qdrantAPIKey
+	return s.config.QdrantAPIKey
 }
 
 // GetJinaAPIKey returns the Jina API key
 func (s *Service) GetJinaAPIKey() string {
-	return s.jinaAPIKey
+	return s.config.JinaAPIKey
 }
 
 func (s *Service) GetSoftoURL() string {
-	return s.softoURL
+	return s.config.SoftoURL
 }
 
 func (s *Service) GetNGrokURL() string {
-	return s.ngrokURL
+	return s.config.NGrokURL
 }
 
 func (s *Service) GetNeo4jConfig() struct {
@@ -140,8 +170,8 @@ func (s *Service) GetNeo4jConfig() struct {
 		Username string `json:"username"`
 		Password string `json:"password"`
 	}{
-		URL:      s.neo4jURL,
-		Username: s.neo4jUsername,
-		Password: s.neo4jPassword,
+		URL:      s.config.Neo4jURL,
+		Username: s.config.Neo4jUsername,
+		Password: s.config.Neo4jPassword,
 	}
 }
@@ -0,0 +1,151 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/crowmw/ai_devs3/pkg/agent"
+	"github.com/crowmw/ai_devs3/pkg/ai"
+)
+
+// Suite is a set of scenarios for one agent
+type Suite struct {
+	// Agent names the suite, e.g. "gps"
+	Agent       string
+	Description string
+	// Dir holds the scenario files
+	Dir string
+	// Options define the agent, tool handlers are replaced by the mocks of each scenario
+	Options func() agent.Options
+	// Synthesize turns a finished run into the answer checked by the scenarios, nil uses the final_answer result
+	Synthesize func(a *agent.Agent) (interface{}, error)
+}
+
+var (
+	suitesMu sync.Mutex
+	suites   = map[string]Suite{}
+)
+
+// Register adds a suite, usually from the init function of the agent's task
+func Register(suite Suite) {
+	suitesMu.Lock()
+	defer suitesMu.Unlock()
+	if _, ok := suites[suite.Agent]; ok {
+		panic(fmt.Sprintf("eval suite %q registered twice", suite.Agent))
+	}
+	suites[suite.Agent] = suite
+}
+
+// Suites returns registered suites sorted by agent name
+func Suites() []Suite {
+	suitesMu.Lock()
+	defer suitesMu.Unlock()
+	list := make([]Suite, 0, len(suites))
+	for _, suite := range suites {
+		list = append(list, suite)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Agent < list[j].Agent })
+	return list
+}
+
+// GetSuite returns a registered suite by agent name
+func GetSuite(name string) (Suite, bool) {
+	suitesMu.Lock()
+	defer suitesMu.Unlock()
+	suite, ok := suites[name]
+	return suite, ok
+}
+
+// Run runs every scenario of the suite the given number of times.
+// Runs are sequential so the token usage of each one can be read from the AI service.
+func Run(aiSvc *ai.Service, suite Suite, runs int) (*Report, error) {
+	scenarios, err := LoadScenarios(suite.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if runs <= 0 {
+		runs = 1
+	}
+
+	opts := suite.Options()
+	report := &Report{
+		Agent:           suite.Agent,
+		PromptVersion:   opts.Prompts.Version,
+		Model:           opts.Model,
+		RunsPerScenario: runs,
+		CreatedAt:       time.Now(),
+	}
+	if report.PromptVersion == "" {
+		report.PromptVersion = "unversioned"
+	}
+	if report.Model == "" {
+		report.Model = "gpt-4o"
+	}
+
+	for i := range scenarios {
+		scenario := &scenarios[i]
+		result := ScenarioResult{Scenario: scenario.Name}
+		for run := 1; run <= runs; run++ {
+			logger.Info("running scenario", "agent", suite.Agent, "scenario", scenario.Name, "run", run, "runs", runs)
+			result.Runs = append(result.Runs, runScenario(aiSvc, suite, scenario, run))
+		}
+		result.summarize()
+		report.Scenarios = append(report.Scenarios, result)
+	}
+	report.summarize()
+	return report, nil
+}
+
+func runScenario(aiSvc *ai.Service, suite Suite, scenario *Scenario, run int) RunResult {
+	opts := suite.Options()
+	// evals never checkpoint and never wait for an operator
+	opts.Store = nil
+	opts.Oversight = agent.Oversight{}
+	if len(scenario.Messages) > 0 {
+		opts.Messages = scenario.Messages
+	}
+	m := newMock(scenario)
+	opts.Tools = m.tools(opts.Tools)
+
+	result := RunResult{Run: run}
+	a, err := agent.New(aiSvc, opts)
+	if err != nil {
+		result.Failures = []string{fmt.Sprintf("error creating agent: %v", err)}
+		return result
+	}
+
+	before := aiSvc.Usage()
+	start := time.Now()
+	answer, err := a.Run(scenario.Question)
+	if err == nil && suite.Synthesize != nil {
+		answer, err = suite.Synthesize(a)
+	}
+	usage := aiSvc.Usage().Sub(before)
+
+	result.Answer = answer
+	result.Steps = a.State.Config.Step
+	result.Tokens = usage.TotalTokens()
+	result.Cost = usage.Cost
+	result.Duration = time.Since(start)
+	var abortErr *agent.AbortError
+	if errors.As(err, &abortErr) {
+		result.Termination = string(abortErr.Termination)
+	}
+	if err != nil {
+		result.Failures = []string{fmt.Sprintf("run failed: %v", err)}
+	} else {
+		result.Failures = scenario.Expect.check(answer, result.Steps, m)
+	}
+	result.Passed = len(result.Failures) == 0
+
+	log := logger.With("scenario", scenario.Name, "run", run, "steps", result.Steps, "tokens", result.Tokens)
+	if result.Passed {
+		log.Info("scenario passed")
+	} else {
+		log.Warn("scenario failed", "failures", result.Failures)
+	}
+	return result
+}
@@ -0,0 +1,215 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RunResult is one run of a scenario
+type RunResult struct {
+	Run      int      `json:"run"`
+	Passed   bool     `json:"passed"`
+	Failures []string `json:"failures,omitempty"`
+	// Termination is why a run stopped without an answer, e.g. max_steps
+	Termination string        `json:"termination,omitempty"`
+	Answer      interface{}   `json:"answer,omitempty"`
+	Steps       int           `json:"steps"`
+	Tokens      int           `json:"tokens"`
+	Cost        float64       `json:"cost"`
+	Duration    time.Duration `json:"duration"`
+}
+
+// ScenarioResult sums up the runs of a scenario
+type ScenarioResult struct {
+	Scenario  string      `json:"scenario"`
+	Runs      []RunResult `json:"runs"`
+	PassRate  float64     `json:"pass_rate"`
+	AvgSteps  float64     `json:"avg_steps"`
+	AvgTokens float64     `json:"avg_tokens"`
+	Cost      float64     `json:"cost"`
+}
+
+func (r *ScenarioResult) summarize() {
+	if len(r.Runs) == 0 {
+		return
+	}
+	passed, steps, tokens := 0, 0, 0
+	r.Cost = 0
+	for _, run := range r.Runs {
+		if run.Passed {
+			passed++
+		}
+		steps += run.Steps
+		tokens += run.Tokens
+		r.Cost += run.Cost
+	}
+	n := float64(len(r.Runs))
+	r.PassRate = float64(passed) / n
+	r.AvgSteps = float64(steps) / n
+	r.AvgTokens = float64(tokens) / n
+}
+
+// Report is the result of an eval of one prompt version
+type Report struct {
+	Agent           string           `json:"agent"`
+	PromptVersion   string           `json:"prompt_version"`
+	Model           string           `json:"model"`
+	RunsPerScenario int              `json:"runs_per_scenario"`
+	CreatedAt       time.Time        `json:"created_at"`
+	Scenarios       []ScenarioResult `json:"scenarios"`
+	Runs            int              `json:"runs"`
+	PassRate        float64          `json:"pass_rate"`
+	AvgSteps        float64          `json:"avg_steps"`
+	AvgTokens       float64          `json:"avg_tokens"`
+	Cost            float64          `json:"cost"`
+}
+
+func (r *Report) summarize() {
+	passed, steps, tokens := 0, 0, 0
+	r.Runs, r.Cost = 0, 0
+	for _, scenario := range r.Scenarios {
+		for _, run := range scenario.Runs {
+			r.Runs++
+			if run.Passed {
+				passed++
+			}
+			steps += run.Steps
+			tokens += run.Tokens
+		}
+		r.Cost += scenario.Cost
+	}
+	if r.Runs == 0 {
+		return
+	}
+	n := float64(r.Runs)
+	r.PassRate = float64(passed) / n
+	r.AvgSteps = float64(steps) / n
+	r.AvgTokens = float64(tokens) / n
+}
+
+// Save writes the report to <dir>/<agent>-<time>.json and returns the file name
+func (r *Report) Save(dir string) (string, error) {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error marshaling report: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("error creating eval directory: %w", err)
+	}
+	file := filepath.Join(dir, fmt.Sprintf("%s-%s.json", r.Agent, r.CreatedAt.Format("20060102-150405")))
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return "", fmt.Errorf("error writing report: %w", err)
+	}
+	return file, nil
+}
+
+// LoadReports reads the saved reports of an agent, oldest first
+func LoadReports(dir, agentName string) ([]Report, error) {
+	files, err := filepath.Glob(filepath.Join(dir, agentName+"-*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("error listing reports: %w", err)
+	}
+	var reports []Report
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("error reading report: %w", err)
+		}
+		var report Report
+		if err := json.Unmarshal(data, &report); err != nil {
+			logger.Warn("skipping report", "file", file, "error", err)
+			continue
+		}
+		if report.Agent == agentName {
+			reports = append(reports, report)
+		}
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].CreatedAt.Before(reports[j].CreatedAt) })
+	return reports, nil
+}
+
+// VersionSummary sums up all saved runs of a prompt version with a model
+type VersionSummary struct {
+	PromptVersion string
+	Model         string
+	Reports       int
+	Runs          int
+	PassRate      float64
+	AvgSteps      float64
+	AvgTokens     float64
+	CostPerRun    float64
+	LastRun       time.Time
+}
+
+// Compare groups reports by prompt version and model, in the order the versions were first evaluated
+func Compare(reports []Report) []VersionSummary {
+	var summaries []VersionSummary
+	index := map[string]int{}
+	for _, report := range reports {
+		key := report.PromptVersion + "\x00" + report.Model
+		i, ok := index[key]
+		if !ok {
+			i = len(summaries)
+			index[key] = i
+			summaries = append(summaries, VersionSummary{PromptVersion: report.PromptVersion, Model: report.Model})
+		}
+		s := &summaries[i]
+		// running sums, turned into averages below
+		n := float64(report.Runs)
+		s.Reports++
+		s.Runs += report.Runs
+		s.PassRate += report.PassRate * n
+		s.AvgSteps += report.AvgSteps * n
+		s.AvgTokens += report.AvgTokens * n
+		s.CostPerRun += report.Cost
+		s.LastRun = report.CreatedAt
+	}
+	for i := range summaries {
+		s := &summaries[i]
+		if s.Runs == 0 {
+			continue
+		}
+		n := float64(s.Runs)
+		s.PassRate /= n
+		s.AvgSteps /= n
+		s.AvgTokens /= n
+		s.CostPerRun /= n
+	}
+	return summaries
+}
+
+// Format renders the report as a table of scenarios with the failures of each run
+func (r *Report) Format() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s prompts %s on %s, %d runs per scenario\n\n", r.Agent, r.PromptVersion, r.Model, r.RunsPerScenario)
+	fmt.Fprintf(&b, "%-32s %6s %9s %10s %9s\n", "scenario", "pass", "avg steps", "avg tokens", "cost")
+	for _, scenario := range r.Scenarios {
+		fmt.Fprintf(&b, "%-32s %5.0f%% %9.1f %10.0f %9s\n", scenario.Scenario, scenario.PassRate*100, scenario.AvgSteps, scenario.AvgTokens, formatCost(scenario.Cost))
+		for _, run := range scenario.Runs {
+			for _, failure := range run.Failures {
+				fmt.Fprintf(&b, "  run %d: %s\n", run.Run, failure)
+			}
+		}
+	}
+	fmt.Fprintf(&b, "%-32s %5.0f%% %9.1f %10.0f %9s\n", "total", r.PassRate*100, r.AvgSteps, r.AvgTokens, formatCost(r.Cost))
+	return b.String()
+}
+
+// FormatComparison renders version summaries as a table
+func FormatComparison(summaries []VersionSummary) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-16s %-14s %5s %6s %9s %10s %10s  %s\n", "prompt version", "model", "runs", "pass", "avg steps", "avg tokens", "cost/run", "last run")
+	for _, s := range summaries {
+		fmt.Fprintf(&b, "%-16s %-14s %5d %5.0f%% %9.1f %10.0f %10s  %s\n", s.PromptVersion, s.Model, s.Runs, s.PassRate*100, s.AvgSteps, s.AvgTokens, formatCost(s.CostPerRun), s.LastRun.Format("2006-01-02 15:04"))
+	}
+	return b.String()
+}
+
+func formatCost(usd float64) string {
+	return fmt.Sprintf("$%.4f", usd)
+}
@@ -0,0 +1,201 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/crowmw/ai_devs3/pkg/agent"
+	"github.com/crowmw/ai_devs3/pkg/logging"
+	"github.com/sashabaranov/go-openai"
+)
+
+var logger = logging.New("eval")
+
+// Scenario is a question for an agent with mocked tool responses and the expected outcome
+type Scenario struct {
+	Name     string `json:"name"`
+	Question string `json:"question"`
+	// Messages replace the initial conversation of the agent, e.g. a system prompt with fixture data
+	Messages []openai.ChatCompletionMessage `json:"messages,omitempty"`
+	// Tools maps tool names to their responses, the first one matching the payload is returned.
+	// Tools missing here return an error to the agent.
+	Tools  map[string][]MockResponse `json:"tools"`
+	Expect Expect                    `json:"expect"`
+}
+
+// MockResponse is a canned tool result
+type MockResponse struct {
+	// Match lists payload fields the call must have, an empty match answers any call.
+	// Values are compared loosely, 1 matches "1".
+	Match  map[string]interface{} `json:"match,omitempty"`
+	Result interface{}            `json:"result,omitempty"`
+	// Error makes the tool fail with this message instead
+	Error string `json:"error,omitempty"`
+}
+
+// Expect lists the checks of a run, all of them must pass
+type Expect struct {
+	// Answer must equal the final answer, strings ignore case and surrounding spaces
+	Answer interface{} `json:"answer,omitempty"`
+	// Contains are substrings of the answer, or of its JSON when it is not a string
+	Contains []string `json:"contains,omitempty"`
+	// Tools must all be called at least once
+	Tools    []string `json:"tools,omitempty"`
+	MaxSteps int      `json:"max_steps,omitempty"`
+}
+
+// LoadScenarios reads every *.json file of a directory, sorted by file name
+func LoadScenarios(dir string) ([]Scenario, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("error listing scenarios: %w", err)
+	}
+	sort.Strings(files)
+
+	var scenarios []Scenario
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("error reading scenario: %w", err)
+		}
+		var scenario Scenario
+		if err := json.Unmarshal(data, &scenario); err != nil {
+			return nil, fmt.Errorf("error parsing scenario %s: %w", file, err)
+		}
+		if scenario.Name == "" {
+			scenario.Name = strings.TrimSuffix(filepath.Base(file), ".json")
+		}
+		if scenario.Question == "" {
+			return nil, fmt.Errorf("scenario %s has no question", scenario.Name)
+		}
+		scenarios = append(scenarios, scenario)
+	}
+	if len(scenarios) == 0 {
+		return nil, fmt.Errorf("no scenarios in %s", dir)
+	}
+	return scenarios, nil
+}
+
+// mock answers tool calls of one run from the scenario and remembers which tools were called
+type mock struct {
+	scenario *Scenario
+	mu       sync.Mutex
+	calls    map[string]int
+}
+
+func newMock(scenario *Scenario) *mock {
+	return &mock{scenario: scenario, calls: map[string]int{}}
+}
+
+// tools keeps names, descriptions and schemas of the agent's tools so prompts stay the same, only handlers change
+func (m *mock) tools(tools []agent.Tool) []agent.Tool {
+	mocked := make([]agent.Tool, len(tools))
+	for i, tool := range tools {
+		mocked[i] = tool
+		if tool.Name == agent.FinalAnswer {
+			continue
+		}
+		name := tool.Name
+		mocked[i].Handler = func(payload map[string]interface{}) (agent.ToolResult, error) {
+			return m.call(name, payload), nil
+		}
+	}
+	return mocked
+}
+
+func (m *mock) call(tool string, payload map[string]interface{}) agent.ToolResult {
+	m.mu.Lock()
+	m.calls[tool]++
+	m.mu.Unlock()
+
+	for _, response := range m.scenario.Tools[tool] {
+		if !matches(response.Match, payload) {
+			continue
+		}
+		if response.Error != "" {
+			return agent.ToolResult{Status: agent.ToolError, Data: response.Error}
+		}
+		return agent.ToolResult{Status: agent.ToolSuccess, Data: response.Result}
+	}
+	logger.Debug("no mocked response", "scenario", m.scenario.Name, logging.KeyTool, tool, "payload", payload)
+	return agent.ToolResult{Status: agent.ToolError, Data: fmt.Sprintf("no data found for payload %v", payload)}
+}
+
+func (m *mock) called(tool string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[tool] > 0
+}
+
+func matches(match, payload map[string]interface{}) bool {
+	for key, want := range match {
+		got, ok := payload[key]
+		if !ok || fmt.Sprint(normalize(got)) != fmt.Sprint(normalize(want)) {
+			return false
+		}
+	}
+	return true
+}
+
+// check returns what a run got wrong, nothing when it passed
+func (e Expect) check(answer interface{}, steps int, m *mock) []string {
+	var failures []string
+	if e.Answer != nil && !equalAnswers(e.Answer, answer) {
+		failures = append(failures, fmt.Sprintf("answer %s, expected %s", answerText(answer), answerText(e.Answer)))
+	}
+	text := strings.ToLower(answerText(answer))
+	for _, s := range e.Contains {
+		if !strings.Contains(text, strings.ToLower(s)) {
+			failures = append(failures, fmt.Sprintf("answer does not contain %q", s))
+		}
+	}
+	for _, tool := range e.Tools {
+		if !m.called(tool) {
+			failures = append(failures, fmt.Sprintf("tool %s was not called", tool))
+		}
+	}
+	if e.MaxSteps > 0 && steps > e.MaxSteps {
+		failures = append(failures, fmt.Sprintf("took %d steps, expected at most %d", steps, e.MaxSteps))
+	}
+	return failures
+}
+
+func equalAnswers(expected, answer interface{}) bool {
+	expected, answer = normalize(expected), normalize(answer)
+	if e, ok := expected.(string); ok {
+		if a, ok := answer.(string); ok {
+			return strings.EqualFold(strings.TrimSpace(e), strings.TrimSpace(a))
+		}
+	}
+	return reflect.DeepEqual(expected, answer)
+}
+
+// normalize turns a value into what encoding/json decodes it to, so structs compare equal to maps
+func normalize(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return v
+	}
+	return normalized
+}
+
+func answerText(answer interface{}) string {
+	if s, ok := answer.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(answer)
+	if err != nil {
+		return fmt.Sprint(answer)
+	}
+	return string(data)
+}
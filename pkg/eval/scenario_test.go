@@ -0,0 +1,229 @@
+package eval
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/crowmw/ai_devs3/pkg/agent"
+)
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		match   map[string]interface{}
+		payload map[string]interface{}
+		want    bool
+	}{
+		{"empty match answers any call", nil, map[string]interface{}{"userID": 1}, true},
+		{"number matches string", map[string]interface{}{"userID": 1}, map[string]interface{}{"userID": "1"}, true},
+		{"string matches number", map[string]interface{}{"userID": "1"}, map[string]interface{}{"userID": 1.0}, true},
+		{"extra payload fields ignored", map[string]interface{}{"name": "Barbara"}, map[string]interface{}{"name": "Barbara", "limit": 5}, true},
+		{"different value", map[string]interface{}{"userID": 1}, map[string]interface{}{"userID": 2}, false},
+		{"missing field", map[string]interface{}{"userID": 1}, map[string]interface{}{}, false},
+		{"case matters", map[string]interface{}{"name": "Barbara"}, map[string]interface{}{"name": "BARBARA"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matches(tt.match, tt.payload); got != tt.want {
+				t.Errorf("matches(%v, %v) = %v, want %v", tt.match, tt.payload, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEqualAnswers(t *testing.T) {
+	type coordinates struct {
+		Lat float64 `json:"lat"`
+		Lon float64 `json:"lon"`
+	}
+	tests := []struct {
+		name     string
+		expected interface{}
+		answer   interface{}
+		want     bool
+	}{
+		{"strings ignore case and spaces", "Lubawa", "  lubawa\n", true},
+		{"different strings", "Lubawa", "Grudziądz", false},
+		{"struct equals map", map[string]interface{}{"lat": 53.5, "lon": 19.7}, coordinates{53.5, 19.7}, true},
+		{"struct differs from map", map[string]interface{}{"lat": 53.5, "lon": 19.7}, coordinates{53.5, 0}, false},
+		{"int equals float", 1, 1.0, true},
+		{"number is not a string", 1, "1", false},
+		{"slices in order", []string{"a", "b"}, []interface{}{"a", "b"}, true},
+		{"slices out of order", []string{"a", "b"}, []string{"b", "a"}, false},
+		{"nil answer", "Lubawa", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := equalAnswers(tt.expected, tt.answer); got != tt.want {
+				t.Errorf("equalAnswers(%v, %v) = %v, want %v", tt.expected, tt.answer, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+		Age  int    `json:"age,omitempty"`
+	}
+	tests := []struct {
+		input interface{}
+		want  interface{}
+	}{
+		{user{Name: "Barbara"}, map[string]interface{}{"name": "Barbara"}},
+		{[]int{1, 2}, []interface{}{1.0, 2.0}},
+		{3, 3.0},
+		{"text", "text"},
+		{nil, nil},
+	}
+	for _, tt := range tests {
+		if got := normalize(tt.input); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("normalize(%v) = %#v, want %#v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestExpectCheck(t *testing.T) {
+	scenario := &Scenario{Name: "test", Tools: map[string][]MockResponse{
+		"gps": {{Match: map[string]interface{}{"userID": 1}, Result: map[string]interface{}{"lat": 53.5}}},
+	}}
+
+	tests := []struct {
+		name   string
+		expect Expect
+		answer interface{}
+		steps  int
+		calls  []string
+		want   []string
+	}{
+		{
+			name:   "everything passes",
+			expect: Expect{Answer: "Lubawa", Contains: []string{"luba"}, Tools: []string{"gps"}, MaxSteps: 3},
+			answer: "lubawa",
+			steps:  3,
+			calls:  []string{"gps"},
+		},
+		{
+			name:   "wrong answer",
+			expect: Expect{Answer: "Lubawa"},
+			answer: "Grudziądz",
+			want:   []string{"answer Grudziądz, expected Lubawa"},
+		},
+		{
+			name:   "contains checks the JSON of other answers",
+			expect: Expect{Contains: []string{`"lat":53.5`, "lon"}},
+			answer: map[string]interface{}{"lat": 53.5},
+			want:   []string{`answer does not contain "lon"`},
+		},
+		{
+			name:   "missing tool call is a failure",
+			expect: Expect{Tools: []string{"gps", "database"}},
+			answer: "Lubawa",
+			calls:  []string{"gps"},
+			want:   []string{"tool database was not called"},
+		},
+		{
+			name:   "too many steps",
+			expect: Expect{MaxSteps: 2},
+			answer: "Lubawa",
+			steps:  4,
+			want:   []string{"took 4 steps, expected at most 2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMock(scenario)
+			for _, tool := range tt.calls {
+				m.call(tool, map[string]interface{}{"userID": "1"})
+			}
+			if got := tt.expect.check(tt.answer, tt.steps, m); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("check() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMockCall(t *testing.T) {
+	m := newMock(&Scenario{Name: "test", Tools: map[string][]MockResponse{
+		"gps": {
+			{Match: map[string]interface{}{"userID": 1}, Error: "gps is down"},
+			{Result: "53.5,19.7"},
+		},
+	}})
+
+	tests := []struct {
+		tool    string
+		payload map[string]interface{}
+		want    agent.ToolResult
+	}{
+		{"gps", map[string]interface{}{"userID": "1"}, agent.ToolResult{Status: agent.ToolError, Data: "gps is down"}},
+		{"gps", map[string]interface{}{"userID": 2}, agent.ToolResult{Status: agent.ToolSuccess, Data: "53.5,19.7"}},
+		{"database", map[string]interface{}{"query": "x"}, agent.ToolResult{Status: agent.ToolError, Data: "no data found for payload map[query:x]"}},
+	}
+	for _, tt := range tests {
+		if got := m.call(tt.tool, tt.payload); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("call(%s, %v) = %+v, want %+v", tt.tool, tt.payload, got, tt.want)
+		}
+	}
+	if !m.called("database") || m.called("final_answer") {
+		t.Error("called() does not follow the calls")
+	}
+}
+
+func TestLoadScenarios(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "sorted by file name, name defaults to the file",
+			files: map[string]string{
+				"b.json":    `{"name": "second", "question": "Where?"}`,
+				"a.json":    `{"question": "Who?", "expect": {"answer": "Barbara"}}`,
+				"notes.txt": `not a scenario`,
+			},
+			want: []string{"a", "second"},
+		},
+		{name: "no scenarios", files: map[string]string{}, wantErr: true},
+		{name: "missing question", files: map[string]string{"a.json": `{"name": "a"}`}, wantErr: true},
+		{name: "invalid JSON", files: map[string]string{"a.json": `{"question": `}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			scenarios, err := LoadScenarios(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadScenarios() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, scenario := range scenarios {
+				got = append(got, scenario.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got scenarios %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFixtureScenarios(t *testing.T) {
+	scenarios, err := LoadScenarios(filepath.Join("..", "..", "fixtures", "evals", "gps"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, scenario := range scenarios {
+		if scenario.Expect.Answer == nil && len(scenario.Expect.Contains) == 0 && len(scenario.Expect.Tools) == 0 {
+			t.Errorf("scenario %s checks nothing", scenario.Name)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package explorer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/crowmw/ai_devs3/pkg/graph"
+	"github.com/crowmw/ai_devs3/pkg/logging"
+)
+
+var logger = logging.New("explorer")
+
+// Entity is a single item discovered during exploration, e.g. a person or a place
+type Entity struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// key returns a unique identifier of the entity used for visited tracking and graph node IDs
+func (e Entity) key() string {
+	return e.Type + ":" + e.Value
+}
+
+// ExpandFunc returns entities directly connected to the given value
+type ExpandFunc func(ctx context.Context, value string) ([]Entity, error)
+
+// Discovery describes a single edge found during exploration
+type Discovery struct {
+	From  Entity `json:"from"`
+	To    Entity `json:"to"`
+	Depth int    `json:"depth"`
+}
+
+// StopFunc decides whether exploration should stop after the given discovery
+type StopFunc func(discovery Discovery) bool
+
+// Config holds the exploration settings
+type Config struct {
+	// Seeds are the entities exploration starts from
+	Seeds []Entity
+	// Expanders maps an entity type to the function returning its neighbours
+	Expanders map[string]ExpandFunc
+	// Relationships maps an entity type to the relationship name used for its outgoing edges
+	Relationships map[string]string
+	// Normalize is applied to every entity value before visited tracking (optional)
+	Normalize func(string) string
+	// MaxDepth limits the number of expansion rounds (0 means no limit)
+	MaxDepth int
+	// Concurrency limits the number of expansions running at the same time (default 1)
+	Concurrency int
+	// Stop ends exploration once it returns true (optional)
+	Stop StopFunc
+	// ContinueOnError keeps exploring past failed expansions, Run then only fails when a whole level does.
+	// By default the first level with a failed expansion ends exploration with an error.
+	ContinueOnError bool
+}
+
+// Result holds everything found during exploration
+type Result struct {
+	Graph       *graph.Graph `json:"graph"`
+	Visited     []Entity     `json:"visited"`
+	Discoveries []Discovery  `json:"discoveries"`
+	Depth       int          `json:"depth"`
+	// Match is the discovery for which Stop returned true, nil if exploration ran to the end
+	Match *Discovery `json:"match,omitempty"`
+	// Errors are the failed expansions
+	Errors []error `json:"-"`
+}
+
+// Explorer runs a breadth-first exploration across entity types
+type Explorer struct {
+	config Config
+}
+
+// NewExplorer creates a new Explorer with the given config
+func NewExplorer(config Config) (*Explorer, error) {
+	if len(config.Expanders) == 0 {
+		return nil, fmt.Errorf("at least one expander is required")
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 1
+	}
+	if config.Normalize == nil {
+		config.Normalize = strings.TrimSpace
+	}
+	return &Explorer{config: config}, nil
+}
+
+type expansion struct {
+	from     Entity
+	entities []Entity
+	err      error
+}
+
+// Run explores the graph level by level until nothing new is found, the depth limit is hit or Stop returns true.
+// A failed expansion returns the result found so far with an error, see Config.ContinueOnError.
+func (e *Explorer) Run(ctx context.Context) (*Result, error) {
+	result := &Result{Graph: graph.NewGraph()}
+	visited := make(map[string]bool)
+
+	var frontier []Entity
+	for _, seed := range e.config.Seeds {
+		seed.Value = e.config.Normalize(seed.Value)
+		if seed.Value == "" || visited[seed.key()] {
+			continue
+		}
+		visited[seed.key()] = true
+		frontier = append(frontier, seed)
+		e.addNode(result.Graph, seed)
+	}
+
+	for depth := 1; len(frontier) > 0; depth++ {
+		if e.config.MaxDepth > 0 && depth > e.config.MaxDepth {
+			logger.Warn("max depth reached", "max_depth", e.config.MaxDepth)
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		logger.Info("expanding entities", "depth", depth, "entities", len(frontier))
+		result.Depth = depth
+		expansions := e.expandAll(ctx, frontier)
+
+		var next []Entity
+		var failed []error
+		for _, exp := range expansions {
+			result.Visited = append(result.Visited, exp.from)
+			if exp.err != nil {
+				logger.Error("error expanding entity", "entity", exp.from.key(), "error", exp.err)
+				failed = append(failed, fmt.Errorf("expanding %s: %w", exp.from.key(), exp.err))
+				continue
+			}
+
+			for _, found := range exp.entities {
+				found.Value = e.config.Normalize(found.Value)
+				if found.Value == "" {
+					continue
+				}
+
+				discovery := Discovery{From: exp.from, To: found, Depth: depth}
+				result.Discoveries = append(result.Discoveries, discovery)
+				e.addNode(result.Graph, found)
+				result.Graph.AddEdge(exp.from.key(), found.key(), e.relationship(exp.from.Type))
+
+				if result.Match == nil && e.config.Stop != nil && e.config.Stop(discovery) {
+					logger.Info("stop condition met", "from", exp.from.key(), "to", found.key())
+					result.Match = &discovery
+				}
+
+				if !visited[found.key()] {
+					visited[found.key()] = true
+					next = append(next, found)
+				}
+			}
+		}
+
+		result.Errors = append(result.Errors, failed...)
+		if len(failed) > 0 && (!e.config.ContinueOnError || len(failed) == len(expansions)) {
+			return result, fmt.Errorf("%d of %d expansions failed at depth %d: %w", len(failed), len(expansions), depth, errors.Join(failed...))
+		}
+		if result.Match != nil {
+			break
+		}
+		frontier = next
+	}
+
+	return result, nil
+}
+
+// expandAll expands the entities concurrently and returns results in the frontier order
+func (e *Explorer) expandAll(ctx context.Context, frontier []Entity) []expansion {
+	results := make([]expansion, len(frontier))
+	semaphore := make(chan struct{}, e.config.Concurrency)
+	var wg sync.WaitGroup
+
+	for i, entity := range frontier {
+		results[i].from = entity
+		expand, ok := e.config.Expanders[entity.Type]
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, entity Entity) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			results[i].entities, results[i].err = expand(ctx, entity.Value)
+		}(i, entity)
+	}
+
+	wg.Wait()
+	return results
+}
+
+func (e *Explorer) relationship(entityType string) string {
+	if name, ok := e.config.Relationships[entityType]; ok {
+		return name
+	}
+	return "CONNECTED_TO"
+}
+
+func (e *Explorer) addNode(g *graph.Graph, entity Entity) {
+	g.AddNode(graph.Node{ID: entity.key(), Key: entity.Value, Label: entity.Value, Type: entity.Type})
+}
+
+// Persist saves the discovered graph to Neo4j
+func (r *Result) Persist(ctx context.Context, graphSvc *graph.Service) error {
+	return graphSvc.SaveGraph(ctx, r.Graph)
+}
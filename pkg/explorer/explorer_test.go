@@ -0,0 +1,85 @@
+package explorer
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRunExpansionErrors(t *testing.T) {
+	outage := errors.New("service unavailable")
+	expanders := map[string]ExpandFunc{
+		"person": func(ctx context.Context, name string) ([]Entity, error) {
+			if name == "ADAM" {
+				return nil, outage
+			}
+			return []Entity{{Type: "place", Value: "KRAKOW"}}, nil
+		},
+		"place": func(ctx context.Context, city string) ([]Entity, error) {
+			return nil, nil
+		},
+	}
+
+	tests := []struct {
+		name            string
+		seeds           []Entity
+		continueOnError bool
+		wantErr         bool
+		wantErrors      int
+		wantVisited     int
+	}{
+		{"one failure stops by default", []Entity{{"person", "ADAM"}, {"person", "EWA"}}, false, true, 1, 2},
+		{"one failure continues", []Entity{{"person", "ADAM"}, {"person", "EWA"}}, true, false, 1, 3},
+		{"whole level failing always stops", []Entity{{"person", "ADAM"}}, true, true, 1, 1},
+		{"no failures", []Entity{{"person", "EWA"}}, false, false, 0, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewExplorer(Config{Seeds: tt.seeds, Expanders: expanders, ContinueOnError: tt.continueOnError})
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := e.Run(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, outage) {
+				t.Errorf("Run() error = %v, want it to wrap the expansion error", err)
+			}
+			if len(result.Errors) != tt.wantErrors {
+				t.Errorf("got %d errors, want %d", len(result.Errors), tt.wantErrors)
+			}
+			if len(result.Visited) != tt.wantVisited {
+				t.Errorf("got %d visited, want %d", len(result.Visited), tt.wantVisited)
+			}
+		})
+	}
+}
+
+func TestRunStopsAtMatch(t *testing.T) {
+	e, err := NewExplorer(Config{
+		Seeds: []Entity{{"person", "BARBARA"}},
+		Expanders: map[string]ExpandFunc{
+			"person": func(ctx context.Context, name string) ([]Entity, error) {
+				return []Entity{{"place", "ELBLAG"}}, nil
+			},
+			"place": func(ctx context.Context, city string) ([]Entity, error) {
+				return []Entity{{"person", "BARBARA"}}, nil
+			},
+		},
+		Stop: func(d Discovery) bool { return d.From.Type == "place" },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := e.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Match == nil || result.Match.From.Value != "ELBLAG" {
+		t.Fatalf("got match %+v, want ELBLAG", result.Match)
+	}
+	if result.Graph.GetNode("place:ELBLAG") == nil {
+		t.Error("ELBLAG missing from the graph")
+	}
+}
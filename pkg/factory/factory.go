@@ -11,44 +11,43 @@ import (
 	"github.com/yeka/zip"
 
 	"github.com/crowmw/ai_devs3/pkg/ai"
+	"github.com/crowmw/ai_devs3/pkg/c3ntrala"
 	"github.com/crowmw/ai_devs3/pkg/env"
-	"github.com/crowmw/ai_devs3/pkg/http"
+	"github.com/crowmw/ai_devs3/pkg/logging"
 	"github.com/crowmw/ai_devs3/pkg/processor"
 	"github.com/otiai10/gosseract/v2"
 	"github.com/sashabaranov/go-openai"
 )
 
+var logger = logging.New("factory")
+
 // Factory represents a factory with its files
 type Factory struct {
 	DirPath string
+	aiSvc   *ai.Service
 }
 
 // NewFactory creates a new Factory instance and initializes it with files
-func NewFactory(envSvc *env.Service) (*Factory, error) {
+func NewFactory(envSvc *env.Service, aiSvc *ai.Service) (*Factory, error) {
 	factory := &Factory{
 		DirPath: "pliki_z_fabryki",
+		aiSvc:   aiSvc,
 	}
 
 	// Check if directory exists
 	if _, err := os.Stat(factory.DirPath); err == nil {
-		fmt.Println("Factory files directory already exists, skipping download")
+		logger.Info("factory files directory already exists, skipping download", "dir", factory.DirPath)
 		return factory, nil
 	}
 
 	// Download zip file
-	fmt.Println("Downloading factory files...")
-	zipData, err := http.FetchData(envSvc.GetC3ntralaURL() + "/data/" + envSvc.GetMyAPIKey() + "/pliki_z_fabryki.zip")
-	if err != nil {
+	logger.Info("downloading factory files")
+	resources := c3ntrala.NewResources(envSvc.GetC3ntralaURL(), envSvc.GetMyAPIKey(), "")
+	if _, err := resources.ExtractZip(c3ntrala.ResourceFactoryFiles, factory.DirPath); err != nil {
 		return nil, fmt.Errorf("error downloading factory files: %w", err)
 	}
 
-	// Extract files
-	fmt.Println("Extracting factory files...")
-	if err := processor.ExtractZipToDirectory(zipData, factory.DirPath); err != nil {
-		return nil, fmt.Errorf("error extracting factory files: %w", err)
-	}
-
-	fmt.Println("Factory files downloaded and extracted successfully")
+	logger.Info("factory files downloaded and extracted")
 	return factory, nil
 }
 
@@ -130,11 +129,11 @@ func (f *Factory) GetFactFileKeywords(fact FactoryFileContent) (string, error) {
 	if keywords, exists, err := f.getExistingKeywords(filePath); err != nil {
 		return "", err
 	} else if exists {
-		fmt.Println("🔍 Keywords already exist for:", fact.File)
+		logger.Debug("keywords already exist", "file", fact.File)
 		return keywords, nil
 	}
 
-	fmt.Println("🔍 Extracting keywords for:", fact.File)
+	logger.Info("extracting keywords", "file", fact.File)
 	prompt := []openai.ChatCompletionMessage{
 		{
 			Role:    openai.ChatMessageRoleSystem,
@@ -146,7 +145,7 @@ func (f *Factory) GetFactFileKeywords(fact FactoryFileContent) (string, error) {
 		},
 	}
 
-	aiResponse, err := ai.SendChatCompletion("gpt-4o-mini", false, prompt)
+	aiResponse, err := f.aiSvc.SendChatCompletion("gpt-4o-mini", false, prompt)
 	if err != nil {
 		return "", fmt.Errorf("error getting AI analysis: %w", err)
 	}
@@ -184,14 +183,14 @@ func (f *Factory) GetFactsFilesKeywords() (string, error) {
 
 // ExtractKeyInformation extracts key information from a text file using AI analysis
 func (f *Factory) ExtractKeyInformation(content FactoryFileContent) (FactoryFileContent, error) {
-	fmt.Println("🔍 Extracting key information from:", content.File)
+	logger.Info("extracting key information", "file", content.File)
 	filePath := filepath.Join(f.DirPath, content.File)
 
 	// Try to read existing keywords file
 	if keywords, exists, err := f.getExistingKeywords(filePath); err != nil {
 		return FactoryFileContent{}, err
 	} else if exists {
-		fmt.Println("🔍 Keywords already exist for:", content.File)
+		logger.Debug("keywords already exist", "file", content.File)
 		content.Content = content.Content + "\n\nKey information:\n" + keywords
 		return content, nil
 	}
@@ -212,7 +211,7 @@ func (f *Factory) ExtractKeyInformation(content FactoryFileContent) (FactoryFile
 		},
 	}
 
-	result, err := ai.SendChatCompletion("gpt-4o-mini", false, prompt)
+	result, err := f.aiSvc.SendChatCompletion("gpt-4o-mini", false, prompt)
 	if err != nil {
 		return FactoryFileContent{}, fmt.Errorf("error getting AI analysis: %w", err)
 	}
@@ -255,21 +254,19 @@ type ReportAnalysis map[string]string
 
 // AnalyzeReports analyzes all reports and returns a map of report files and their analyzed keywords
 func (f *Factory) AnalyzeReports() (ReportAnalysis, error) {
-	fmt.Println("🔍 Analyzing reports...")
+	logger.Info("analyzing reports")
 	reports, err := f.GetReportFiles()
-	fmt.Println("\n\n🔍 Reports:\n\n", reports)
 	if err != nil {
 		return nil, fmt.Errorf("error getting report files: %w", err)
 	}
 
-	fmt.Println("🔍 Getting facts files keywords...")
+	logger.Info("getting facts files keywords", "reports", len(reports))
 	facts, err := f.GetFactsFilesKeywords()
-	fmt.Println("\n\n🔍 Facts files keywords:\n\n", facts)
 	if err != nil {
 		return nil, fmt.Errorf("error getting facts files: %w", err)
 	}
+	logging.Dump(logger, "facts files keywords", "facts", fmt.Sprint(facts))
 
-	fmt.Println("🔍 Analyzing reports...")
 	reportAnalyses := make(ReportAnalysis)
 	var allReportsContent string
 	for _, report := range reports {
@@ -287,15 +284,11 @@ func (f *Factory) AnalyzeReports() (ReportAnalysis, error) {
 		},
 	}
 
-	fmt.Println("\n\n🔍 Prompt:\n\n", prompt)
-
-	aiResponse, err := ai.SendChatCompletion("gpt-4.1", false, prompt)
+	aiResponse, err := f.aiSvc.SendChatCompletion("gpt-4.1", false, prompt)
 	if err != nil {
 		return nil, fmt.Errorf("error sending chat completion: %w", err)
 	}
 
-	fmt.Println("\n\n🔍 AI response:\n\n", aiResponse)
-
 	var response struct {
 		Thinking    string            `json:"_thinking"`
 		FinalResult map[string]string `json:"finalResult"`
@@ -467,7 +460,7 @@ func (f *Factory) GetAudioFilesTexts() ([]FactoryFileContent, error) {
 	// Process each audio file
 	for _, audio := range audios {
 		// Transcribe the audio file
-		transcriptionPath, err := processor.TranscribeAudioFile(f.DirPath+"/"+audio.File, filepath.Join(f.DirPath, "transcriptions"))
+		transcriptionPath, err := processor.TranscribeAudioFile(f.aiSvc, f.DirPath+"/"+audio.File, filepath.Join(f.DirPath, "transcriptions"))
 		if err != nil {
 			return nil, fmt.Errorf("error transcribing file %s: %w", audio.File, err)
 		}
@@ -492,7 +485,7 @@ func (f *Factory) LoadWeaponTests() error {
 	// Check if do-not-share directory exists
 	doNotSharePath := filepath.Join(f.DirPath, "do-not-share")
 	if _, err := os.Stat(doNotSharePath); err == nil {
-		fmt.Println("do-not-share directory already exists")
+		logger.Info("do-not-share directory already exists")
 		return nil
 	}
 
@@ -557,7 +550,7 @@ type WeaponTest struct {
 }
 
 func (f *Factory) GetWeaponTests() ([]WeaponTest, error) {
-	fmt.Println("🔍 Reading do-not-share files...")
+	logger.Info("reading do-not-share files")
 	doNotSharePath := filepath.Join(f.DirPath, "do-not-share")
 
 	// Check if directory exists, if not run LoadWeaponTests
@@ -576,7 +569,7 @@ func (f *Factory) GetWeaponTests() ([]WeaponTest, error) {
 	var result []WeaponTest
 
 	for _, file := range files {
-		fmt.Println("🔍 Reading file:", file.Name())
+		logger.Debug("reading file", "file", file.Name())
 		if file.IsDir() {
 			continue
 		}
@@ -1,22 +1,20 @@
 package gps_agent
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
-)
 
-func getToolsPrompt(state *State) string {
-	fmt.Println("\n📝 [PROMPTS] Generating tools prompt...")
+	"github.com/crowmw/ai_devs3/pkg/agent"
+	"github.com/crowmw/ai_devs3/pkg/logging"
+)
 
-	var toolsList string
-	for _, tool := range state.Config.Tools {
-		toolsList += fmt.Sprintf("<tool name=\"%s\">%s</tool>\n", tool.Name, tool.Description)
-	}
+// PromptVersion names the prompts below in eval reports, bump it with every prompt change
+const PromptVersion = "gps-v1"
 
+func getToolsPrompt(state *agent.State) string {
 	currentDateTime := time.Now().Format(time.RFC3339)
-	fmt.Printf("✅ [PROMPTS] Tools prompt generated with %d tools\n", len(state.Config.Tools))
-	return fmt.Sprintf(toolsPrompt, currentDateTime, toolsList)
+	logger.Debug("tools prompt generated", "tools", len(state.Tools))
+	return fmt.Sprintf(toolsPrompt, currentDateTime, agent.FormatTools(state.Tools))
 }
 
 const toolsPrompt = `Your task is to analyze the conversation context and generate relevant queries for using available tools.
@@ -84,21 +82,9 @@ Before delivering ANY output:
 This prompt is designed to create an internal dialogue while analyzing conversations. It processes the conversation context and generates appropriate, independent queries for each relevant tool. The output focuses on utilizing available tools effectively, avoiding assumptions about unavailable tools, and ensures all queries are independent and can be executed concurrently.
 </confirmation>`
 
-func getTaskThoughtsPrompt(state *State) string {
-	fmt.Println("\n📝 [PROMPTS] Generating task thoughts prompt...")
-
-	var tasksList string
-	for _, task := range state.Tasks {
-		tasksList += fmt.Sprintf("<task uuid=\"%s\" name=\"%s\" status=\"%s\">\n<description>%s</description>\n</task>\n", task.Uuid, task.Name, task.Status, task.Description)
-	}
-
-	var toolsList string
-	for _, tool := range state.Tools {
-		toolsList += fmt.Sprintf("<tool name=\"%s\">%s</tool>\n", tool.Name, tool.Description)
-	}
-
-	fmt.Printf("✅ [PROMPTS] Task thoughts prompt generated with %d tasks and %d tools\n", len(state.Tasks), len(state.Tools))
-	return fmt.Sprintf(taskThoughtsPrompt, state.Thoughts.Tools, toolsList, tasksList)
+func getTaskThoughtsPrompt(state *agent.State) string {
+	logger.Debug("task thoughts prompt generated", "tasks", len(state.Tasks), "tools", len(state.Tools))
+	return fmt.Sprintf(taskThoughtsPrompt, state.Thoughts.Tools, agent.FormatTools(state.Tools), agent.FormatTaskList(state.Tasks))
 }
 
 const taskThoughtsPrompt = `
@@ -131,6 +117,7 @@ Analyze the conversation context, including the user's latest request, completed
 - Task names MUST be descriptive and use underscores
 - Task descriptions MUST be precise and actionable
 - NEVER modify completed tasks
+- A pending task with <failed_action> entries failed before, read the errors and update its description so the next attempt can succeed
 - NEVER create duplicate tasks
 - NEVER create tasks with empty descriptions
 - NEVER create tasks for BARBARA
@@ -238,32 +225,16 @@ This prompt is designed to analyze ongoing conversations with the user and maint
 Is this revised prompt aligned with your requirements for maintaining and updating tasks based on the ongoing conversation and existing task list, while ensuring user communication as the final step and incorporating long-term memory searches when necessary?
 </confirmation>`
 
-func getActionThoughtsPrompt(state *State) string {
-	fmt.Println("\n📝 [PROMPTS] Generating action thoughts prompt...")
-
-	var toolsList string
-	for _, tool := range state.Tools {
-		toolsList += fmt.Sprintf("<tool name=\"%s\">%s</tool>\n", tool.Name, tool.Description)
-	}
-
-	var tasksList string
-	for _, task := range state.Tasks {
-		var actionsList string
-		for _, action := range task.Actions {
-			actionsList += fmt.Sprintf("<action name=\"%s\" tool=\"%s\" status=\"%s\"><result>%s</result></action>\n", action.Description, action.ToolName, action.Status, action.Result)
-		}
-		tasksList += fmt.Sprintf("<task uuid=\"%s\" name=\"%s\" status=\"%s\"><description>%s</description><actions>%s</actions></task>\n", task.Uuid, task.Name, task.Status, task.Description, actionsList)
-	}
-
-	fmt.Printf("✅ [PROMPTS] Action thoughts prompt generated with %d tasks\n", len(state.Tasks))
-	return fmt.Sprintf(actionThoughtsPrompt, toolsList, tasksList)
+func getActionThoughtsPrompt(state *agent.State) string {
+	logger.Debug("action thoughts prompt generated", "tasks", len(state.Tasks))
+	return fmt.Sprintf(actionThoughtsPrompt, agent.FormatTools(state.Tools), agent.FormatTaskActions(state.Tasks))
 }
 
 const actionThoughtsPrompt = `
 You are an AI assistant responsible for determining the next immediate action to take based on the ongoing conversation and current tasks. Your goal is to decide on the most appropriate next step.
 
 <prompt_objective>
-Analyze the current tasks and their dependencies to determine the next action. Select a tool and associate it with the relevant task. Output a JSON object containing your reasoning and a detailed action object.
+Analyze the current tasks and their dependencies to determine the next action. Select a tool and associate it with the relevant task. Output a JSON object containing your reasoning and a detailed action object, or an array of action objects when several pending tasks are independent of each other.
 </prompt_objective>
 
 <prompt_rules>
@@ -272,6 +243,8 @@ Analyze the current tasks and their dependencies to determine the next action. S
   - "description": Clear action description
   - "tool_name": One of the available tools
   - "task_uuid": UUID of the associated task
+- The "result" property MAY be an array of such objects, one per task, when the tasks do not depend on each other or on pending results, e.g. person_id_finder for every name or gps for every ID already obtained. The actions run at the same time
+- NEVER put final_answer in an array, it runs alone after all other tasks
 - Follow strict task order:
   1. person_finder first
   2. person_id_finder for EACH name (except BARBARA)
@@ -307,6 +280,15 @@ GOOD EXAMPLE (with multiple names):
   }
 }
 
+GOOD EXAMPLE (independent tasks at once):
+{
+  "_thinking": "person_finder returned RAFAL and AZAZEL, their IDs do not depend on each other",
+  "result": [
+    {"description": "Get ID for person named RAFAL", "tool_name": "person_id_finder", "task_uuid": "task_234"},
+    {"description": "Get ID for person named AZAZEL", "tool_name": "person_id_finder", "task_uuid": "task_345"}
+  ]
+}
+
 BAD EXAMPLE - NEVER DO THIS:
 {
   "_thinking": "Getting GPS location",
@@ -353,21 +335,19 @@ Before delivering ANY output:
 </execution_validation>
 `
 
-func (s *Service) getUseThoughtsPrompt() string {
-	fmt.Println("\n📝 [PROMPTS] Generating use thoughts prompt...")
-
-	currentTask := s.getCurrentTask()
-	currentAction := s.getCurrentAction(currentTask)
+func getUseThoughtsPrompt(state *agent.State) string {
+	currentAction := state.CurrentAction(state.CurrentTask())
 	if currentAction == nil {
-		fmt.Println("⚠️ [PROMPTS] No current action found, using default values")
-		return fmt.Sprintf(useThoughtsPrompt, "unknown", "unknown", formatTasks(s.State.Tasks))
+		logger.Warn("no current action found, using default values for use thoughts prompt")
+		return fmt.Sprintf(useThoughtsPrompt, "unknown", "unknown", "", agent.FormatTasks(state.Tasks))
 	}
 
-	fmt.Printf("✅ [PROMPTS] Use thoughts prompt generated for tool '%s'\n", currentAction.ToolName)
+	logger.Debug("use thoughts prompt generated", logging.KeyTool, currentAction.ToolName)
 	return fmt.Sprintf(useThoughtsPrompt,
-		currentAction.ToolName,     // Current tool name
-		currentAction.Description,  // Action description
-		formatTasks(s.State.Tasks), // Task history
+		currentAction.ToolName,    // Current tool name
+		currentAction.Description, // Action description
+		agent.FormatToolPayload(state.Tools, currentAction.ToolName), // Payload schema
+		agent.FormatTasks(state.Tasks),                               // Task history
 	)
 }
 
@@ -377,12 +357,14 @@ You are preparing to execute a tool action. Your task is to generate a valid pay
 <current_action>
 Tool: %s
 Description: %s
+Payload: %s
 </current_action>
 
 <prompt_rules>
 - Output MUST be a valid JSON object with "_thinking" and "result" properties
 - The "_thinking" property MUST contain your detailed reasoning process
 - The "result" property MUST match the exact format required by the tool
+- If an earlier attempt of this action failed with an invalid payload, fix the payload as its result describes
 - NEVER modify names returned by person_finder - use them exactly as returned
 - NEVER include names that weren't returned by person_finder
 - For person_id_finder:
@@ -510,29 +492,3 @@ Before returning:
 %s
 </performed_tasks>
 `
-
-func formatTasks(tasks []Task) string {
-	var result string
-	for _, task := range tasks {
-		result += fmt.Sprintf("<task name=\"%s\" status=\"%s\">\n", task.Name, task.Status)
-		result += fmt.Sprintf("<description>%s</description>\n", task.Description)
-
-		for _, action := range task.Actions {
-			result += fmt.Sprintf("<action tool=\"%s\" status=\"%s\">\n", action.ToolName, action.Status)
-			if action.Result != nil {
-				var resultStr string
-				switch v := action.Result.Data.(type) {
-				case string:
-					resultStr = v
-				default:
-					resultBytes, _ := json.Marshal(v)
-					resultStr = string(resultBytes)
-				}
-				result += fmt.Sprintf("<result>%s</result>\n", resultStr)
-			}
-			result += "</action>\n"
-		}
-		result += "</task>\n"
-	}
-	return result
-}
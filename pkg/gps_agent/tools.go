@@ -4,65 +4,42 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"github.com/crowmw/ai_devs3/pkg/config"
+	"github.com/crowmw/ai_devs3/pkg/agent"
 	"github.com/crowmw/ai_devs3/pkg/http"
+	"github.com/crowmw/ai_devs3/pkg/logging"
 )
 
-// GetTools returns the list of available tools
-func getTools() []Tool {
-	return []Tool{
-		{
-			Name:        "person_finder",
-			Description: "Returns an array of person names who were seen in a specified city",
-			Instruction: `Provide a JSON payload with "city" field containing the name of the city you want to get persons list for, like: {"city": "Warszawa"}. This will return an array of person names who were seen in that city.`,
-		},
-		{
-			Name:        "person_id_finder",
-			Description: "Returns the userID for a given person's name. Takes a name as input and returns the corresponding userID.",
-			Instruction: `Provide a JSON payload with "name" field containing the name of the person you want to get ID for, like: {"name": "Azazel"}`,
-		},
-		{
-			Name:        "gps",
-			Description: "Returns GPS coordinates for a person when given their userID. STRICTLY ONE numeric userID per call. Do NOT include lat/lon or multiple IDs.",
-			Instruction: `Provide a JSON payload with ONLY "userID" field containing ONE numeric ID, like: {"userID": "69"}. NEVER include any other keys (e.g., "answer", "lat", "lon"). NEVER send arrays or comma-separated IDs.`,
-		},
-		{
-			Name:        "final_answer",
-			Description: "Use this to answer the user question",
-			Instruction: `Provide a JSON payload with "answer" field containing the original question from the user, like: {"answer": "Where was John last seen?"}`,
-		},
-	}
+type GpsPayload struct {
+	UserID string `json:"userID" description:"ONE numeric userID returned by person_id_finder, e.g. \"69\". Never a name, an array or comma-separated IDs"`
 }
 
-func (s *Service) handlePersonFinder(payload map[string]interface{}) (ToolResult, error) {
-	fmt.Println("\n🔍 [PERSON_FINDER] Starting search for people...")
+type PersonIDFinderPayload struct {
+	Name string `json:"name" description:"exact person name as returned by person_finder, e.g. \"AZAZEL\""`
+}
 
-	var personFinderPayload PersonFinderPayload
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		fmt.Printf("❌ [PERSON_FINDER] Error marshaling payload: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Data:   fmt.Sprintf("error marshaling payload: %v", err),
-		}, err
-	}
+type PersonFinderPayload struct {
+	City string `json:"city" description:"name of the city, e.g. \"Warszawa\""`
+}
 
-	if err := json.Unmarshal(payloadBytes, &personFinderPayload); err != nil {
-		fmt.Printf("❌ [PERSON_FINDER] Error unmarshaling payload: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Data:   fmt.Sprintf("error unmarshaling payload: %v", err),
-		}, err
+// getTools returns the list of available tools
+func (s *Service) getTools() []agent.Tool {
+	return []agent.Tool{
+		agent.NewTool("person_finder", "Returns an array of person names who were seen in a specified city", s.handlePersonFinder),
+		agent.NewTool("person_id_finder", "Returns the userID for a given person's name. Takes a name as input and returns the corresponding userID.", s.handlePersonIDFinder),
+		agent.NewTool("gps", "Returns GPS coordinates for a person when given their userID. STRICTLY ONE numeric userID per call. Do NOT include lat/lon or multiple IDs.", s.handleGps),
+		agent.FinalAnswerTool(),
 	}
+}
+
+func (s *Service) handlePersonFinder(personFinderPayload PersonFinderPayload) (agent.ToolResult, error) {
+	log := logger.With(logging.KeyTool, "person_finder")
+	log.Info("starting search for people")
 
-	fmt.Printf("📍 [PERSON_FINDER] Searching in city: %s\n", personFinderPayload.City)
+	log.Info("searching in city", "city", personFinderPayload.City)
 	persons, err := s.c3ntralaSvc.GetWhoWasSeenThere(personFinderPayload.City)
 	if err != nil {
-		fmt.Printf("❌ [PERSON_FINDER] Error getting people: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Data:   err.Error(),
-		}, err
+		log.Error("error getting people", "error", err)
+		return agent.ErrorResult(err), err
 	}
 
 	type Result struct {
@@ -71,52 +48,32 @@ func (s *Service) handlePersonFinder(payload map[string]interface{}) (ToolResult
 	}
 
 	Data := Result{City: personFinderPayload.City, Persons: persons}
-	fmt.Printf("✅ [PERSON_FINDER] Found %d people in %s: %v\n", len(persons), personFinderPayload.City, persons)
+	log.Info("found people", "city", personFinderPayload.City, "count", len(persons), "people", persons)
 
-	return ToolResult{
-		Status: "success",
+	return agent.ToolResult{
+		Status: agent.ToolSuccess,
 		Data:   Data,
 	}, nil
 }
 
-func (s *Service) handlePersonIDFinder(payload map[string]interface{}) (ToolResult, error) {
-	fmt.Println("\n🆔 [PERSON_ID_FINDER] Starting ID lookup...")
-
-	var personIDFinderPayload PersonIDFinderPayload
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		fmt.Printf("❌ [PERSON_ID_FINDER] Error marshaling payload: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Data:   fmt.Sprintf("error marshaling payload: %v", err),
-		}, err
-	}
-
-	if err := json.Unmarshal(payloadBytes, &personIDFinderPayload); err != nil {
-		fmt.Printf("❌ [PERSON_ID_FINDER] Error unmarshaling payload: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Data:   fmt.Sprintf("error unmarshaling payload: %v", err),
-		}, err
-	}
+func (s *Service) handlePersonIDFinder(personIDFinderPayload PersonIDFinderPayload) (agent.ToolResult, error) {
+	log := logger.With(logging.KeyTool, "person_id_finder")
+	log.Info("starting ID lookup")
 
 	if personIDFinderPayload.Name == "" {
-		fmt.Println("❌ [PERSON_ID_FINDER] Error: Name parameter is required")
-		return ToolResult{
-			Status: "error",
-			Data:   fmt.Sprintf("You need to provide only parameter 'name' with person name in payload"),
-		}, err
+		log.Error("name parameter is required")
+		return agent.ToolResult{
+			Status: agent.ToolError,
+			Data:   "You need to provide only parameter 'name' with person name in payload",
+		}, nil
 	}
 
-	fmt.Printf("👤 [PERSON_ID_FINDER] Looking up ID for: %s\n", personIDFinderPayload.Name)
+	log.Info("looking up ID", "name", personIDFinderPayload.Name)
 	query := fmt.Sprintf(`SELECT id FROM users WHERE username = "%s"`, personIDFinderPayload.Name)
 	result, err := http.PostSQLQueryToAPIDB(s.envSvc, query)
 	if err != nil {
-		fmt.Printf("❌ [PERSON_ID_FINDER] Database error: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Data:   err.Error(),
-		}, err
+		log.Error("database error", "error", err)
+		return agent.ErrorResult(err), err
 	}
 
 	type Reply struct {
@@ -127,11 +84,13 @@ func (s *Service) handlePersonIDFinder(payload map[string]interface{}) (ToolResu
 		Error string  `json:"error"`
 	}
 	if err := json.Unmarshal([]byte(result), &data); err != nil {
-		fmt.Printf("❌ [PERSON_ID_FINDER] Error parsing database response: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Data:   fmt.Sprintf("error unmarshaling payload: %v", err),
-		}, err
+		log.Error("error parsing database response", "error", err)
+		return agent.ErrorResult(err), err
+	}
+	if len(data.Reply) == 0 {
+		err := fmt.Errorf("no user named %s", personIDFinderPayload.Name)
+		log.Error("user not found", "name", personIDFinderPayload.Name)
+		return agent.ErrorResult(err), err
 	}
 
 	type Result struct {
@@ -140,43 +99,23 @@ func (s *Service) handlePersonIDFinder(payload map[string]interface{}) (ToolResu
 	}
 
 	Data := Result{Name: personIDFinderPayload.Name, UserID: data.Reply[0].ID}
-	fmt.Printf("✅ [PERSON_ID_FINDER] Found ID for %s: %s\n", Data.Name, Data.UserID)
+	log.Info("found ID", "name", Data.Name, "user_id", Data.UserID)
 
-	return ToolResult{
-		Status: "success",
+	return agent.ToolResult{
+		Status: agent.ToolSuccess,
 		Data:   Data,
 	}, nil
 }
 
-func (s *Service) handleGps(payload map[string]interface{}) (ToolResult, error) {
-	fmt.Println("\n📍 [GPS] Starting location lookup...")
+func (s *Service) handleGps(gpsPayload GpsPayload) (agent.ToolResult, error) {
+	log := logger.With(logging.KeyTool, "gps")
+	log.Info("starting location lookup")
 
-	var gpsPayload GpsPayload
-	payloadBytes, err := json.Marshal(payload)
+	log.Info("looking up coordinates", "user_id", gpsPayload.UserID)
+	result, err := http.SendJSONPost(s.envSvc.GetC3ntralaURL()+"/gps", map[string]string{"userID": gpsPayload.UserID})
 	if err != nil {
-		fmt.Printf("❌ [GPS] Error marshaling payload: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Data:   fmt.Sprintf("error marshaling payload: %v", err),
-		}, err
-	}
-
-	if err := json.Unmarshal(payloadBytes, &gpsPayload); err != nil {
-		fmt.Printf("❌ [GPS] Error unmarshaling payload: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Data:   fmt.Sprintf("error unmarshaling payload: %v", err),
-		}, err
-	}
-
-	fmt.Printf("🔍 [GPS] Looking up coordinates for userID: %s\n", gpsPayload.UserID)
-	result, err := http.SendJSONPost(config.GetC3ntralaURL()+"/gps", map[string]string{"userID": gpsPayload.UserID})
-	if err != nil {
-		fmt.Printf("❌ [GPS] API error: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Data:   err.Error(),
-		}, err
+		log.Error("API error", "error", err)
+		return agent.ErrorResult(err), err
 	}
 
 	var data struct {
@@ -187,11 +126,8 @@ func (s *Service) handleGps(payload map[string]interface{}) (ToolResult, error)
 		} `json:"message"`
 	}
 	if err := json.Unmarshal([]byte(result), &data); err != nil {
-		fmt.Printf("❌ [GPS] Error parsing API response: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Data:   fmt.Sprintf("error unmarshaling payload: %v", err),
-		}, err
+		log.Error("error parsing API response", "error", err)
+		return agent.ErrorResult(err), err
 	}
 
 	type Result struct {
@@ -201,65 +137,10 @@ func (s *Service) handleGps(payload map[string]interface{}) (ToolResult, error)
 	}
 
 	Data := Result{UserID: gpsPayload.UserID, Lat: data.Message.Lat, Long: data.Message.Lon}
-	fmt.Printf("✅ [GPS] Found location for userID %s: lat=%f, lon=%f\n", Data.UserID, Data.Lat, Data.Long)
+	log.Info("found location", "user_id", Data.UserID, "lat", Data.Lat, "lon", Data.Long)
 
-	return ToolResult{
-		Status: "success",
+	return agent.ToolResult{
+		Status: agent.ToolSuccess,
 		Data:   Data,
 	}, nil
 }
-
-func (s *Service) handleFinalAnswer(payload map[string]interface{}) (ToolResult, error) {
-	fmt.Println("\n🎯 [FINAL_ANSWER] Preparing final response...")
-	fmt.Printf("📝 [FINAL_ANSWER] Original question: %s\n", s.State.UserMessage)
-
-	// First try to get answer from result.answer structure
-	result, hasResult := payload["result"]
-	if hasResult {
-		resultMap, ok := result.(map[string]interface{})
-		if !ok {
-			return ToolResult{
-				Status: "error",
-				Data:   "'result' field must be an object",
-			}, fmt.Errorf("'result' field must be an object")
-		}
-
-		answer, hasAnswer := resultMap["answer"]
-		if !hasAnswer {
-			return ToolResult{
-				Status: "error",
-				Data:   "missing 'answer' field in result",
-			}, fmt.Errorf("missing 'answer' field in result")
-		}
-
-		fmt.Printf("✅ [FINAL_ANSWER] Generated response: %s\n", prettyPrint(answer))
-		return ToolResult{
-			Status: "success",
-			Data:   answer,
-		}, nil
-	}
-
-	// If no result wrapper, try direct answer field
-	answer, hasAnswer := payload["answer"]
-	if !hasAnswer {
-		return ToolResult{
-			Status: "error",
-			Data:   "missing either 'result.answer' or 'answer' field in payload",
-		}, fmt.Errorf("missing either 'result.answer' or 'answer' field in payload")
-	}
-
-	fmt.Printf("✅ [FINAL_ANSWER] Generated response: %s\n", prettyPrint(answer))
-	return ToolResult{
-		Status: "success",
-		Data:   answer,
-	}, nil
-}
-
-func (s *Service) getToolHandlers() map[string]ToolHandler {
-	return map[string]ToolHandler{
-		"gps":              s.handleGps,
-		"person_id_finder": s.handlePersonIDFinder,
-		"person_finder":    s.handlePersonFinder,
-		"final_answer":     s.handleFinalAnswer,
-	}
-}
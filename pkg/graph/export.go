@@ -0,0 +1,312 @@
+package graph
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Format is a supported graph export format
+type Format string
+
+const (
+	FormatGraphML Format = "graphml"
+	FormatDOT     Format = "dot"
+	FormatJSON    Format = "json"
+)
+
+// ExportOptions controls what part of the graph is exported and what gets highlighted
+type ExportOptions struct {
+	// Center limits the export to the subgraph around this node (ID or label)
+	Center string
+	// Depth is the number of hops around Center to include (default 1)
+	Depth int
+	// Path is a list of node IDs or labels to highlight, e.g. a found shortest path
+	Path []string
+}
+
+// prepare applies subgraph selection and resolves the highlighted path to node IDs and edges
+func (g *Graph) prepare(opts ExportOptions) (*Graph, map[string]bool, map[[2]string]bool) {
+	out := g.clone()
+	if opts.Center != "" {
+		depth := opts.Depth
+		if depth <= 0 {
+			depth = 1
+		}
+		out = g.Subgraph(opts.Center, depth)
+		// Keep the highlighted path visible even if it leaves the subgraph
+		for _, step := range opts.Path {
+			if node := g.FindNode(step); node != nil {
+				out.AddNode(*node)
+			}
+		}
+	}
+
+	highlightedNodes := make(map[string]bool)
+	highlightedEdges := make(map[[2]string]bool)
+	var previous string
+	for _, step := range opts.Path {
+		node := out.FindNode(step)
+		if node == nil {
+			previous = ""
+			continue
+		}
+		highlightedNodes[node.ID] = true
+		if previous != "" {
+			highlightedEdges[[2]string{previous, node.ID}] = true
+			highlightedEdges[[2]string{node.ID, previous}] = true
+			if !out.hasEdgeBetween(previous, node.ID) {
+				out.AddEdge(previous, node.ID, "PATH")
+			}
+		}
+		previous = node.ID
+	}
+
+	return out, highlightedNodes, highlightedEdges
+}
+
+func (g *Graph) hasEdgeBetween(a, b string) bool {
+	for _, edge := range g.Edges {
+		if (edge.From == a && edge.To == b) || (edge.From == b && edge.To == a) {
+			return true
+		}
+	}
+	return false
+}
+
+// Export writes the graph in the given format
+func (g *Graph) Export(w io.Writer, format Format, opts ExportOptions) error {
+	switch format {
+	case FormatGraphML:
+		return g.WriteGraphML(w, opts)
+	case FormatDOT:
+		return g.WriteDOT(w, opts)
+	case FormatJSON:
+		return g.WriteJSON(w, opts)
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// ExportToFile writes the graph to a file, picking the format from the file extension
+func (g *Graph) ExportToFile(path string, opts ExportOptions) error {
+	format := Format(strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), "."))
+	if format == "gv" {
+		format = FormatDOT
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating directory: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating export file: %w", err)
+	}
+	defer f.Close()
+
+	if err := g.Export(f, format, opts); err != nil {
+		return err
+	}
+	logger.Info("graph exported", "path", path, "format", format)
+	return nil
+}
+
+// WriteDOT writes the graph in Graphviz DOT format
+func (g *Graph) WriteDOT(w io.Writer, opts ExportOptions) error {
+	out, highlightedNodes, highlightedEdges := g.prepare(opts)
+
+	var b strings.Builder
+	b.WriteString("digraph G {\n")
+	b.WriteString("  node [shape=ellipse];\n")
+	for _, node := range out.Nodes {
+		attrs := []string{"label=" + dotQuote(node.Label)}
+		if node.Type == "place" {
+			attrs = append(attrs, "shape=box")
+		}
+		if highlightedNodes[node.ID] {
+			attrs = append(attrs, "color=red", "style=bold")
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(node.ID), strings.Join(attrs, ", "))
+	}
+	for _, edge := range out.Edges {
+		attrs := []string{"label=" + dotQuote(edge.Type)}
+		if highlightedEdges[[2]string{edge.From, edge.To}] {
+			attrs = append(attrs, "color=red", "penwidth=2")
+		}
+		fmt.Fprintf(&b, "  %s -> %s [%s];\n", dotQuote(edge.From), dotQuote(edge.To), strings.Join(attrs, ", "))
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   struct {
+		ID          string        `xml:"id,attr"`
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphMLNode `xml:"node"`
+		Edges       []graphMLEdge `xml:"edge"`
+	} `xml:"graph"`
+}
+
+// WriteGraphML writes the graph in GraphML format
+func (g *Graph) WriteGraphML(w io.Writer, opts ExportOptions) error {
+	out, highlightedNodes, highlightedEdges := g.prepare(opts)
+
+	doc := graphMLDocument{Xmlns: "http://graphml.graphdrawing.org/xmlns"}
+	doc.Keys = []graphMLKey{
+		{ID: "label", For: "node", Name: "label", Type: "string"},
+		{ID: "type", For: "node", Name: "type", Type: "string"},
+		{ID: "highlighted", For: "node", Name: "highlighted", Type: "boolean"},
+		{ID: "relationship", For: "edge", Name: "relationship", Type: "string"},
+		{ID: "edge_highlighted", For: "edge", Name: "highlighted", Type: "boolean"},
+	}
+
+	propertyKeys := out.propertyKeys()
+	for _, key := range propertyKeys {
+		doc.Keys = append(doc.Keys, graphMLKey{ID: "prop_" + key, For: "node", Name: key, Type: "string"})
+	}
+
+	doc.Graph.ID = "G"
+	doc.Graph.EdgeDefault = "directed"
+	for _, node := range out.Nodes {
+		data := []graphMLData{
+			{Key: "label", Value: node.Label},
+			{Key: "type", Value: node.Type},
+			{Key: "highlighted", Value: fmt.Sprint(highlightedNodes[node.ID])},
+		}
+		for _, key := range propertyKeys {
+			if value, ok := node.Properties[key]; ok {
+				data = append(data, graphMLData{Key: "prop_" + key, Value: fmt.Sprint(value)})
+			}
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{ID: node.ID, Data: data})
+	}
+	for _, edge := range out.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			Source: edge.From,
+			Target: edge.To,
+			Data: []graphMLData{
+				{Key: "relationship", Value: edge.Type},
+				{Key: "edge_highlighted", Value: fmt.Sprint(highlightedEdges[[2]string{edge.From, edge.To}])},
+			},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("error encoding GraphML: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// propertyKeys returns sorted names of all node properties
+func (g *Graph) propertyKeys() []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, node := range g.Nodes {
+		for key := range node.Properties {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type d3Node struct {
+	ID          string         `json:"id"`
+	Label       string         `json:"label"`
+	Group       string         `json:"group,omitempty"`
+	Highlighted bool           `json:"highlighted,omitempty"`
+	Properties  map[string]any `json:"properties,omitempty"`
+}
+
+type d3Link struct {
+	Source      string `json:"source"`
+	Target      string `json:"target"`
+	Type        string `json:"type"`
+	Highlighted bool   `json:"highlighted,omitempty"`
+}
+
+// WriteJSON writes the graph as {"nodes": [...], "links": [...]} ready for d3-force
+func (g *Graph) WriteJSON(w io.Writer, opts ExportOptions) error {
+	out, highlightedNodes, highlightedEdges := g.prepare(opts)
+
+	doc := struct {
+		Nodes []d3Node `json:"nodes"`
+		Links []d3Link `json:"links"`
+	}{Nodes: []d3Node{}, Links: []d3Link{}}
+
+	for _, node := range out.Nodes {
+		doc.Nodes = append(doc.Nodes, d3Node{
+			ID:          node.ID,
+			Label:       node.Label,
+			Group:       node.Type,
+			Highlighted: highlightedNodes[node.ID],
+			Properties:  node.Properties,
+		})
+	}
+	for _, edge := range out.Edges {
+		doc.Links = append(doc.Links, d3Link{
+			Source:      edge.From,
+			Target:      edge.To,
+			Type:        edge.Type,
+			Highlighted: highlightedEdges[[2]string{edge.From, edge.To}],
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("error encoding JSON graph: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package graph
+
+// Node represents a single vertex of an in-memory graph
+type Node struct {
+	// ID is unique in the graph, e.g. "Person:1", so nodes of different types with the same key stay apart
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	Type  string `json:"type"`
+	// Key identifies the node among nodes of its type in Neo4j (original_id), ID is used when empty
+	Key        string         `json:"key,omitempty"`
+	Properties map[string]any `json:"properties,omitempty"`
+}
+
+// Edge represents a directed relationship between two nodes
+type Edge struct {
+	From string `json:"source"`
+	To   string `json:"target"`
+	Type string `json:"type"`
+}
+
+// Graph is an in-memory graph of nodes and edges
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+
+	nodeIndex map[string]int
+	edgeIndex map[Edge]bool
+}
+
+// NewGraph creates an empty in-memory graph
+func NewGraph() *Graph {
+	return &Graph{
+		Nodes:     []Node{},
+		Edges:     []Edge{},
+		nodeIndex: make(map[string]int),
+		edgeIndex: make(map[Edge]bool),
+	}
+}
+
+// reindex rebuilds lookup maps, Nodes and Edges may have been changed directly
+// or built as a struct literal
+func (g *Graph) reindex() {
+	g.nodeIndex = make(map[string]int, len(g.Nodes))
+	for i, node := range g.Nodes {
+		g.nodeIndex[node.ID] = i
+	}
+	g.edgeIndex = make(map[Edge]bool, len(g.Edges))
+	for _, edge := range g.Edges {
+		g.edgeIndex[edge] = true
+	}
+}
+
+// ensureIndex keeps the lookup maps up to date with the Add methods and rebuilds them
+// when Nodes or Edges were changed directly, which shows as a size the maps don't have
+func (g *Graph) ensureIndex() {
+	if g.nodeIndex == nil || g.edgeIndex == nil || len(g.nodeIndex) != len(g.Nodes) || len(g.edgeIndex) != len(g.Edges) {
+		g.reindex()
+	}
+}
+
+// AddNode adds a node to the graph, merging properties if the node already exists
+func (g *Graph) AddNode(node Node) {
+	g.ensureIndex()
+	g.addNode(node)
+}
+
+func (g *Graph) addNode(node Node) {
+	if i, ok := g.nodeIndex[node.ID]; ok {
+		existing := &g.Nodes[i]
+		if existing.Label == "" {
+			existing.Label = node.Label
+		}
+		if existing.Type == "" {
+			existing.Type = node.Type
+		}
+		if existing.Key == "" {
+			existing.Key = node.Key
+		}
+		for key, value := range node.Properties {
+			if existing.Properties == nil {
+				existing.Properties = make(map[string]any)
+			}
+			existing.Properties[key] = value
+		}
+		return
+	}
+	if node.Label == "" {
+		node.Label = node.ID
+	}
+	g.nodeIndex[node.ID] = len(g.Nodes)
+	g.Nodes = append(g.Nodes, node)
+}
+
+// AddEdge adds an edge to the graph, creating missing endpoints. Duplicate edges are ignored.
+func (g *Graph) AddEdge(from, to, relationshipType string) {
+	g.ensureIndex()
+	if _, ok := g.nodeIndex[from]; !ok {
+		g.addNode(Node{ID: from})
+	}
+	if _, ok := g.nodeIndex[to]; !ok {
+		g.addNode(Node{ID: to})
+	}
+	edge := Edge{From: from, To: to, Type: relationshipType}
+	if g.edgeIndex[edge] {
+		return
+	}
+	g.edgeIndex[edge] = true
+	g.Edges = append(g.Edges, edge)
+}
+
+// GetNode returns the node with the given ID or nil if it does not exist
+func (g *Graph) GetNode(id string) *Node {
+	g.ensureIndex()
+	if i, ok := g.nodeIndex[id]; ok {
+		return &g.Nodes[i]
+	}
+	return nil
+}
+
+// FindNode returns the node matching the given ID or label
+func (g *Graph) FindNode(idOrLabel string) *Node {
+	if node := g.GetNode(idOrLabel); node != nil {
+		return node
+	}
+	for i := range g.Nodes {
+		if g.Nodes[i].Label == idOrLabel {
+			return &g.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// clone returns a copy of the graph that can be modified independently
+func (g *Graph) clone() *Graph {
+	out := NewGraph()
+	for _, node := range g.Nodes {
+		out.AddNode(node)
+	}
+	for _, edge := range g.Edges {
+		out.AddEdge(edge.From, edge.To, edge.Type)
+	}
+	return out
+}
+
+// neighbours returns the IDs of nodes connected to the given node, ignoring edge direction
+func (g *Graph) neighbours(id string) []string {
+	var result []string
+	for _, edge := range g.Edges {
+		if edge.From == id {
+			result = append(result, edge.To)
+		} else if edge.To == id {
+			result = append(result, edge.From)
+		}
+	}
+	return result
+}
+
+// Subgraph returns the part of the graph within depth hops of the center node (matched by ID or label)
+func (g *Graph) Subgraph(center string, depth int) *Graph {
+	sub := NewGraph()
+	start := g.FindNode(center)
+	if start == nil {
+		return sub
+	}
+
+	distance := map[string]int{start.ID: 0}
+	queue := []string{start.ID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if distance[current] >= depth {
+			continue
+		}
+		for _, next := range g.neighbours(current) {
+			if _, seen := distance[next]; !seen {
+				distance[next] = distance[current] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	for _, node := range g.Nodes {
+		if _, ok := distance[node.ID]; ok {
+			sub.AddNode(node)
+		}
+	}
+	for _, edge := range g.Edges {
+		_, fromOk := distance[edge.From]
+		_, toOk := distance[edge.To]
+		if fromOk && toOk {
+			sub.AddEdge(edge.From, edge.To, edge.Type)
+		}
+	}
+	return sub
+}
+
+// ShortestPath returns node IDs of the shortest undirected path between two nodes (matched by ID or label)
+func (g *Graph) ShortestPath(from, to string) []string {
+	start := g.FindNode(from)
+	end := g.FindNode(to)
+	if start == nil || end == nil {
+		return nil
+	}
+
+	previous := map[string]string{start.ID: ""}
+	queue := []string{start.ID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == end.ID {
+			break
+		}
+		for _, next := range g.neighbours(current) {
+			if _, seen := previous[next]; !seen {
+				previous[next] = current
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	if _, found := previous[end.ID]; !found {
+		return nil
+	}
+
+	var path []string
+	for current := end.ID; current != ""; current = previous[current] {
+		path = append([]string{current}, path...)
+	}
+	return path
+}
@@ -0,0 +1,126 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNodeID(t *testing.T) {
+	tests := []struct {
+		label, key, elementID string
+		want                  string
+	}{
+		{"Person", "ADAM", "4:x:1", "Person:ADAM"},
+		{"City", "ADAM", "4:x:2", "City:ADAM"},
+		{"Person", "person:ADAM", "4:x:3", "person:ADAM"},
+		{"Person", "", "4:x:4", "Person:4:x:4"},
+		{"Node", "Person:", "4:x:5", "Node:Person:"},
+	}
+	for _, tt := range tests {
+		if got := nodeID(tt.label, tt.key, tt.elementID); got != tt.want {
+			t.Errorf("nodeID(%q, %q, %q) = %q, want %q", tt.label, tt.key, tt.elementID, got, tt.want)
+		}
+	}
+}
+
+func TestAddEdgeAfterDirectEdgeChange(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge("a", "b", "KNOWS")
+
+	// edges replaced without the node count changing
+	g.Edges = nil
+	g.AddEdge("a", "b", "KNOWS")
+	if len(g.Edges) != 1 {
+		t.Fatalf("got %d edges, want the removed edge added again", len(g.Edges))
+	}
+
+	g.Edges = append(g.Edges, Edge{From: "b", To: "a", Type: "KNOWS"})
+	g.AddEdge("b", "a", "KNOWS")
+	if len(g.Edges) != 2 {
+		t.Fatalf("got %d edges, want the directly added edge to be known", len(g.Edges))
+	}
+}
+
+func TestShortestPathKeepsSameNamesApart(t *testing.T) {
+	g := NewGraph()
+	g.AddNode(Node{ID: "Person:ADAM", Label: "ADAM", Type: "person"})
+	g.AddNode(Node{ID: "City:ADAM", Label: "ADAM", Type: "city"})
+	g.AddEdge("Person:ADAM", "Person:EWA", "KNOWS")
+	g.AddEdge("City:ADAM", "City:ELBLAG", "ROAD")
+
+	if len(g.Nodes) != 4 {
+		t.Fatalf("got %d nodes, want 4", len(g.Nodes))
+	}
+	if path := g.ShortestPath("Person:EWA", "City:ELBLAG"); path != nil {
+		t.Errorf("got path %v between unconnected nodes", path)
+	}
+}
+
+func TestIndexFollowsDirectNodeChanges(t *testing.T) {
+	g := NewGraph()
+	for i := 0; i < 1000; i++ {
+		g.AddEdge(fmt.Sprint("p", i), fmt.Sprint("p", i+1), "KNOWS")
+	}
+	if len(g.Nodes) != 1001 || len(g.Edges) != 1000 {
+		t.Fatalf("got %d nodes and %d edges, want 1001 and 1000", len(g.Nodes), len(g.Edges))
+	}
+
+	g.Nodes = append(g.Nodes, Node{ID: "direct", Label: "direct"})
+	g.AddNode(Node{ID: "direct", Properties: map[string]any{"seen": true}})
+	if len(g.Nodes) != 1002 || g.GetNode("direct").Properties["seen"] != true {
+		t.Errorf("directly added node not merged: %d nodes, %+v", len(g.Nodes), g.GetNode("direct"))
+	}
+
+	literal := &Graph{Nodes: []Node{{ID: "a"}}, Edges: []Edge{{From: "a", To: "b", Type: "KNOWS"}}}
+	literal.AddEdge("a", "b", "KNOWS")
+	if len(literal.Edges) != 1 || literal.GetNode("a") == nil {
+		t.Errorf("struct literal not indexed: %+v", literal)
+	}
+}
+
+func TestMergeQueries(t *testing.T) {
+	tests := []struct {
+		name      string
+		node      Node
+		wantLabel string
+		wantKey   string
+	}{
+		{"loaded person", Node{ID: "Person:1", Key: "1", Label: "Rafał", Type: "person", Properties: map[string]any{"username": "Rafał", "original_id": "1"}}, "Person", "1"},
+		{"loaded label case", Node{ID: "DataCenter:7", Key: "7", Type: "datacenter"}, "DataCenter", "7"},
+		{"explored place", Node{ID: "place:ELBLAG", Key: "ELBLAG", Label: "ELBLAG", Type: "place"}, "Place", "ELBLAG"},
+		{"without key", Node{ID: "b", Label: "b"}, "Node", "b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, params := mergeNodeQuery(tt.node)
+			if want := "MERGE (n:" + tt.wantLabel + " {original_id: $key}) SET n += $properties"; query != want {
+				t.Errorf("got query %s, want %s", query, want)
+			}
+			properties := params["properties"].(map[string]any)
+			if params["key"] != tt.wantKey || properties["original_id"] != tt.wantKey {
+				t.Errorf("got params %v, want key %s", params, tt.wantKey)
+			}
+			for name, value := range tt.node.Properties {
+				if properties[name] != value {
+					t.Errorf("property %s = %v, want %v", name, properties[name], value)
+				}
+			}
+			if properties["name"] != tt.node.Label && tt.node.Label != "" {
+				t.Errorf("got name %v, want %s", properties["name"], tt.node.Label)
+			}
+		})
+	}
+
+	person := Node{ID: "Person:1", Key: "1", Type: "person"}
+	city := Node{ID: "City:1", Key: "1", Type: "city"}
+	query, params := mergeEdgeQuery(person, city, "lives in")
+	for _, want := range []string{"MATCH (a:Person {original_id: $from})", "MATCH (b:City {original_id: $to})", "MERGE (a)-[r:Livesin]->(b)"} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query %s does not contain %s", query, want)
+		}
+	}
+	if params["from"] != "1" || params["to"] != "1" {
+		t.Errorf("got params %v", params)
+	}
+}
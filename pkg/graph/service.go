@@ -3,16 +3,23 @@ package graph
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/crowmw/ai_devs3/pkg/env"
+	"github.com/crowmw/ai_devs3/pkg/logging"
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 )
 
+var logger = logging.New("graph")
+
 type Service struct {
 	driver neo4j.DriverWithContext
 }
 
 func NewService(ctx context.Context, envSvc *env.Service) (*Service, error) {
+	if err := envSvc.Require(env.KeyNeo4jURL, env.KeyNeo4jUsername, env.KeyNeo4jPassword); err != nil {
+		return nil, err
+	}
 	neo4jConfig := envSvc.GetNeo4jConfig()
 
 	driver, err := neo4j.NewDriverWithContext(
@@ -27,7 +34,7 @@ func NewService(ctx context.Context, envSvc *env.Service) (*Service, error) {
 		driver.Close(ctx) // Close only if verification fails
 		return nil, fmt.Errorf("failed to verify Neo4j connection: %w", err)
 	}
-	fmt.Println("Connection established.")
+	logger.Info("connected to Neo4j", "url", neo4jConfig.URL)
 
 	return &Service{
 		driver: driver,
@@ -55,6 +62,7 @@ func (s *Service) CreatePerson(ctx context.Context, person PersonNode) error {
 			"username":    person.Username,
 			"original_id": person.OriginalID,
 		}
+		logger.Debug("running query", "query", query, "params", params)
 		_, err := tx.Run(ctx, query, params)
 		return nil, err
 	})
@@ -77,6 +85,7 @@ func (s *Service) CreateRelationship(ctx context.Context, person1, person2, rela
 			"person1": person1,
 			"person2": person2,
 		}
+		logger.Debug("running query", "query", query, "params", params)
 		_, err := tx.Run(ctx, query, params)
 		return nil, err
 	})
@@ -101,6 +110,7 @@ func (s *Service) GetShortestConnection(ctx context.Context, fromID, toID string
 			"toID":   toID,
 		}
 
+		logger.Debug("running query", "query", query, "params", params)
 		result, err := tx.Run(ctx, query, params)
 		if err != nil {
 			return nil, err
@@ -143,6 +153,7 @@ func (s *Service) GetPersonConnections(ctx context.Context, name string) ([]stri
 		params := map[string]any{
 			"name": name,
 		}
+		logger.Debug("running query", "query", query, "params", params)
 		result, err := tx.Run(ctx, query, params)
 		if err != nil {
 			return nil, err
@@ -165,6 +176,189 @@ func (s *Service) GetPersonConnections(ctx context.Context, name string) ([]stri
 	return result.([]string), nil
 }
 
+// LoadGraph reads all nodes and relationships from Neo4j into an in-memory graph
+func (s *Service) LoadGraph(ctx context.Context) (*Graph, error) {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		g := NewGraph()
+
+		nodesResult, err := tx.Run(ctx, `
+			MATCH (n)
+			RETURN elementId(n) as element_id, labels(n) as labels, properties(n) as props
+		`, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		// Neo4j element ids are mapped to our ids, so relationships can be resolved below
+		ids := make(map[string]string)
+		for nodesResult.Next(ctx) {
+			record := nodesResult.Record()
+			elementID, _ := record.Get("element_id")
+			labels, _ := record.Get("labels")
+			props, _ := record.Get("props")
+
+			properties, _ := props.(map[string]any)
+			node := Node{
+				Label:      firstString(properties, "username", "name", "original_id"),
+				Properties: properties,
+			}
+			label := "Node"
+			if labelList, ok := labels.([]any); ok && len(labelList) > 0 {
+				label = fmt.Sprint(labelList[0])
+				node.Type = strings.ToLower(label)
+			}
+			node.Key = firstString(properties, "original_id", "name", "username")
+			node.ID = nodeID(label, node.Key, elementID.(string))
+
+			ids[elementID.(string)] = node.ID
+			g.AddNode(node)
+		}
+		if err := nodesResult.Err(); err != nil {
+			return nil, err
+		}
+
+		edgesResult, err := tx.Run(ctx, `
+			MATCH (a)-[r]->(b)
+			RETURN elementId(a) as from, elementId(b) as to, type(r) as type
+		`, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for edgesResult.Next(ctx) {
+			record := edgesResult.Record()
+			from, _ := record.Get("from")
+			to, _ := record.Get("to")
+			relationshipType, _ := record.Get("type")
+			g.AddEdge(ids[from.(string)], ids[to.(string)], relationshipType.(string))
+		}
+		return g, edgesResult.Err()
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to load graph: %w", err)
+	}
+
+	return result.(*Graph), nil
+}
+
+// SaveGraph merges all nodes and relationships of an in-memory graph into Neo4j
+func (s *Service) SaveGraph(ctx context.Context, g *Graph) error {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		for _, node := range g.Nodes {
+			query, params := mergeNodeQuery(node)
+			logger.Debug("running query", "query", query, "params", params)
+			if _, err := tx.Run(ctx, query, params); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, edge := range g.Edges {
+			from, to := g.GetNode(edge.From), g.GetNode(edge.To)
+			if from == nil || to == nil {
+				return nil, fmt.Errorf("edge %s -> %s has no node", edge.From, edge.To)
+			}
+			query, params := mergeEdgeQuery(*from, *to, edge.Type)
+			logger.Debug("running query", "query", query, "params", params)
+			if _, err := tx.Run(ctx, query, params); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to save graph: %w", err)
+	}
+	return nil
+}
+
+// storeKey returns the label and the original_id a node is merged on. Type is lower case,
+// the label is taken from the ID prefix when it has one, e.g. "DataCenter:1" of a loaded node.
+func storeKey(node Node) (label, key string) {
+	key = node.Key
+	if key == "" {
+		key = node.ID
+	}
+	if prefix, _, found := strings.Cut(node.ID, ":"); found && strings.EqualFold(prefix, node.Type) {
+		return cypherName(prefix, "Node"), key
+	}
+	return cypherName(node.Type, "Node"), key
+}
+
+// mergeNodeQuery merges a node on its label and key, so a node loaded by LoadGraph is updated in place,
+// and sets its properties. The label becomes the name unless the properties have one.
+func mergeNodeQuery(node Node) (string, map[string]any) {
+	label, key := storeKey(node)
+	properties := make(map[string]any, len(node.Properties)+2)
+	for name, value := range node.Properties {
+		properties[name] = value
+	}
+	if _, ok := properties["name"]; !ok && node.Label != "" {
+		properties["name"] = node.Label
+	}
+	properties["original_id"] = key
+	return "MERGE (n:" + label + " {original_id: $key}) SET n += $properties", map[string]any{
+		"key":        key,
+		"properties": properties,
+	}
+}
+
+// mergeEdgeQuery merges a relationship between two nodes matched by label and key like mergeNodeQuery
+func mergeEdgeQuery(from, to Node, relationshipType string) (string, map[string]any) {
+	fromLabel, fromKey := storeKey(from)
+	toLabel, toKey := storeKey(to)
+	query := `
+		MATCH (a:` + fromLabel + ` {original_id: $from})
+		MATCH (b:` + toLabel + ` {original_id: $to})
+		MERGE (a)-[r:` + cypherName(relationshipType, "CONNECTED_TO") + `]->(b)
+	`
+	return query, map[string]any{"from": fromKey, "to": toKey}
+}
+
+// nodeID prefixes a node key with its label, so a Person and a City with the same name stay apart.
+// Keys that already carry the prefix, e.g. saved by older versions of SaveGraph, are kept.
+func nodeID(label, key, elementID string) string {
+	if key == "" {
+		key = elementID
+	}
+	if prefix := label + ":"; len(key) > len(prefix) && strings.EqualFold(key[:len(prefix)], prefix) {
+		return key
+	}
+	return label + ":" + key
+}
+
+// cypherName makes a label or relationship type safe to embed in a query
+func cypherName(name, fallback string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() == 0 {
+		return fallback
+	}
+	result := b.String()
+	return strings.ToUpper(result[:1]) + result[1:]
+}
+
+// firstString returns the first non-empty property value from the given keys
+func firstString(properties map[string]any, keys ...string) string {
+	for _, key := range keys {
+		if value, ok := properties[key]; ok && value != nil && fmt.Sprint(value) != "" {
+			return fmt.Sprint(value)
+		}
+	}
+	return ""
+}
+
 // ClearDatabase removes all nodes and relationships from the database
 func (s *Service) ClearDatabase(ctx context.Context) error {
 	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
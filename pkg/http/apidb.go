@@ -1,13 +1,14 @@
 package http
 
 import (
-	"fmt"
-
 	"github.com/crowmw/ai_devs3/pkg/env"
+	"github.com/crowmw/ai_devs3/pkg/logging"
 )
 
+var logger = logging.New("http")
+
 func PostSQLQueryToAPIDB(envSvc *env.Service, query string) (string, error) {
-	fmt.Println("Sending SQL query to APIDB:", query)
+	logger.Info("sending SQL query to APIDB", "query", query)
 	url := envSvc.GetC3ntralaURL() + "/apidb"
 
 	postData := map[string]interface{}{
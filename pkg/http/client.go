@@ -108,7 +108,7 @@ func SendFormData(targetURL string, formData map[string]string) (string, error)
 
 	// Check response status
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return "", &StatusError{StatusCode: resp.StatusCode}
 	}
 
 	// Read response body
@@ -167,3 +167,59 @@ func POSTJSONData(url string, body interface{}, v interface{}) error {
 
 	return nil
 }
+
+// StatusError is returned for a response with an unexpected status code
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+// ConditionalResponse is the result of a conditional GET request
+type ConditionalResponse struct {
+	Body         []byte
+	ETag         string
+	LastModified string
+	NotModified  bool
+}
+
+// FetchConditional retrieves data from the URL, sending If-None-Match/If-Modified-Since when validators are given
+func FetchConditional(url, etag, lastModified string) (*ConditionalResponse, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching data: %w", err)
+	}
+	defer resp.Body.Close()
+
+	result := &ConditionalResponse{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		result.NotModified = true
+		return result, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	result.Body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,148 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/crowmw/ai_devs3/pkg/redact"
+)
+
+// Attribute keys shared by all components
+const (
+	KeyComponent = "component"
+	KeyAgent     = "agent"
+	KeyPhase     = "phase"
+	KeyTool      = "tool"
+	KeyTask      = "task"
+)
+
+// Output formats
+const (
+	FormatPretty = "pretty"
+	FormatJSON   = "json"
+)
+
+// Options configure the default logger
+type Options struct {
+	// Level is debug, info, warn or error
+	Level string
+	// Format is pretty or json
+	Format string
+	// DumpPrompts enables logging of whole prompts, responses and payloads
+	DumpPrompts bool
+	// Output defaults to stderr; everything written is passed through redact
+	Output io.Writer
+}
+
+var dumpPrompts atomic.Bool
+
+// Setup installs the default slog logger used by every component
+func Setup(opts Options) error {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return err
+	}
+
+	out := opts.Output
+	if out == nil {
+		out = os.Stderr
+	}
+	out = redact.NewWriter(out)
+
+	var handler slog.Handler
+	switch strings.ToLower(opts.Format) {
+	case "", FormatPretty:
+		handler = NewPrettyHandler(out, level)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(out, &slog.HandlerOptions{Level: level})
+	default:
+		return fmt.Errorf("unknown log format %q, use %s or %s", opts.Format, FormatPretty, FormatJSON)
+	}
+
+	slog.SetDefault(slog.New(handler))
+	dumpPrompts.Store(opts.DumpPrompts)
+	return nil
+}
+
+// ParseLevel converts a level name to slog.Level, an empty name means info
+func ParseLevel(name string) (slog.Level, error) {
+	if name == "" {
+		return slog.LevelInfo, nil
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q, use debug, info, warn or error", name)
+	}
+	return level, nil
+}
+
+// New returns a logger tagged with the component name. It always writes through the
+// current default logger, so package level loggers pick up the configuration from Setup.
+func New(component string) *slog.Logger {
+	return slog.New(&defaultHandler{attrs: []slog.Attr{slog.String(KeyComponent, component)}})
+}
+
+// DumpEnabled reports whether prompts and payloads should be logged
+func DumpEnabled() bool {
+	return dumpPrompts.Load()
+}
+
+// Dump logs a whole prompt, response or payload when dumping is enabled
+func Dump(logger *slog.Logger, msg string, args ...any) {
+	if !DumpEnabled() {
+		return
+	}
+	logger.Info(msg, args...)
+}
+
+// defaultHandler forwards records to slog.Default() at the time they are logged
+type defaultHandler struct {
+	attrs []slog.Attr
+}
+
+func (h *defaultHandler) target() slog.Handler {
+	return slog.Default().Handler().WithAttrs(h.attrs)
+}
+
+func (h *defaultHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return slog.Default().Handler().Enabled(ctx, level)
+}
+
+func (h *defaultHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.target().Handle(ctx, record)
+}
+
+func (h *defaultHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &defaultHandler{attrs: append(h.attrs[:len(h.attrs):len(h.attrs)], attrs...)}
+}
+
+// WithGroup binds to the current default logger, groups are not used by the components
+func (h *defaultHandler) WithGroup(name string) slog.Handler {
+	return &fixedHandler{handler: h.target().WithGroup(name)}
+}
+
+// fixedHandler is a handler bound to the default logger at the time it was created
+type fixedHandler struct {
+	handler slog.Handler
+}
+
+func (h *fixedHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *fixedHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.handler.Handle(ctx, record)
+}
+
+func (h *fixedHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &fixedHandler{handler: h.handler.WithAttrs(attrs)}
+}
+
+func (h *fixedHandler) WithGroup(name string) slog.Handler {
+	return &fixedHandler{handler: h.handler.WithGroup(name)}
+}
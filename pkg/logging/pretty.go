@@ -0,0 +1,135 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PrettyHandler writes human readable lines:
+//
+//	12:04:05.123 INFO  [gps_agent] planning agent=gps phase=plan step=2
+//
+// Multi-line values such as prompts are printed as indented blocks below the line.
+type PrettyHandler struct {
+	mu    *sync.Mutex
+	out   io.Writer
+	level slog.Leveler
+	// component is shown in brackets, attrs and groups apply to every record
+	component string
+	attrs     []slog.Attr
+	groups    []string
+}
+
+// NewPrettyHandler creates a handler writing records at or above level to out
+func NewPrettyHandler(out io.Writer, level slog.Leveler) *PrettyHandler {
+	return &PrettyHandler{mu: &sync.Mutex{}, out: out, level: level}
+}
+
+func (h *PrettyHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *PrettyHandler) Handle(_ context.Context, record slog.Record) error {
+	var line, blocks bytes.Buffer
+
+	line.WriteString(record.Time.Format("15:04:05.000"))
+	fmt.Fprintf(&line, " %-5s ", record.Level.String())
+	if h.component != "" {
+		fmt.Fprintf(&line, "[%s] ", h.component)
+	}
+	line.WriteString(record.Message)
+
+	prefix := strings.Join(h.groups, ".")
+	for _, attr := range h.attrs {
+		writeAttr(&line, &blocks, "", attr)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		writeAttr(&line, &blocks, prefix, attr)
+		return true
+	})
+	line.WriteByte('\n')
+	line.Write(blocks.Bytes())
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.out.Write(line.Bytes())
+	return err
+}
+
+func (h *PrettyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = h.attrs[:len(h.attrs):len(h.attrs)]
+	prefix := strings.Join(h.groups, ".")
+	for _, attr := range attrs {
+		if attr.Key == KeyComponent && len(h.groups) == 0 {
+			clone.component = attr.Value.String()
+			continue
+		}
+		if prefix != "" {
+			attr.Key = prefix + "." + attr.Key
+		}
+		clone.attrs = append(clone.attrs, attr)
+	}
+	return &clone
+}
+
+func (h *PrettyHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &clone
+}
+
+// writeAttr appends key=value to the line, or an indented block for multi-line values
+func writeAttr(line, blocks *bytes.Buffer, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+	key := attr.Key
+	if prefix != "" {
+		key = prefix + "." + key
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		for _, member := range attr.Value.Group() {
+			writeAttr(line, blocks, key, member)
+		}
+		return
+	}
+
+	var value string
+	switch attr.Value.Kind() {
+	case slog.KindTime:
+		value = attr.Value.Time().Format(time.RFC3339)
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok {
+			value = err.Error()
+		} else {
+			value = fmt.Sprintf("%+v", attr.Value.Any())
+		}
+	default:
+		value = attr.Value.String()
+	}
+
+	if strings.Contains(value, "\n") {
+		fmt.Fprintf(blocks, "    %s:\n", key)
+		for _, l := range strings.Split(strings.TrimRight(value, "\n"), "\n") {
+			fmt.Fprintf(blocks, "      %s\n", l)
+		}
+		return
+	}
+	if value == "" || strings.ContainsAny(value, " \t\"=") {
+		value = strconv.Quote(value)
+	}
+	fmt.Fprintf(line, " %s=%s", key, value)
+}
@@ -9,11 +9,15 @@ import (
 
 	"github.com/crowmw/ai_devs3/pkg/ai"
 	httpclient "github.com/crowmw/ai_devs3/pkg/http"
+	"github.com/crowmw/ai_devs3/pkg/logging"
 	"golang.org/x/net/html"
 )
 
+var logger = logging.New("media")
+
 // Processor handles media file processing
 type Processor struct {
+	aiSvc     *ai.Service
 	outputDir string
 	htmlProc  HTMLProcessor
 }
@@ -25,8 +29,9 @@ type HTMLProcessor interface {
 }
 
 // NewProcessor creates a new media processor
-func NewProcessor(outputDir string, htmlProc HTMLProcessor) *Processor {
+func NewProcessor(aiSvc *ai.Service, outputDir string, htmlProc HTMLProcessor) *Processor {
 	return &Processor{
+		aiSvc:     aiSvc,
 		outputDir: outputDir,
 		htmlProc:  htmlProc,
 	}
@@ -38,7 +43,7 @@ func (p *Processor) ProcessImage(src string, index int) (string, error) {
 	fullURL := p.htmlProc.GetFullURL(src)
 
 	// Fetch image
-	fmt.Println("Fetching image:", fullURL)
+	logger.Info("fetching image", "url", fullURL)
 	imageData, err := httpclient.FetchData(fullURL)
 	if err != nil {
 		return "", fmt.Errorf("error fetching image %s: %w", src, err)
@@ -57,7 +62,7 @@ func (p *Processor) ProcessImage(src string, index int) (string, error) {
 	}
 
 	// Read image to base64
-	fmt.Println("Reading image to base64:", imagePath)
+	logger.Debug("reading image to base64", "file", imagePath)
 	imageBase64, err := ReadImageToBase64(imagePath)
 	if err != nil {
 		return "", fmt.Errorf("error reading image %s: %w", imagePath, err)
@@ -67,7 +72,7 @@ func (p *Processor) ProcessImage(src string, index int) (string, error) {
 	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(imagePath)), ".")
 
 	// Describe image
-	return ai.DescribeImageAndFormat(imageBase64, format)
+	return p.aiSvc.DescribeImageAndFormat(imageBase64, format)
 }
 
 // ProcessAudio processes a single audio file and returns its transcription
@@ -76,7 +81,7 @@ func (p *Processor) ProcessAudio(src string, index int) (string, error) {
 	fullURL := p.htmlProc.GetFullURL(src)
 
 	// Fetch audio
-	fmt.Println("Fetching audio:", fullURL)
+	logger.Info("fetching audio", "url", fullURL)
 	audioData, err := httpclient.FetchData(fullURL)
 	if err != nil {
 		return "", fmt.Errorf("error fetching audio %s: %w", src, err)
@@ -95,8 +100,8 @@ func (p *Processor) ProcessAudio(src string, index int) (string, error) {
 	}
 
 	// Transcribe audio
-	fmt.Println("Transcribing audio:", audioPath)
-	return ai.TranscribeAudioAndFormat(audioPath)
+	logger.Info("transcribing audio", "file", audioPath)
+	return p.aiSvc.TranscribeAudioAndFormat(audioPath)
 }
 
 // ReadImageToBase64 reads an image file and converts it to base64
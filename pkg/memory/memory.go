@@ -0,0 +1,292 @@
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crowmw/ai_devs3/pkg/logging"
+	"github.com/google/uuid"
+)
+
+var logger = logging.New("memory")
+
+// DefaultDuplicateThreshold is the cosine similarity above which a new fact is treated as one already known
+const DefaultDuplicateThreshold = 0.95
+
+// Embedder turns a text into a vector, e.g. ai.Service.CreateOpenAIEmbedding
+type Embedder func(text string) ([]float32, error)
+
+// Record is a single remembered fact
+type Record struct {
+	ID        string    `json:"id"`
+	Text      string    `json:"text"`
+	Embedding []float32 `json:"embedding"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// ExpiresAt is zero for facts that never expire
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+func (r Record) expired(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && !now.Before(r.ExpiresAt)
+}
+
+// Match is a record found by Recall with its similarity to the query
+type Match struct {
+	Record
+	Score float64
+}
+
+type Options struct {
+	// Path of the JSON file the memory is kept in, e.g. <cache-dir>/memory/serce.json
+	Path  string
+	Embed Embedder
+	// TTL of new facts, zero keeps them forever
+	TTL time.Duration
+	// DuplicateThreshold defaults to DefaultDuplicateThreshold
+	DuplicateThreshold float64
+}
+
+// Memory keeps facts with their embeddings in a JSON file.
+// It holds hundreds of facts of a single agent, so they are searched in process instead of in Qdrant.
+type Memory struct {
+	mu        sync.Mutex
+	path      string
+	embed     Embedder
+	ttl       time.Duration
+	threshold float64
+	records   []Record
+}
+
+// Open loads the memory from opts.Path, a missing file starts an empty memory
+func Open(opts Options) (*Memory, error) {
+	if opts.Embed == nil {
+		return nil, fmt.Errorf("memory needs an embedder")
+	}
+	if opts.DuplicateThreshold == 0 {
+		opts.DuplicateThreshold = DefaultDuplicateThreshold
+	}
+	m := &Memory{path: opts.Path, embed: opts.Embed, ttl: opts.TTL, threshold: opts.DuplicateThreshold}
+
+	if m.path != "" {
+		data, err := os.ReadFile(m.path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("error reading memory: %w", err)
+		}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &m.records); err != nil {
+				return nil, fmt.Errorf("error parsing memory %s: %w", m.path, err)
+			}
+		}
+	}
+
+	if removed := m.prune(time.Now()); removed > 0 {
+		if err := m.save(); err != nil {
+			return nil, err
+		}
+	}
+	logger.Info("memory loaded", "path", m.path, "records", len(m.records))
+	return m, nil
+}
+
+// Write remembers a fact. A fact equal or similar to a known one replaces the text of that record instead,
+// the newer wording wins, e.g. a corrected value. The returned bool tells whether it was a duplicate.
+func (m *Memory) Write(text string) (Record, bool, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return Record{}, false, fmt.Errorf("empty fact")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	m.prune(now)
+
+	// exact duplicates don't need an embedding
+	for i, record := range m.records {
+		if normalize(record.Text) == normalize(text) {
+			return m.refresh(i, now, text, record.Embedding)
+		}
+	}
+
+	embedding, err := m.embed(text)
+	if err != nil {
+		return Record{}, false, fmt.Errorf("error creating embedding: %w", err)
+	}
+	similar, best := -1, m.threshold
+	for i, record := range m.records {
+		if score := cosine(embedding, record.Embedding); score >= best {
+			similar, best = i, score
+		}
+	}
+	if similar >= 0 {
+		logger.Debug("similar fact already known", "fact", text, "known", m.records[similar].Text, "score", best)
+		return m.refresh(similar, now, text, embedding)
+	}
+
+	record := Record{
+		ID:        uuid.New().String(),
+		Text:      text,
+		Embedding: embedding,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if m.ttl > 0 {
+		record.ExpiresAt = now.Add(m.ttl)
+	}
+	m.records = append(m.records, record)
+	logger.Info("fact remembered", "id", record.ID, "records", len(m.records))
+	return record, false, m.save()
+}
+
+// refresh replaces a known fact written again with its newer text and extends its life
+func (m *Memory) refresh(i int, now time.Time, text string, embedding []float32) (Record, bool, error) {
+	m.records[i].Text = text
+	m.records[i].Embedding = embedding
+	m.records[i].UpdatedAt = now
+	if m.ttl > 0 {
+		m.records[i].ExpiresAt = now.Add(m.ttl)
+	}
+	return m.records[i], true, m.save()
+}
+
+// Recall returns up to k facts most similar to the query, the best first
+func (m *Memory) Recall(query string, k int) ([]Match, error) {
+	m.mu.Lock()
+	empty := len(m.records) == 0
+	m.mu.Unlock()
+	if empty || k <= 0 {
+		return nil, nil
+	}
+
+	embedding, err := m.embed(query)
+	if err != nil {
+		return nil, fmt.Errorf("error creating query embedding: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	var matches []Match
+	for _, record := range m.records {
+		if record.expired(now) {
+			continue
+		}
+		matches = append(matches, Match{Record: record, Score: cosine(embedding, record.Embedding)})
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if len(matches) > k {
+		matches = matches[:k]
+	}
+	logger.Debug("facts recalled", "query", query, "matches", len(matches))
+	return matches, nil
+}
+
+// Lookup returns the facts containing every word of the keyword, ignoring case, the newest first
+func (m *Memory) Lookup(keyword string) []Record {
+	words := strings.Fields(normalize(keyword))
+	if len(words) == 0 {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	var found []Record
+	for _, record := range m.records {
+		if record.expired(now) {
+			continue
+		}
+		text := normalize(record.Text)
+		matched := true
+		for _, word := range words {
+			if !strings.Contains(text, word) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			found = append(found, record)
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].UpdatedAt.After(found[j].UpdatedAt) })
+	return found
+}
+
+// Prune removes expired facts and returns how many were removed
+func (m *Memory) Prune() (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	removed := m.prune(time.Now())
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, m.save()
+}
+
+// Len returns the number of facts, expired ones included until they are pruned
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.records)
+}
+
+func (m *Memory) prune(now time.Time) int {
+	kept := m.records[:0]
+	for _, record := range m.records {
+		if !record.expired(now) {
+			kept = append(kept, record)
+		}
+	}
+	removed := len(m.records) - len(kept)
+	m.records = kept
+	if removed > 0 {
+		logger.Info("expired facts removed", "removed", removed)
+	}
+	return removed
+}
+
+func (m *Memory) save() error {
+	if m.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(m.records)
+	if err != nil {
+		return fmt.Errorf("error marshaling memory: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return fmt.Errorf("error creating memory directory: %w", err)
+	}
+	// Not redacted, remembered facts are often the passwords and keys the agent is asked about later
+	if err := os.WriteFile(m.path, data, 0600); err != nil {
+		return fmt.Errorf("error writing memory: %w", err)
+	}
+	return nil
+}
+
+func normalize(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testEmbedder gives the facts of a test fixed vectors, facts about the same thing point the same way
+func testEmbedder(vectors map[string][]float32) Embedder {
+	return func(text string) ([]float32, error) {
+		for prefix, vector := range vectors {
+			if strings.HasPrefix(text, prefix) {
+				return vector, nil
+			}
+		}
+		return []float32{0, 0, 1}, nil
+	}
+}
+
+func TestWriteDedup(t *testing.T) {
+	vectors := map[string][]float32{
+		"The password is": {1, 0, 0},
+		"Password:":       {0.99, 0.05, 0},
+		"Barbara lives":   {0, 1, 0},
+	}
+
+	tests := []struct {
+		name          string
+		facts         []string
+		wantDuplicate []bool
+		wantTexts     []string
+	}{
+		{"distinct facts", []string{"The password is NONOMNISMORIAR", "Barbara lives in Kraków"}, []bool{false, false},
+			[]string{"The password is NONOMNISMORIAR", "Barbara lives in Kraków"}},
+		{"exact duplicate keeps the newer wording", []string{"The password is NONOMNISMORIAR", "  the password IS   nonomnismoriar "}, []bool{false, true},
+			[]string{"the password IS   nonomnismoriar"}},
+		{"near duplicate replaces the fact", []string{"The password is NONOMNISMORIAR", "Password: S2FwaXRhbg"}, []bool{false, true},
+			[]string{"Password: S2FwaXRhbg"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "memory.json")
+			m, err := Open(Options{Path: path, Embed: testEmbedder(vectors)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, fact := range tt.facts {
+				record, duplicate, err := m.Write(fact)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if duplicate != tt.wantDuplicate[i] {
+					t.Errorf("Write(%q) duplicate = %v, want %v", fact, duplicate, tt.wantDuplicate[i])
+				}
+				if record.Text != strings.TrimSpace(fact) {
+					t.Errorf("Write(%q) = %q, want the written fact", fact, record.Text)
+				}
+			}
+
+			// the file holds the same facts as the memory
+			reopened, err := Open(Options{Path: path, Embed: testEmbedder(vectors)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var texts []string
+			for _, record := range reopened.records {
+				texts = append(texts, record.Text)
+			}
+			if strings.Join(texts, "|") != strings.Join(tt.wantTexts, "|") {
+				t.Errorf("got facts %q, want %q", texts, tt.wantTexts)
+			}
+		})
+	}
+
+	t.Run("near duplicate takes the new embedding", func(t *testing.T) {
+		m, err := Open(Options{Embed: testEmbedder(vectors)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.Write("The password is NONOMNISMORIAR")
+		m.Write("Password: S2FwaXRhbg")
+		matches, err := m.Recall("Password: ?", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(matches) != 1 || matches[0].Score < 0.9999 {
+			t.Errorf("got %+v, want the new fact with its own embedding", matches)
+		}
+	})
+}
+
+func TestExpiry(t *testing.T) {
+	vectors := map[string][]float32{"an old": {1, 0, 0}, "a fresh": {0, 1, 0}}
+	path := filepath.Join(t.TempDir(), "memory.json")
+	m, err := Open(Options{Path: path, Embed: testEmbedder(vectors), TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, _, err := m.Write("an old fact")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old.ExpiresAt.IsZero() {
+		t.Fatal("fact written with a TTL never expires")
+	}
+	m.Write("a fresh fact")
+
+	// an hour passes for the old fact
+	m.records[0].ExpiresAt = time.Now().Add(-time.Second)
+	if found := m.Lookup("fact"); len(found) != 1 || found[0].Text != "a fresh fact" {
+		t.Errorf("Lookup() = %+v, want the fresh fact only", found)
+	}
+	if matches, _ := m.Recall("fact", 10); len(matches) != 1 {
+		t.Errorf("Recall() = %+v, want the fresh fact only", matches)
+	}
+
+	// writing the expired fact again remembers it anew
+	record, duplicate, err := m.Write("an old fact")
+	if err != nil || duplicate || record.ID == old.ID {
+		t.Errorf("Write() = %+v, %v, %v, want a new record", record, duplicate, err)
+	}
+
+	m.records[0].ExpiresAt = time.Now().Add(-time.Second)
+	if err := m.save(); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := Open(Options{Path: path, Embed: testEmbedder(vectors)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Len() != 1 {
+		t.Errorf("got %d facts after reopening, want the expired one pruned", reopened.Len())
+	}
+}
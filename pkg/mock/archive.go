@@ -0,0 +1,90 @@
+package mock
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// serveFixture serves a fixture file. A missing <name>.zip is served zipped from the <name> directory,
+// so archive fixtures stay plain files that can be read and edited.
+func serveFixture(w http.ResponseWriter, r *http.Request, path string) {
+	if info, err := os.Stat(path); err == nil {
+		if info.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, path)
+		return
+	}
+
+	dir := strings.TrimSuffix(path, ".zip")
+	if info, err := os.Stat(dir); dir == path || err != nil || !info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	data, modTime, err := zipDirectory(dir)
+	if err != nil {
+		logger.Error("error zipping fixture", "dir", dir, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.ServeContent(w, r, filepath.Base(path), modTime, bytes.NewReader(data))
+}
+
+// zipDirectory archives the directory with explicit directory entries and returns its latest modification time
+func zipDirectory(dir string) ([]byte, time.Time, error) {
+	var buf bytes.Buffer
+	var modTime time.Time
+	archive := zip.NewWriter(&buf)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || path == dir {
+			return err
+		}
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			header.Name += "/"
+			_, err = archive.CreateHeader(header)
+			return err
+		}
+		header.Method = zip.Deflate
+
+		writer, err := archive.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(writer, file)
+		return err
+	})
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("error zipping %s: %w", dir, err)
+	}
+	if err := archive.Close(); err != nil {
+		return nil, time.Time{}, fmt.Errorf("error zipping %s: %w", dir, err)
+	}
+	return buf.Bytes(), modTime, nil
+}
@@ -0,0 +1,74 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ReportRule describes how the mock answers a report. The first matching rule wins.
+type ReportRule struct {
+	// Answer matches when the submitted answer is JSON-equal to it
+	Answer json.RawMessage `json:"answer,omitempty"`
+	// Pattern matches when the regexp matches the submitted answer (string value or JSON text)
+	Pattern string `json:"pattern,omitempty"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`
+	Debug   string `json:"debug,omitempty"`
+}
+
+// TaskScript holds the report rules for a single task
+type TaskScript struct {
+	Rules []ReportRule `json:"rules"`
+	// Default is used when no rule matches
+	Default *ReportRule `json:"default,omitempty"`
+}
+
+// APIDBFixture holds the tables and scripted query results served by /apidb
+type APIDBFixture struct {
+	// Tables maps a table name to its rows
+	Tables map[string][]map[string]any `json:"tables"`
+	// Schemas maps a table name to its CREATE TABLE statement
+	Schemas map[string]string `json:"schemas,omitempty"`
+	// Queries maps an exact SQL query to its reply rows, taking precedence over Tables
+	Queries map[string][]map[string]any `json:"queries,omitempty"`
+}
+
+// Coordinates is a GPS position returned by /gps
+type Coordinates struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// Fixtures is the content of mock.json in the fixture directory
+type Fixtures struct {
+	// APIKey is the key requests must carry, set MY_API_KEY to it
+	APIKey string `json:"apikey"`
+	// People maps a first name to places where the person was seen
+	People map[string][]string `json:"people"`
+	// Places maps a city to people who were seen there
+	Places map[string][]string `json:"places"`
+	// GPS maps a userID to coordinates
+	GPS   map[string]Coordinates `json:"gps"`
+	APIDB APIDBFixture           `json:"apidb"`
+	Tasks map[string]TaskScript  `json:"tasks"`
+}
+
+// LoadFixtures reads mock.json from the fixture directory
+func LoadFixtures(dir string) (*Fixtures, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "mock.json"))
+	if err != nil {
+		return nil, fmt.Errorf("error reading fixtures: %w", err)
+	}
+
+	var fixtures Fixtures
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("error parsing fixtures: %w", err)
+	}
+	if fixtures.APIKey == "" {
+		return nil, fmt.Errorf("error parsing fixtures: apikey is required")
+	}
+	return &fixtures, nil
+}
@@ -0,0 +1,317 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/crowmw/ai_devs3/pkg/logging"
+	"github.com/crowmw/ai_devs3/pkg/textpl"
+)
+
+var logger = logging.New("mock")
+
+// Server is a local stand-in for C3ntrala serving data from a fixture directory
+type Server struct {
+	dir      string
+	fixtures *Fixtures
+	mu       sync.Mutex
+	mux      *http.ServeMux
+}
+
+// NewServer creates a new mock server for the given fixture directory
+func NewServer(dir string) (*Server, error) {
+	fixtures, err := LoadFixtures(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{dir: dir, fixtures: fixtures, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/data/", s.handleData)
+	s.mux.HandleFunc("/dane/", s.handleDane)
+	s.mux.HandleFunc("/report", s.handleReport)
+	s.mux.HandleFunc("/people", s.handlePeople)
+	s.mux.HandleFunc("/places", s.handlePlaces)
+	s.mux.HandleFunc("/apidb", s.handleAPIDB)
+	s.mux.HandleFunc("/gps", s.handleGPS)
+	s.mux.HandleFunc("/reload", s.handleReload)
+	return s, nil
+}
+
+// ServeHTTP logs every request and dispatches it to the matching handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger.Info("request", "method", r.Method, "path", r.URL.Path)
+	s.mux.ServeHTTP(w, r)
+}
+
+// Reload re-reads mock.json, so rules can be edited without restarting the server
+func (s *Server) Reload() error {
+	fixtures, err := LoadFixtures(s.dir)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.fixtures = fixtures
+	s.mu.Unlock()
+	return nil
+}
+
+// handleReload answers POST /reload
+func (s *Server) handleReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := s.Reload(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	logger.Info("fixtures reloaded")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getFixtures() *Fixtures {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fixtures
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeCode(w http.ResponseWriter, code int, message any) {
+	writeJSON(w, http.StatusOK, map[string]any{"code": code, "message": message})
+}
+
+func (s *Server) validKey(key string) bool {
+	return key != "" && key == s.getFixtures().APIKey
+}
+
+// decodeBody reads a JSON body of a POST request
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeCode(w, -1, fmt.Sprintf("Invalid JSON body: %v", err))
+		return false
+	}
+	return true
+}
+
+// decodeRequest reads a JSON body and rejects it unless it carries the API key, like C3ntrala does
+func (s *Server) decodeRequest(w http.ResponseWriter, r *http.Request, v any) bool {
+	var body json.RawMessage
+	if !decodeBody(w, r, &body) {
+		return false
+	}
+
+	var auth struct {
+		APIKey string `json:"apikey"`
+	}
+	json.Unmarshal(body, &auth)
+	if !s.validKey(auth.APIKey) {
+		logger.Warn("request rejected", "path", r.URL.Path, "reason", "invalid API key")
+		writeJSON(w, http.StatusUnauthorized, map[string]any{"code": -2, "message": "Invalid API key"})
+		return false
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		writeCode(w, -1, fmt.Sprintf("Invalid request: %v", err))
+		return false
+	}
+	return true
+}
+
+// handleData serves /data/<apikey>/<file> from the data directory
+func (s *Server) handleData(w http.ResponseWriter, r *http.Request) {
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/data/"), "/", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if !s.validKey(parts[0]) {
+		http.Error(w, "Invalid API key", http.StatusForbidden)
+		return
+	}
+
+	serveFixture(w, r, s.fixturePath("data", parts[1]))
+}
+
+// handleDane serves the public /dane/<file> from the dane directory
+func (s *Server) handleDane(w http.ResponseWriter, r *http.Request) {
+	serveFixture(w, r, s.fixturePath("dane", strings.TrimPrefix(r.URL.Path, "/dane/")))
+}
+
+// fixturePath returns the path of a requested file, kept inside the fixture directory
+func (s *Server) fixturePath(dir, name string) string {
+	return filepath.Join(s.dir, dir, filepath.FromSlash(filepath.Clean("/"+name)))
+}
+
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Task   string          `json:"task"`
+		Answer json.RawMessage `json:"answer"`
+	}
+	if !s.decodeRequest(w, r, &request) {
+		return
+	}
+
+	script, ok := s.getFixtures().Tasks[request.Task]
+	if !ok {
+		writeCode(w, -3, fmt.Sprintf("Unknown task: %s", request.Task))
+		return
+	}
+
+	rule := matchRule(script, request.Answer)
+	logger.Info("report", logging.KeyTask, request.Task, "answer", string(request.Answer), "code", rule.Code, "message", rule.Message)
+
+	response := map[string]any{"code": rule.Code, "message": rule.Message}
+	if rule.Hint != "" {
+		response["hint"] = rule.Hint
+	}
+	if rule.Debug != "" {
+		response["debug"] = rule.Debug
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// matchRule returns the first rule matching the answer, the default rule or a generic rejection
+func matchRule(script TaskScript, answer json.RawMessage) ReportRule {
+	var answerValue any
+	json.Unmarshal(answer, &answerValue)
+	answerText := string(answer)
+	if s, ok := answerValue.(string); ok {
+		answerText = s
+	}
+
+	for _, rule := range script.Rules {
+		if len(rule.Answer) > 0 {
+			var ruleValue any
+			if err := json.Unmarshal(rule.Answer, &ruleValue); err == nil && jsonEqual(ruleValue, answerValue) {
+				return rule
+			}
+		}
+		if rule.Pattern != "" {
+			if re, err := regexp.Compile(rule.Pattern); err == nil && re.MatchString(answerText) {
+				return rule
+			}
+		}
+	}
+
+	if script.Default != nil {
+		return *script.Default
+	}
+	return ReportRule{Code: -1, Message: "Answer is incorrect"}
+}
+
+// jsonEqual compares decoded JSON values, treating strings case- and diacritics-insensitively
+// and numbers as equal to their string form, e.g. 4278 and "4278". Arrays match in any order.
+func jsonEqual(a, b any) bool {
+	switch va := a.(type) {
+	case []any:
+		vb, ok := b.([]any)
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+		used := make([]bool, len(vb))
+	items:
+		for _, item := range va {
+			for i, other := range vb {
+				if !used[i] && jsonEqual(item, other) {
+					used[i] = true
+					continue items
+				}
+			}
+			return false
+		}
+		return true
+	case map[string]any:
+		vb, ok := b.(map[string]any)
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+		for key, value := range va {
+			other, ok := vb[key]
+			if !ok || !jsonEqual(value, other) {
+				return false
+			}
+		}
+		return true
+	case string, float64:
+		switch b.(type) {
+		case string, float64:
+			return textpl.EqualFold(fmt.Sprint(a), fmt.Sprint(b))
+		}
+		return false
+	}
+	return a == b
+}
+
+// handleSightings answers /people and /places queries from the given map
+func (s *Server) handleSightings(w http.ResponseWriter, r *http.Request, data map[string][]string) {
+	var request struct {
+		Query string `json:"query"`
+	}
+	if !s.decodeRequest(w, r, &request) {
+		return
+	}
+
+	for key, values := range data {
+		if textpl.EqualFold(key, request.Query) {
+			writeCode(w, 0, strings.Join(values, " "))
+			return
+		}
+	}
+	writeCode(w, -100, fmt.Sprintf("No data for: %s", request.Query))
+}
+
+func (s *Server) handlePeople(w http.ResponseWriter, r *http.Request) {
+	s.handleSightings(w, r, s.getFixtures().People)
+}
+
+func (s *Server) handlePlaces(w http.ResponseWriter, r *http.Request) {
+	s.handleSightings(w, r, s.getFixtures().Places)
+}
+
+// handleGPS answers /gps, which like the real endpoint takes no API key
+func (s *Server) handleGPS(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserID string `json:"userID"`
+	}
+	if !decodeBody(w, r, &request) {
+		return
+	}
+
+	coordinates, ok := s.getFixtures().GPS[request.UserID]
+	if !ok {
+		writeCode(w, -1, fmt.Sprintf("Unknown userID: %s", request.UserID))
+		return
+	}
+	writeCode(w, 0, coordinates)
+}
+
+// handleAPIDB answers a small SQL subset: SHOW TABLES, SHOW CREATE TABLE and SELECT with JOIN ... ON and WHERE ... AND
+func (s *Server) handleAPIDB(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Query string `json:"query"`
+	}
+	if !s.decodeRequest(w, r, &request) {
+		return
+	}
+
+	reply, err := s.getFixtures().APIDB.query(strings.TrimSpace(request.Query))
+	if err != nil {
+		writeJSON(w, http.StatusOK, map[string]any{"reply": nil, "error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"reply": reply, "error": "OK"})
+}
@@ -0,0 +1,271 @@
+package mock
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const fixtureDir = "../../fixtures/c3ntrala"
+
+func newTestServer(t *testing.T) (*Server, *httptest.Server) {
+	t.Helper()
+	server, err := NewServer(fixtureDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+	return server, ts
+}
+
+func post(t *testing.T, url string, body any) (int, map[string]any) {
+	t.Helper()
+	data, _ := json.Marshal(body)
+	resp, err := http.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var reply map[string]any
+	json.NewDecoder(resp.Body).Decode(&reply)
+	return resp.StatusCode, reply
+}
+
+func TestAPIKey(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	tests := []struct {
+		name     string
+		path     string
+		body     map[string]any
+		wantCode float64
+	}{
+		{"report without key", "/report", map[string]any{"task": "loop", "answer": "ELBLAG"}, -2},
+		{"report with wrong key", "/report", map[string]any{"task": "loop", "answer": "ELBLAG", "apikey": "wrong"}, -2},
+		{"report with key", "/report", map[string]any{"task": "loop", "answer": "ELBLAG", "apikey": "mock-api-key"}, 0},
+		{"people with wrong key", "/people", map[string]any{"query": "BARBARA", "apikey": "wrong"}, -2},
+		{"places with key", "/places", map[string]any{"query": "ELBLAG", "apikey": "mock-api-key"}, 0},
+		{"gps takes no key", "/gps", map[string]any{"userID": "28"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, reply := post(t, ts.URL+tt.path, tt.body)
+			if reply["code"] != tt.wantCode {
+				t.Errorf("got code %v (status %d), want %v", reply["code"], status, tt.wantCode)
+			}
+			if tt.wantCode == -2 && status != http.StatusUnauthorized {
+				t.Errorf("got status %d, want %d", status, http.StatusUnauthorized)
+			}
+		})
+	}
+
+	for _, key := range []string{"", "wrong"} {
+		resp, err := http.Get(ts.URL + "/data/" + key + "/cenzura.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			t.Errorf("data served for API key %q", key)
+		}
+	}
+}
+
+func TestReloadRequiresPost(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/reload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET /reload: got status %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+
+	resp, err = http.Post(ts.URL+"/reload", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("POST /reload: got status %d, want %d", resp.StatusCode, http.StatusNoContent)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	fixtures, err := LoadFixtures(fixtureDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query   string
+		column  string
+		want    []string
+		wantErr bool
+	}{
+		{"SELECT id FROM users WHERE username = \"RAFAL\"", "id", []string{"28"}, false},
+		{"select `username` from `users` where is_active = 0;", "username", []string{"Monika", "Zygfryd"}, false},
+		{"SELECT d.dc_id FROM datacenters d JOIN users u ON d.manager = u.id WHERE d.is_active = 1 AND u.is_active = 0", "dc_id", []string{"4278", "9294"}, false},
+		{"SELECT datacenters.dc_id AS id FROM datacenters INNER JOIN users ON datacenters.manager = users.id WHERE users.username = 'Rafał'", "id", []string{"8376"}, false},
+		{"SELECT u2.username FROM connections c JOIN users u1 ON c.user1_id = u1.id JOIN users u2 ON c.user2_id = u2.id WHERE u1.username = 'Azazel'", "username", []string{"Aleksander"}, false},
+		{"SELECT dc_id FROM datacenters WHERE is_active != 1", "dc_id", []string{"5555"}, false},
+		{"SELECT id FROM missing", "", nil, true},
+		{"SELECT nope FROM users", "", nil, true},
+		{"DELETE FROM users", "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			reply, err := fixtures.APIDB.query(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("query() error = %v, want error %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, row := range reply {
+				got = append(got, row[tt.column].(string))
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONEqual(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{`"Elbląg"`, `"ELBLAG"`, true},
+		{`[4278, 9294]`, `["9294", "4278"]`, true},
+		{`[4278, 4278]`, `[4278, 9294]`, false},
+		{`{"a": {"lat": 53.5}}`, `{"a": {"lat": "53.5"}}`, true},
+		{`{"a": 1}`, `{"a": 1, "b": 2}`, false},
+		{`true`, `"true"`, false},
+	}
+	for _, tt := range tests {
+		var a, b any
+		json.Unmarshal([]byte(tt.a), &a)
+		json.Unmarshal([]byte(tt.b), &b)
+		if got := jsonEqual(a, b); got != tt.want {
+			t.Errorf("jsonEqual(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// TestFixturesAreCoherent follows the episodes through the fixtures to the answers their rules accept
+func TestFixturesAreCoherent(t *testing.T) {
+	fixtures, err := LoadFixtures(fixtureDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	accepted := func(task string, answer any) bool {
+		data, _ := json.Marshal(answer)
+		return matchRule(fixtures.Tasks[task], data).Code == 0
+	}
+
+	t.Run("loop", func(t *testing.T) {
+		// breadth-first from the people of barbara.txt to a new place where BARBARA was seen
+		known := map[string]bool{"KRAKOW": true, "WARSZAWA": true}
+		queue := []string{"BARBARA", "ALEKSANDER", "ANDRZEJ", "RAFAL"}
+		seen := map[string]bool{}
+		var found string
+		for len(queue) > 0 && found == "" {
+			person := queue[0]
+			queue = queue[1:]
+			for _, place := range fixtures.People[person] {
+				if seen[place] {
+					continue
+				}
+				seen[place] = true
+				for _, name := range fixtures.Places[place] {
+					if name == "BARBARA" && !known[place] {
+						found = place
+					}
+					queue = append(queue, name)
+				}
+			}
+		}
+		if !accepted("loop", found) {
+			t.Errorf("BARBARA found in %q, which the loop rules reject", found)
+		}
+	})
+
+	t.Run("database", func(t *testing.T) {
+		reply, err := fixtures.APIDB.query("SELECT dc_id FROM datacenters JOIN users ON manager = id WHERE datacenters.is_active = 1 AND users.is_active = 0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []any
+		for _, row := range reply {
+			ids = append(ids, row["dc_id"])
+		}
+		if !accepted("database", ids) {
+			t.Errorf("datacenters %v rejected by the database rules", ids)
+		}
+	})
+
+	t.Run("gps", func(t *testing.T) {
+		locations := map[string]Coordinates{}
+		for _, name := range fixtures.Places["LUBAWA"] {
+			reply, err := fixtures.APIDB.query(`SELECT id FROM users WHERE username = "` + name + `"`)
+			if err != nil || len(reply) != 1 {
+				t.Fatalf("no user %s: %v", name, err)
+			}
+			coordinates, ok := fixtures.GPS[reply[0]["id"].(string)]
+			if !ok {
+				t.Fatalf("no coordinates of %s", name)
+			}
+			locations[name] = coordinates
+		}
+		if !accepted("gps", locations) {
+			t.Errorf("locations %v rejected by the gps rules", locations)
+		}
+	})
+}
+
+func TestServeZippedDirectory(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/data/mock-api-key/pliki_z_fabryki.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d", resp.StatusCode)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]bool{}
+	for _, file := range archive.File {
+		names[file.Name] = true
+	}
+	for _, want := range []string{"facts/", "facts/f01.txt", "do-not-share/2024_02_21.txt"} {
+		if !names[want] {
+			t.Errorf("%s missing from the archive", want)
+		}
+	}
+
+	for _, path := range []string{"/dane/przesluchania", "/data/mock-api-key/missing.zip", "/dane/../mock.json"} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("GET %s: got status %d, want %d", path, resp.StatusCode, http.StatusNotFound)
+		}
+	}
+}
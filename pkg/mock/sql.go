@@ -0,0 +1,295 @@
+package mock
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/crowmw/ai_devs3/pkg/textpl"
+)
+
+var (
+	selectPattern    = regexp.MustCompile(`(?is)^select\s+(.+?)\s+from\s+(.+?)(?:\s+where\s+(.+?))?\s*;?$`)
+	showCreate       = regexp.MustCompile(`(?i)^show\s+create\s+table\s+` + "`?" + `(\w+)` + "`?" + `\s*;?$`)
+	showTables       = regexp.MustCompile(`(?i)^show\s+tables\s*;?$`)
+	joinSeparator    = regexp.MustCompile(`(?i)\s+(?:inner\s+)?join\s+`)
+	joinPattern      = regexp.MustCompile(`(?is)^(.+?)\s+on\s+(.+)$`)
+	tablePattern     = regexp.MustCompile(`(?i)^` + "`?" + `(\w+)` + "`?" + `(?:\s+(?:as\s+)?` + "`?" + `(\w+)` + "`?" + `)?$`)
+	andSeparator     = regexp.MustCompile(`(?i)\s+and\s+`)
+	conditionPattern = regexp.MustCompile(`^(.+?)\s*(!=|<>|=)\s*(.+)$`)
+	aliasPattern     = regexp.MustCompile(`(?i)^(.+?)\s+as\s+` + "`?" + `(\w+)` + "`?" + `$`)
+)
+
+// selectQuery is a parsed SELECT of the SQL subset served by /apidb
+type selectQuery struct {
+	// columns is nil for SELECT *
+	columns []selectColumn
+	from    tableRef
+	joins   []joinClause
+	where   []condition
+}
+
+type selectColumn struct {
+	// ref is a column reference, e.g. "dc_id" or "d.dc_id"
+	ref string
+	// name is the key of the column in the reply
+	name string
+}
+
+type tableRef struct {
+	name  string
+	alias string
+}
+
+type joinClause struct {
+	table tableRef
+	on    []condition
+}
+
+// condition compares two operands, each a column reference or a literal
+type condition struct {
+	left  string
+	op    string
+	right string
+}
+
+// parseSelect parses SELECT columns FROM table [[INNER] JOIN table ON a = b ...] [WHERE a = b [AND ...]]
+func parseSelect(query string) (*selectQuery, error) {
+	matches := selectPattern.FindStringSubmatch(query)
+	if matches == nil {
+		return nil, fmt.Errorf("query not supported by mock: %s", query)
+	}
+
+	q := &selectQuery{}
+	if strings.TrimSpace(matches[1]) != "*" {
+		for _, column := range strings.Split(matches[1], ",") {
+			column = strings.TrimSpace(column)
+			ref, name := column, column
+			if alias := aliasPattern.FindStringSubmatch(column); alias != nil {
+				ref, name = strings.TrimSpace(alias[1]), alias[2]
+			} else if i := strings.LastIndex(column, "."); i >= 0 {
+				name = column[i+1:]
+			}
+			q.columns = append(q.columns, selectColumn{ref: columnRef(ref), name: strings.Trim(name, "`")})
+		}
+	}
+
+	tables := joinSeparator.Split(strings.TrimSpace(matches[2]), -1)
+	from, err := parseTable(tables[0])
+	if err != nil {
+		return nil, err
+	}
+	q.from = from
+	for _, join := range tables[1:] {
+		parts := joinPattern.FindStringSubmatch(join)
+		if parts == nil {
+			return nil, fmt.Errorf("JOIN without ON not supported by mock: %s", join)
+		}
+		table, err := parseTable(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		on, err := parseConditions(parts[2])
+		if err != nil {
+			return nil, err
+		}
+		q.joins = append(q.joins, joinClause{table: table, on: on})
+	}
+
+	if matches[3] != "" {
+		if q.where, err = parseConditions(matches[3]); err != nil {
+			return nil, err
+		}
+	}
+	return q, nil
+}
+
+func parseTable(text string) (tableRef, error) {
+	matches := tablePattern.FindStringSubmatch(strings.TrimSpace(text))
+	if matches == nil {
+		return tableRef{}, fmt.Errorf("table not supported by mock: %s", text)
+	}
+	table := tableRef{name: matches[1], alias: matches[2]}
+	if table.alias == "" {
+		table.alias = table.name
+	}
+	return table, nil
+}
+
+func parseConditions(text string) ([]condition, error) {
+	var conditions []condition
+	for _, part := range andSeparator.Split(strings.TrimSpace(text), -1) {
+		matches := conditionPattern.FindStringSubmatch(strings.Trim(strings.TrimSpace(part), "()"))
+		if matches == nil {
+			return nil, fmt.Errorf("condition not supported by mock: %s", part)
+		}
+		conditions = append(conditions, condition{left: strings.TrimSpace(matches[1]), op: matches[2], right: strings.TrimSpace(matches[3])})
+	}
+	return conditions, nil
+}
+
+// columnRef normalizes a column reference for lookups in a row
+func columnRef(ref string) string {
+	return strings.ToLower(strings.ReplaceAll(ref, "`", ""))
+}
+
+// row is a joined row, holding every column both qualified ("alias.column") and unqualified
+type row struct {
+	values map[string]any
+	// columns holds the unqualified columns with their original names for SELECT *, the first table wins
+	columns map[string]any
+}
+
+func newRow() row {
+	return row{values: map[string]any{}, columns: map[string]any{}}
+}
+
+// with returns a copy of the row extended by the columns of a table row
+func (r row) with(table tableRef, columns map[string]any) row {
+	extended := newRow()
+	for key, value := range r.values {
+		extended.values[key] = value
+	}
+	for key, value := range r.columns {
+		extended.columns[key] = value
+	}
+	for column, value := range columns {
+		extended.values[strings.ToLower(table.alias+"."+column)] = value
+		if _, ok := extended.values[strings.ToLower(column)]; !ok {
+			extended.values[strings.ToLower(column)] = value
+		}
+		if _, ok := extended.columns[column]; !ok {
+			extended.columns[column] = value
+		}
+	}
+	return extended
+}
+
+// operand returns the value of a literal or of a column of the row
+func (r row) operand(text string) (any, error) {
+	if len(text) >= 2 && (text[0] == '\'' || text[0] == '"') && text[len(text)-1] == text[0] {
+		return text[1 : len(text)-1], nil
+	}
+	if value, ok := r.values[columnRef(text)]; ok {
+		return value, nil
+	}
+	if strings.Trim(text, "-0123456789.") == "" {
+		return text, nil
+	}
+	return nil, fmt.Errorf("Unknown column '%s' in 'where clause'", text)
+}
+
+// matches reports whether the row meets all conditions
+func (r row) matches(conditions []condition) (bool, error) {
+	for _, c := range conditions {
+		left, err := r.operand(c.left)
+		if err != nil {
+			return false, err
+		}
+		right, err := r.operand(c.right)
+		if err != nil {
+			return false, err
+		}
+		if textpl.EqualFold(fmt.Sprint(left), fmt.Sprint(right)) != (c.op == "=") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (db APIDBFixture) query(query string) ([]map[string]any, error) {
+	for scripted, reply := range db.Queries {
+		if textpl.EqualFold(strings.TrimSuffix(scripted, ";"), strings.TrimSuffix(query, ";")) {
+			return reply, nil
+		}
+	}
+
+	if showTables.MatchString(query) {
+		var names []string
+		for name := range db.Tables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		reply := []map[string]any{}
+		for _, name := range names {
+			reply = append(reply, map[string]any{"Tables_in_banan": name})
+		}
+		return reply, nil
+	}
+
+	if matches := showCreate.FindStringSubmatch(query); matches != nil {
+		schema, ok := db.Schemas[matches[1]]
+		if !ok {
+			return nil, fmt.Errorf("Table 'banan.%s' doesn't exist", matches[1])
+		}
+		return []map[string]any{{"Table": matches[1], "Create Table": schema}}, nil
+	}
+
+	q, err := parseSelect(query)
+	if err != nil {
+		return nil, err
+	}
+	return db.run(q)
+}
+
+// run evaluates a parsed SELECT as nested loop joins over the fixture tables
+func (db APIDBFixture) run(q *selectQuery) ([]map[string]any, error) {
+	rows, err := db.scan(q.from, []row{newRow()}, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, join := range q.joins {
+		if rows, err = db.scan(join.table, rows, join.on); err != nil {
+			return nil, err
+		}
+	}
+
+	reply := []map[string]any{}
+	for _, r := range rows {
+		ok, err := r.matches(q.where)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if q.columns == nil {
+			reply = append(reply, r.columns)
+			continue
+		}
+		selected := make(map[string]any, len(q.columns))
+		for _, column := range q.columns {
+			value, ok := r.values[column.ref]
+			if !ok {
+				return nil, fmt.Errorf("Unknown column '%s' in 'field list'", column.ref)
+			}
+			selected[column.name] = value
+		}
+		reply = append(reply, selected)
+	}
+	return reply, nil
+}
+
+// scan joins every row with the rows of the table that meet the conditions
+func (db APIDBFixture) scan(table tableRef, rows []row, on []condition) ([]row, error) {
+	tableRows, ok := db.Tables[table.name]
+	if !ok {
+		return nil, fmt.Errorf("Table 'banan.%s' doesn't exist", table.name)
+	}
+
+	var joined []row
+	for _, r := range rows {
+		for _, tableRow := range tableRows {
+			candidate := r.with(table, tableRow)
+			ok, err := candidate.matches(on)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				joined = append(joined, candidate)
+			}
+		}
+	}
+	return joined, nil
+}
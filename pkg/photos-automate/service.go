@@ -1,12 +1,13 @@
 package photosautomate
 
 import (
-	"fmt"
-
 	"github.com/crowmw/ai_devs3/pkg/c3ntrala"
 	"github.com/crowmw/ai_devs3/pkg/env"
+	"github.com/crowmw/ai_devs3/pkg/logging"
 )
 
+var logger = logging.New("photos")
+
 type Service struct {
 	envSvc      *env.Service
 	c3ntralaSvc *c3ntrala.Service
@@ -19,7 +20,7 @@ func NewService(envSvc *env.Service, c3ntralaSvc *c3ntrala.Service) (*Service, e
 		return nil, err
 	}
 
-	fmt.Println("❇️ Photos:", photos)
+	logger.Info("photos loaded", "photos", photos)
 	return &Service{envSvc: envSvc, c3ntralaSvc: c3ntralaSvc, photos: photos}, nil
 }
 
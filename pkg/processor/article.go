@@ -8,7 +8,7 @@ import (
 	"strings"
 
 	"github.com/crowmw/ai_devs3/pkg/ai"
-	"github.com/crowmw/ai_devs3/pkg/config"
+	"github.com/crowmw/ai_devs3/pkg/env"
 	htmlpkg "github.com/crowmw/ai_devs3/pkg/html"
 	"github.com/crowmw/ai_devs3/pkg/media"
 	"github.com/sashabaranov/go-openai"
@@ -17,16 +17,18 @@ import (
 
 // ArticleProcessor handles processing of HTML articles
 type ArticleProcessor struct {
+	aiSvc     *ai.Service
 	outputDir string
 	htmlProc  *htmlpkg.Processor
 	mediaProc *media.Processor
 }
 
 // NewArticleProcessor creates a new ArticleProcessor instance
-func NewArticleProcessor(outputDir string) *ArticleProcessor {
-	htmlProc := htmlpkg.NewProcessor(config.GetC3ntralaURL())
-	mediaProc := media.NewProcessor(outputDir, htmlProc)
+func NewArticleProcessor(envSvc *env.Service, aiSvc *ai.Service, outputDir string) *ArticleProcessor {
+	htmlProc := htmlpkg.NewProcessor(envSvc.GetC3ntralaURL())
+	mediaProc := media.NewProcessor(aiSvc, outputDir, htmlProc)
 	return &ArticleProcessor{
+		aiSvc:     aiSvc,
 		outputDir: outputDir,
 		htmlProc:  htmlProc,
 		mediaProc: mediaProc,
@@ -114,7 +116,7 @@ func (ap *ArticleProcessor) ProcessQuestions(articleText string, questions []str
 		},
 	}
 
-	aiResponse, err := ai.SendChatCompletion("gpt-4o", false, questionsPrompt)
+	aiResponse, err := ap.aiSvc.SendChatCompletion("gpt-4o", false, questionsPrompt)
 	if err != nil {
 		return nil, fmt.Errorf("error sending chat completion: %w", err)
 	}
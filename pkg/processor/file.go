@@ -10,11 +10,13 @@ import (
 
 	"github.com/antchfx/htmlquery"
 	"github.com/crowmw/ai_devs3/pkg/ai"
-	"github.com/crowmw/ai_devs3/pkg/config"
 	httpclient "github.com/crowmw/ai_devs3/pkg/http"
+	"github.com/crowmw/ai_devs3/pkg/logging"
 	"golang.org/x/net/html"
 )
 
+var logger = logging.New("processor")
+
 // createTempFileWithData creates a temporary file and writes the provided data to it
 // Returns the temporary file name and cleanup function
 func createTempFileWithData(data []byte, prefix string) (string, func(), error) {
@@ -58,7 +60,7 @@ func ExtractZipToDirectory(zipData []byte, dirName string) error {
 
 	// Check if the directory already exists
 	if _, err := os.Stat(dirName); err == nil {
-		fmt.Println("Audio files already extracted, skipping extraction.")
+		logger.Info("audio files already extracted, skipping extraction", "dir", dirName)
 		return nil
 	}
 
@@ -102,36 +104,36 @@ func ExtractZipToDirectory(zipData []byte, dirName string) error {
 		}
 	}
 
-	fmt.Println("Audio files extracted to:", dirName)
+	logger.Info("audio files extracted", "dir", dirName)
 
 	return nil
 }
 
 // TranscribeAudioFile transcribes a single audio file and saves the transcription
 // Returns the path to the saved transcription file
-func TranscribeAudioFile(audioPath string, outputDir string) (string, error) {
+func TranscribeAudioFile(aiSvc *ai.Service, audioPath string, outputDir string) (string, error) {
 	// Get the base name without extension
 	baseName := strings.TrimSuffix(filepath.Base(audioPath), filepath.Ext(audioPath))
-	fmt.Println("Processing audio file:", baseName+filepath.Ext(audioPath))
+	logger.Info("processing audio file", "file", baseName+filepath.Ext(audioPath))
 
 	// Set up transcription file path
 	transcriptionPath := filepath.Join(outputDir, baseName+".txt")
 
 	// Check if transcription already exists
 	if _, err := os.Stat(transcriptionPath); err == nil {
-		fmt.Println("Transcription already exists for:", baseName)
+		logger.Debug("transcription already exists", "file", baseName)
 		return transcriptionPath, nil
 	}
 
 	// Transcribe the audio file
-	transcription, err := ai.TranscribeAudio(audioPath)
+	transcription, err := aiSvc.TranscribeAudio(audioPath)
 	if err != nil {
 		return "", fmt.Errorf("error transcribing file %s: %w", audioPath, err)
 	}
 
 	// Save the transcription
-	fmt.Println("Transcription:", transcription)
-	fmt.Println("Transcription path:", transcriptionPath)
+	logger.Info("saving transcription", "path", transcriptionPath)
+	logging.Dump(logger, "transcription", "text", transcription)
 	if err := os.WriteFile(transcriptionPath, []byte(transcription), 0644); err != nil {
 		return "", fmt.Errorf("error saving transcription for %s: %w", audioPath, err)
 	}
@@ -140,11 +142,11 @@ func TranscribeAudioFile(audioPath string, outputDir string) (string, error) {
 }
 
 // ProcessAudioFiles transcribes audio files and saves transcriptions
-func ProcessAudioFiles(hashDir string) error {
+func ProcessAudioFiles(aiSvc *ai.Service, hashDir string) error {
 	// Check if transcriptions directory exists
 	transcriptionsDir := filepath.Join(hashDir, "transcriptions")
 	if _, err := os.Stat(transcriptionsDir); err == nil {
-		fmt.Println("Transcriptions directory already exists, skipping processing.")
+		logger.Info("transcriptions directory already exists, skipping processing", "dir", transcriptionsDir)
 		return nil
 	}
 
@@ -165,7 +167,7 @@ func ProcessAudioFiles(hashDir string) error {
 		}
 
 		// Transcribe the audio file
-		_, err = TranscribeAudioFile(path, transcriptionsDir)
+		_, err = TranscribeAudioFile(aiSvc, path, transcriptionsDir)
 		if err != nil {
 			return fmt.Errorf("error processing file %s: %w", path, err)
 		}
@@ -185,14 +187,15 @@ func CreateTranscriptionDirectory(dirPath string) error {
 	transcriptionsDir := filepath.Join(dirPath, "transcriptions")
 	// Check if transcription already exists
 	if _, err := os.Stat(transcriptionsDir); err == nil {
-		fmt.Println("Transcription already exists for:", transcriptionsDir)
+		logger.Debug("transcriptions already exist", "dir", transcriptionsDir)
 		return nil
 	}
 	return os.Mkdir(transcriptionsDir, 0755)
 }
 
-// ProcessAudioElements processes audio elements in HTML and replaces them with transcriptions
-func ProcessAudioElements(doc *html.Node, outputDir string) error {
+// ProcessAudioElements processes audio elements in HTML and replaces them with transcriptions.
+// Relative sources are resolved against baseURL.
+func ProcessAudioElements(aiSvc *ai.Service, baseURL string, doc *html.Node, outputDir string) error {
 	// Find all audio elements
 	audioNodes := htmlquery.Find(doc, "//source")
 	for i, node := range audioNodes {
@@ -203,10 +206,10 @@ func ProcessAudioElements(doc *html.Node, outputDir string) error {
 
 		// Handle relative URLs
 		if !strings.HasPrefix(src, "http") {
-			src = config.GetC3ntralaURL() + "/dane/" + src
+			src = baseURL + "/dane/" + src
 		}
 
-		fmt.Println("Fetching audio:", src)
+		logger.Info("fetching audio", "url", src)
 		// Fetch audio
 		audioData, err := httpclient.FetchData(src)
 		if err != nil {
@@ -225,9 +228,9 @@ func ProcessAudioElements(doc *html.Node, outputDir string) error {
 			return fmt.Errorf("error saving audio %s: %w", audioPath, err)
 		}
 
-		fmt.Println("Transcribing audio:", audioPath)
+		logger.Info("transcribing audio", "file", audioPath)
 		// Transcribe audio
-		transcription, err := ai.TranscribeAudioAndFormat(audioPath)
+		transcription, err := aiSvc.TranscribeAudioAndFormat(audioPath)
 		if err != nil {
 			return fmt.Errorf("error transcribing audio %s: %w", audioPath, err)
 		}
@@ -249,8 +252,9 @@ func ProcessAudioElements(doc *html.Node, outputDir string) error {
 	return nil
 }
 
-// ProcessImageElements processes image elements in HTML and replaces them with descriptions
-func ProcessImageElements(doc *html.Node, outputDir string) error {
+// ProcessImageElements processes image elements in HTML and replaces them with descriptions.
+// Relative sources are resolved against baseURL.
+func ProcessImageElements(aiSvc *ai.Service, baseURL string, doc *html.Node, outputDir string) error {
 	// Find all image elements
 	imageNodes := htmlquery.Find(doc, "//img")
 	for i, node := range imageNodes {
@@ -261,11 +265,11 @@ func ProcessImageElements(doc *html.Node, outputDir string) error {
 
 		// Handle relative URLs
 		if !strings.HasPrefix(src, "http") {
-			src = config.GetC3ntralaURL() + "/dane/" + src
+			src = baseURL + "/dane/" + src
 		}
 
 		// Fetch image
-		fmt.Println("Fetching image:", src)
+		logger.Info("fetching image", "url", src)
 		imageData, err := httpclient.FetchData(src)
 		if err != nil {
 			return fmt.Errorf("error fetching image %s: %w", src, err)
@@ -284,7 +288,7 @@ func ProcessImageElements(doc *html.Node, outputDir string) error {
 		}
 
 		// Read image to base64
-		fmt.Println("Reading image to base64:", imagePath)
+		logger.Debug("reading image to base64", "file", imagePath)
 		imageBase64, err := ReadImageToBase64(imagePath)
 		if err != nil {
 			return fmt.Errorf("error reading image %s: %w", imagePath, err)
@@ -294,7 +298,7 @@ func ProcessImageElements(doc *html.Node, outputDir string) error {
 		format := strings.TrimPrefix(strings.ToLower(filepath.Ext(imagePath)), ".")
 
 		// Describe image
-		imageDescription, err := ai.DescribeImageAndFormat(imageBase64, format)
+		imageDescription, err := aiSvc.DescribeImageAndFormat(imageBase64, format)
 		if err != nil {
 			return fmt.Errorf("error describing image %s: %w", imagePath, err)
 		}
@@ -51,14 +51,13 @@ func NewPDFService(pdfURL string, aiSvc *ai.Service) (*PDFService, error) {
 // GetText extracts all text from the PDF file
 func (s *PDFService) GetText() (string, error) {
 	// Download PDF file
-	fmt.Println("Downloading PDF file...")
+	logger.Info("downloading PDF file", "url", s.pdfURL)
 	resp, err := s.client.Get(s.pdfURL)
 	if err != nil {
 		return "", fmt.Errorf("failed to download PDF: %w", err)
 	}
 	defer resp.Body.Close()
 
-	fmt.Println("Creating PDF file...")
 	pdfPath := filepath.Join(s.tempDir, "document.pdf")
 	file, err := os.Create(pdfPath)
 	if err != nil {
@@ -71,7 +70,7 @@ func (s *PDFService) GetText() (string, error) {
 		return "", fmt.Errorf("failed to save PDF file: %w", err)
 	}
 
-	fmt.Println("Extracting text from PDF file...")
+	logger.Debug("extracting text from PDF file", "path", pdfPath)
 	// Open the PDF document
 	doc, err := fitz.New(pdfPath)
 	if err != nil {
@@ -80,10 +79,9 @@ func (s *PDFService) GetText() (string, error) {
 	defer doc.Close()
 
 	// Extract text from each page
-	fmt.Println("Extracting text from each page...")
 	text := ""
 	for i := 0; i < doc.NumPage(); i++ {
-		fmt.Println("Extracting page", i+1)
+		logger.Debug("extracting page", "page", i+1)
 		pageText, err := doc.Text(i)
 		if err != nil {
 			return "", fmt.Errorf("failed to extract text from page %d: %w", i+1, err)
@@ -91,7 +89,6 @@ func (s *PDFService) GetText() (string, error) {
 		text += pageText + "\n"
 	}
 
-	fmt.Println("Returning text...")
 	return text, nil
 }
 
@@ -127,7 +124,7 @@ func (s *PDFService) ExtractTextFromPage(pageNumber int) (string, error) {
 	// Check if text file already exists
 	textPath := filepath.Join(pageDir, "text.txt")
 	if text, err := os.ReadFile(textPath); err == nil {
-		fmt.Println("Using cached text for page", pageNumber)
+		logger.Debug("using cached text", "page", pageNumber)
 		return string(text), nil
 	}
 
@@ -144,13 +141,12 @@ func (s *PDFService) ExtractTextFromPage(pageNumber int) (string, error) {
 	}
 
 	// Extract the page as image
-	fmt.Println("Extracting page as image...")
+	logger.Info("extracting page as image", "page", pageNumber)
 	img, err := doc.Image(pageNumber - 1) // go-fitz uses 0-based indexing
 	if err != nil {
 		return "", fmt.Errorf("failed to extract page as image: %w", err)
 	}
 
-	fmt.Println("Saving image...")
 	// Save the image
 	imagePath := filepath.Join(pageDir, "page.png")
 	file, err := os.Create(imagePath)
@@ -163,7 +159,7 @@ func (s *PDFService) ExtractTextFromPage(pageNumber int) (string, error) {
 		return "", fmt.Errorf("failed to save image: %w", err)
 	}
 
-	fmt.Println("Processing image with OCR...")
+	logger.Info("processing image with OCR", "page", pageNumber)
 	result, err := s.aiSvc.OCRImage(imagePath)
 	if err != nil {
 		return "", fmt.Errorf("failed to process image: %w", err)
@@ -4,9 +4,6 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
-	"sync"
-
-	"github.com/pkoukk/tiktoken-go"
 )
 
 // Headers represents a map of header levels to their content
@@ -25,87 +22,30 @@ type Doc struct {
 
 // TextSplitter handles splitting text into chunks with metadata
 type TextSplitter struct {
-	modelName     string
-	specialTokens map[string]int
-	tokenizer     *tiktoken.Tiktoken
-	mu            sync.Mutex
+	tokenizer *Tokenizer
 }
 
 // NewTextSplitter creates a new TextSplitter instance
 func NewTextSplitter(modelName string) *TextSplitter {
-	if modelName == "" {
-		modelName = "gpt-4o"
-	}
-
-	return &TextSplitter{
-		modelName: modelName,
-		specialTokens: map[string]int{
-			"<|im_start|>": 100264,
-			"<|im_end|>":   100265,
-			"<|im_sep|>":   100266,
-		},
-	}
-}
-
-// initializeTokenizer initializes the tokenizer if it hasn't been initialized yet
-func (ts *TextSplitter) initializeTokenizer() error {
-	ts.mu.Lock()
-	defer ts.mu.Unlock()
-
-	if ts.tokenizer != nil {
-		return nil
-	}
-
-	// Map model names to their encoding names
-	modelToEncoding := map[string]string{
-		"gpt-4o":        "cl100k_base",
-		"gpt-4":         "cl100k_base",
-		"gpt-3.5":       "cl100k_base",
-		"gpt-3.5-turbo": "cl100k_base",
-	}
-
-	encoding, ok := modelToEncoding[ts.modelName]
-	if !ok {
-		return fmt.Errorf("unsupported model: %s", ts.modelName)
-	}
-
-	tokenizer, err := tiktoken.GetEncoding(encoding)
-	if err != nil {
-		return fmt.Errorf("error getting encoding: %w", err)
-	}
-
-	ts.tokenizer = tokenizer
-	return nil
+	return &TextSplitter{tokenizer: NewTokenizer(modelName)}
 }
 
 // countTokens counts the number of tokens in the given text
 func (ts *TextSplitter) countTokens(text string) int {
-	if err := ts.initializeTokenizer(); err != nil {
-		// Fallback to approximation if tokenizer initialization fails
-		return len(text) / 4
-	}
-
-	// Add special tokens to the text
-	formattedText := ts.formatForTokenization(text)
-
-	// Count tokens
-	tokens := ts.tokenizer.Encode(formattedText, nil, nil)
-	return len(tokens)
+	return ts.tokenizer.Count(text)
 }
 
 // Split splits text into chunks with metadata
 func (ts *TextSplitter) Split(text string, limit int) ([]Doc, error) {
-	fmt.Printf("Starting split process with limit: %d tokens\n", limit)
+	logger.Debug("starting split", "limit", limit, "length", len(text))
 	chunks := []Doc{}
 	position := 0
 	totalLength := len(text)
 	currentHeaders := make(Headers)
 
 	for position < totalLength {
-		fmt.Printf("Processing chunk starting at position: %d\n", position)
 		chunkText, chunkEnd := ts.getChunk(text, position, limit)
 		tokens := ts.countTokens(chunkText)
-		fmt.Printf("Chunk tokens: %d\n", tokens)
 
 		headersInChunk := ts.extractHeaders(chunkText)
 		ts.updateCurrentHeaders(currentHeaders, headersInChunk)
@@ -127,17 +67,15 @@ func (ts *TextSplitter) Split(text string, limit int) ([]Doc, error) {
 			},
 		})
 
-		fmt.Printf("Chunk processed. New position: %d\n", chunkEnd)
+		logger.Debug("chunk processed", "start", position, "end", chunkEnd, "tokens", tokens)
 		position = chunkEnd
 	}
 
-	fmt.Printf("Split process completed. Total chunks: %d\n", len(chunks))
+	logger.Debug("split completed", "chunks", len(chunks))
 	return chunks, nil
 }
 
 func (ts *TextSplitter) getChunk(text string, start int, limit int) (string, int) {
-	fmt.Printf("Getting chunk starting at %d with limit %d\n", start, limit)
-
 	// Account for token overhead due to formatting
 	overhead := ts.countTokens(ts.formatForTokenization("")) - ts.countTokens("")
 
@@ -151,7 +89,7 @@ func (ts *TextSplitter) getChunk(text string, start int, limit int) (string, int
 	tokens := ts.countTokens(chunkText)
 
 	for tokens+overhead > limit && end > start {
-		fmt.Printf("Chunk exceeds limit with %d tokens. Adjusting end position...\n", tokens+overhead)
+		logger.Debug("chunk exceeds limit, adjusting end", "tokens", tokens+overhead, "limit", limit)
 		end = ts.findNewChunkEnd(text, start, end)
 		chunkText = text[start:end]
 		tokens = ts.countTokens(chunkText)
@@ -162,7 +100,6 @@ func (ts *TextSplitter) getChunk(text string, start int, limit int) (string, int
 
 	chunkText = text[start:end]
 	tokens = ts.countTokens(chunkText)
-	fmt.Printf("Final chunk end: %d\n", end)
 	return chunkText, end
 }
 
@@ -178,7 +115,7 @@ func (ts *TextSplitter) adjustChunkEnd(text string, start int, end int, currentT
 		chunkText := text[start:extendedEnd]
 		tokens := ts.countTokens(chunkText)
 		if tokens <= limit && tokens >= minChunkTokens {
-			fmt.Printf("Extending chunk to next newline at position %d\n", extendedEnd)
+			logger.Debug("extending chunk to next newline", "end", extendedEnd)
 			return extendedEnd
 		}
 	}
@@ -189,7 +126,7 @@ func (ts *TextSplitter) adjustChunkEnd(text string, start int, end int, currentT
 		chunkText := text[start:reducedEnd]
 		tokens := ts.countTokens(chunkText)
 		if tokens <= limit && tokens >= minChunkTokens {
-			fmt.Printf("Reducing chunk to previous newline at position %d\n", reducedEnd)
+			logger.Debug("reducing chunk to previous newline", "end", reducedEnd)
 			return reducedEnd
 		}
 	}
@@ -275,7 +212,7 @@ func (ts *TextSplitter) extractURLsAndImages(text string) (string, []string, []s
 }
 
 func (ts *TextSplitter) formatForTokenization(text string) string {
-	return fmt.Sprintf("<|im_start|>user\n%s<|im_end|>\n<|im_start|>assistant<|im_end|>", text)
+	return ts.tokenizer.formatForTokenization(text)
 }
 
 func (ts *TextSplitter) copyHeaders(headers Headers) Headers {
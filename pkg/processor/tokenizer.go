@@ -0,0 +1,84 @@
+package processor
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+)
+
+// Tokenizer counts tokens with the tiktoken encoding of a model, loaded on first use
+type Tokenizer struct {
+	modelName     string
+	specialTokens map[string]int
+	tokenizer     *tiktoken.Tiktoken
+	mu            sync.Mutex
+}
+
+// NewTokenizer creates a new Tokenizer instance
+func NewTokenizer(modelName string) *Tokenizer {
+	if modelName == "" {
+		modelName = "gpt-4o"
+	}
+
+	return &Tokenizer{
+		modelName: modelName,
+		specialTokens: map[string]int{
+			"<|im_start|>": 100264,
+			"<|im_end|>":   100265,
+			"<|im_sep|>":   100266,
+		},
+	}
+}
+
+// initialize initializes the tokenizer if it hasn't been initialized yet
+func (t *Tokenizer) initialize() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.tokenizer != nil {
+		return nil
+	}
+
+	// Map model names to their encoding names
+	modelToEncoding := map[string]string{
+		"gpt-4o":        "cl100k_base",
+		"gpt-4o-mini":   "cl100k_base",
+		"gpt-4.1":       "cl100k_base",
+		"gpt-4":         "cl100k_base",
+		"gpt-3.5":       "cl100k_base",
+		"gpt-3.5-turbo": "cl100k_base",
+	}
+
+	encoding, ok := modelToEncoding[t.modelName]
+	if !ok {
+		return fmt.Errorf("unsupported model: %s", t.modelName)
+	}
+
+	tokenizer, err := tiktoken.GetEncoding(encoding)
+	if err != nil {
+		return fmt.Errorf("error getting encoding: %w", err)
+	}
+
+	t.tokenizer = tokenizer
+	return nil
+}
+
+// Count counts the number of tokens in the given text sent as a chat message
+func (t *Tokenizer) Count(text string) int {
+	if err := t.initialize(); err != nil {
+		// Fallback to approximation if tokenizer initialization fails
+		return len(text) / 4
+	}
+
+	// Add special tokens to the text
+	formattedText := t.formatForTokenization(text)
+
+	// Count tokens
+	tokens := t.tokenizer.Encode(formattedText, nil, nil)
+	return len(tokens)
+}
+
+func (t *Tokenizer) formatForTokenization(text string) string {
+	return fmt.Sprintf("<|im_start|>user\n%s<|im_end|>\n<|im_start|>assistant<|im_end|>", text)
+}
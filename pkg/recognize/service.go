@@ -1,16 +1,18 @@
 package recognize
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/crowmw/ai_devs3/pkg/ai"
 	"github.com/crowmw/ai_devs3/pkg/c3ntrala"
 	"github.com/crowmw/ai_devs3/pkg/env"
+	"github.com/crowmw/ai_devs3/pkg/logging"
 	photosautomate "github.com/crowmw/ai_devs3/pkg/photos-automate"
 	"github.com/sashabaranov/go-openai"
 )
 
+var logger = logging.New("recognize")
+
 type Service struct {
 	envSvc      *env.Service
 	aiSvc       *ai.Service
@@ -21,19 +23,19 @@ type Service struct {
 
 func NewService(envSvc *env.Service, aiSvc *ai.Service, photosSvc *photosautomate.Service, c3ntralaSvc *c3ntrala.Service) (*Service, error) {
 	photos := photosSvc.GetPhotos()
-	fmt.Println("❇️ Photos:", photos)
+	logger.Info("photos loaded", "photos", photos)
 	return &Service{envSvc: envSvc, aiSvc: aiSvc, photosSvc: photosSvc, photos: photos, c3ntralaSvc: c3ntralaSvc}, nil
 }
 
 func (s *Service) StartRecognize() (string, error) {
-	fmt.Println("❇️ Starting recognize")
+	logger.Info("starting recognize", "photos", len(s.photos))
 	if len(s.photos) == 0 {
-		fmt.Println("❇️ No photos to recognize")
+		logger.Warn("no photos to recognize")
 		return "", nil
 	}
 
 	fixedPhotos := s.processPhotos()
-	fmt.Println("❇️ Photos after processing:", fixedPhotos)
+	logger.Info("photos processed", "photos", fixedPhotos)
 
 	imageUrls := make([]openai.ChatMessagePart, len(fixedPhotos))
 	for i, photo := range fixedPhotos {
@@ -58,10 +60,10 @@ func (s *Service) StartRecognize() (string, error) {
 
 	prompt.Messages[1].MultiContent = append(prompt.Messages[1].MultiContent, imageUrls...)
 
-	fmt.Println("❇️ Describing person onphotos...")
+	logger.Info("describing person on photos")
 	barbaraDescription, err := s.aiSvc.ChatCompletion(prompt)
 	if err != nil {
-		fmt.Println("Error getting AI description:", err)
+		logger.Error("error getting AI description", "error", err)
 		return "", err
 	}
 
@@ -80,7 +82,7 @@ func (s *Service) processPhotos() []string {
 	for len(needsProcessing) > 0 {
 		for photo := range needsProcessing {
 			response := s.analyzePhoto(photo)
-			fmt.Println("❇️ Response:", response)
+			logger.Debug("photo analysis", "photo", photo, "response", response)
 
 			if strings.HasPrefix(response, "GOOD") {
 				delete(needsProcessing, photo)
@@ -89,7 +91,7 @@ func (s *Service) processPhotos() []string {
 			}
 
 			if strings.HasPrefix(response, "INVALID") {
-				fmt.Println("❇️ Photo is invalid:", response)
+				logger.Info("photo is invalid", "photo", photo, "response", response)
 				// Remove the invalid photo from s.photos
 				for i, p := range s.photos {
 					if p == photo {
@@ -102,10 +104,10 @@ func (s *Service) processPhotos() []string {
 			}
 
 			if strings.HasPrefix(response, "REPAIR") || strings.HasPrefix(response, "BRIGHTEN") || strings.HasPrefix(response, "DARKEN") {
-				fmt.Println("❇️ Photo needs processing:", response)
+				logger.Info("photo needs processing", "photo", photo, "response", response)
 				fixedPhoto, err := s.c3ntralaSvc.FixPhoto(response)
 				if err != nil {
-					fmt.Println("❇️ Error fixing photo:", err)
+					logger.Error("error fixing photo", "photo", photo, "error", err)
 					continue
 				}
 				delete(needsProcessing, photo)
@@ -118,7 +120,7 @@ func (s *Service) processPhotos() []string {
 }
 
 func (s *Service) analyzePhoto(url string) string {
-	fmt.Println("❇️ Analyzing photo:", url)
+	logger.Info("analyzing photo", "url", url)
 
 	// Replace .PNG with -small.PNG in the URL
 	smallUrl := strings.Replace(url, ".PNG", "-small.PNG", 1)
@@ -137,7 +139,7 @@ func (s *Service) analyzePhoto(url string) string {
 		},
 	})
 	if err != nil {
-		fmt.Println("❇️ Error analyzing photo:", err)
+		logger.Error("error analyzing photo", "url", url, "error", err)
 		return ""
 	}
 
@@ -0,0 +1,206 @@
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mask replaces every redacted value
+const Mask = "[REDACTED]"
+
+// minSecretLength avoids masking short values that would match ordinary text
+const minSecretLength = 6
+
+var (
+	mu      sync.RWMutex
+	secrets []string
+)
+
+// patterns match common credential formats even when they are not registered
+var patterns = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	// OpenAI style keys
+	{regexp.MustCompile(`sk-[A-Za-z0-9_\-]{20,}`), Mask},
+	// Authorization headers
+	{regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._\-]{16,}`), "${1}" + Mask},
+	// C3ntrala data URLs embed the API key in the path
+	{regexp.MustCompile(`(/data/)[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}(/)`), "${1}" + Mask + "${2}"},
+}
+
+// secretNames are names of fields and parameters holding secrets
+const secretNames = `apikey|api_key|api-key|password|secret|token`
+
+// secretKey matches a field name from secretNames
+var secretKey = regexp.MustCompile(`(?i)^(?:` + secretNames + `)$`)
+
+// keyValue matches JSON fields, also escaped inside JSON strings, query parameters and printed maps:
+// "apikey": "...", \"apikey\":\"...\" (escaped any number of times), apikey=..., apikey:...
+// The value stops before a quote or an escape of one, so escaped JSON keeps its escapes.
+var keyValue = regexp.MustCompile(`(?i)((\\*")?\b(?:` + secretNames + `)(?:\\*")?\s*[:=]\s*)(\\*")?((?:[^"\\\s,&{}\[\]]|\\[^"\\\s])+)`)
+
+// maskKeyValues masks the values matched by keyValue
+func maskKeyValues(s string) string {
+	return keyValue.ReplaceAllStringFunc(s, func(match string) string {
+		m := keyValue.FindStringSubmatch(match)
+		prefix, keyQuote, valueQuote := m[1], m[2], m[3]
+		if keyQuote != "" && valueQuote == "" {
+			// a JSON number, boolean or null becomes a string, so the JSON stays valid
+			return prefix + keyQuote + Mask + keyQuote
+		}
+		return prefix + valueQuote + Mask
+	})
+}
+
+// Register adds known secret values, e.g. API keys from configuration, to be masked everywhere
+func Register(values ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if len(value) < minSecretLength {
+			continue
+		}
+		known := false
+		for _, secret := range secrets {
+			if secret == value {
+				known = true
+				break
+			}
+		}
+		if !known {
+			secrets = append(secrets, value)
+		}
+	}
+	// Longest first, so a secret containing another one is masked as a whole
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+}
+
+// String masks registered secrets and common key patterns
+func String(s string) string {
+	mu.RLock()
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, Mask)
+	}
+	mu.RUnlock()
+
+	for _, pattern := range patterns {
+		s = pattern.re.ReplaceAllString(s, pattern.repl)
+	}
+	return maskKeyValues(s)
+}
+
+// Attrs returns a copy of attrs with secrets masked, values of fields like "apikey" entirely.
+// Values are masked before they are marshaled, so the JSON written later stays valid.
+func Attrs(attrs map[string]any) map[string]any {
+	if attrs == nil {
+		return nil
+	}
+	masked := make(map[string]any, len(attrs))
+	for key, value := range attrs {
+		masked[key] = maskValue(key, value)
+	}
+	return masked
+}
+
+// maskValue masks the strings of a value, walking other types in their JSON form
+func maskValue(key string, value any) any {
+	if secretKey.MatchString(key) {
+		return Mask
+	}
+	switch v := value.(type) {
+	case nil, bool, int, int64, float64:
+		return v
+	case string:
+		return String(v)
+	case error:
+		return String(v.Error())
+	case map[string]any:
+		return Attrs(v)
+	case []any:
+		masked := make([]any, len(v))
+		for i, item := range v {
+			masked[i] = maskValue("", item)
+		}
+		return masked
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return String(fmt.Sprint(value))
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return Mask
+	}
+	return maskValue(key, decoded)
+}
+
+// Bytes masks secrets in raw data
+func Bytes(b []byte) []byte {
+	return []byte(String(string(b)))
+}
+
+// Sprint formats like fmt.Sprint and masks secrets
+func Sprint(a ...any) string {
+	return String(fmt.Sprint(a...))
+}
+
+// Println prints like fmt.Println with secrets masked
+func Println(a ...any) {
+	fmt.Print(String(fmt.Sprintln(a...)))
+}
+
+// Printf prints like fmt.Printf with secrets masked
+func Printf(format string, a ...any) {
+	fmt.Print(String(fmt.Sprintf(format, a...)))
+}
+
+type redactedError struct {
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return String(e.err.Error())
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// Error wraps err so that its message is masked; errors.Is/As still see the original error
+func Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &redactedError{err: err}
+}
+
+type writer struct {
+	w io.Writer
+}
+
+// NewWriter wraps w so that everything written to it is masked.
+// Secrets split across two writes are not detected, so wrap line oriented writers such as loggers.
+func NewWriter(w io.Writer) io.Writer {
+	return &writer{w: w}
+}
+
+func (rw *writer) Write(p []byte) (int, error) {
+	if _, err := rw.w.Write(Bytes(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteFile writes data like os.WriteFile with secrets masked
+func WriteFile(name string, data []byte, perm os.FileMode) error {
+	return os.WriteFile(name, Bytes(data), perm)
+}
@@ -5,9 +5,8 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/crowmw/ai_devs3/pkg/config"
-
 	"github.com/crowmw/ai_devs3/pkg/ai"
+	"github.com/crowmw/ai_devs3/pkg/env"
 	"github.com/crowmw/ai_devs3/pkg/http"
 	"github.com/crowmw/ai_devs3/pkg/processor"
 
@@ -16,6 +15,8 @@ import (
 
 // RobotGuard represents a robot security guard service
 type RobotGuard struct {
+	envSvc     *env.Service
+	aiSvc      *ai.Service
 	memoryDump string
 }
 
@@ -26,9 +27,9 @@ type RobotVerificationResponseValues struct {
 }
 
 // NewRobotGuard creates a new instance of RobotGuard and fetches the memory dump
-func NewRobotGuard() (*RobotGuard, error) {
+func NewRobotGuard(envSvc *env.Service, aiSvc *ai.Service) (*RobotGuard, error) {
 	// Fetch robot memory dump
-	data, err := http.FetchData(config.GetXYZURL() + "/files/0_13_4b.txt")
+	data, err := http.FetchData(envSvc.GetXYZURL() + "/files/0_13_4b.txt")
 	if err != nil {
 		return nil, fmt.Errorf("error fetching memory dump: %w", err)
 	}
@@ -37,6 +38,8 @@ func NewRobotGuard() (*RobotGuard, error) {
 	memoryDump := processor.ReadLinesFromTextFileAsString(data)
 
 	return &RobotGuard{
+		envSvc:     envSvc,
+		aiSvc:      aiSvc,
 		memoryDump: memoryDump,
 	}, nil
 }
@@ -128,7 +131,7 @@ func (rg *RobotGuard) StartVerification() error {
 	}
 
 	for {
-		response, err := http.SendPost(config.GetXYZURL()+"/verify", postData)
+		response, err := http.SendPost(rg.envSvc.GetXYZURL()+"/verify", postData)
 		if err != nil {
 			return fmt.Errorf("error sending form data: %w", err)
 		}
@@ -140,11 +143,11 @@ func (rg *RobotGuard) StartVerification() error {
 
 		// Check if response contains flag
 		if strings.Contains(verifyMsg.Text, "{{FLG:") {
-			fmt.Println("FLAG: ", verifyMsg.Text)
+			logger.Info("flag found", "text", verifyMsg.Text)
 			return nil
 		}
 
-		aiResponse, err := ai.SendChatCompletion("gpt-4o-mini", true, rg.GetRobotVerificationPrompt(verifyMsg.Text))
+		aiResponse, err := rg.aiSvc.SendChatCompletion("gpt-4o-mini", true, rg.GetRobotVerificationPrompt(verifyMsg.Text))
 		if err != nil {
 			return fmt.Errorf("error getting OpenAI response: %w", err)
 		}
@@ -6,11 +6,14 @@ import (
 	"strings"
 
 	"github.com/crowmw/ai_devs3/pkg/ai"
-	"github.com/crowmw/ai_devs3/pkg/config"
-	"github.com/crowmw/ai_devs3/pkg/http"
+	"github.com/crowmw/ai_devs3/pkg/c3ntrala"
+	"github.com/crowmw/ai_devs3/pkg/env"
+	"github.com/crowmw/ai_devs3/pkg/logging"
 	"github.com/sashabaranov/go-openai"
 )
 
+var logger = logging.New("robot")
+
 // TestData represents a single test case for the robot
 type TestData struct {
 	Question string `json:"question"`
@@ -31,26 +34,28 @@ type CalibrationData struct {
 
 // IndustrialRobot represents an industrial robot service
 type IndustrialRobot struct {
+	envSvc          *env.Service
+	aiSvc           *ai.Service
+	c3ntralaSvc     *c3ntrala.Service
 	calibrationData CalibrationData
 }
 
 // NewIndustrialRobot creates a new instance of IndustrialRobot and loads calibration data
-func NewIndustrialRobot() (*IndustrialRobot, error) {
-	// Construct URL with API key
-	url := fmt.Sprintf("%s/data/%s/json.txt",
-		config.GetC3ntralaURL(),
-		config.GetMyAPIKey())
-
-	fmt.Printf("URL: %v\n", url)
+func NewIndustrialRobot(envSvc *env.Service, aiSvc *ai.Service, c3ntralaSvc *c3ntrala.Service) (*IndustrialRobot, error) {
+	resources := c3ntralaSvc.Resources()
+	logger.Debug("loading calibration data", "url", resources.URL(c3ntrala.ResourceCalibration))
 
 	// Fetch calibration data as JSON
 	var calibrationData CalibrationData
-	err := http.FetchJSONData(url, &calibrationData)
+	err := resources.LoadJSON(c3ntrala.ResourceCalibration, &calibrationData)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching calibration data: %w", err)
 	}
 
 	return &IndustrialRobot{
+		envSvc:          envSvc,
+		aiSvc:           aiSvc,
+		c3ntralaSvc:     c3ntralaSvc,
 		calibrationData: calibrationData,
 	}, nil
 }
@@ -100,18 +105,18 @@ func calculateResult(testCase TestData) (int, error) {
 }
 
 func (r *IndustrialRobot) Recalibrate() error {
-	fmt.Println("Starting recalibration...")
+	logger.Info("starting recalibration", "cases", len(r.calibrationData.TestData))
 
-	r.calibrationData.APIKey = config.GetMyAPIKey()
+	r.calibrationData.APIKey = r.envSvc.GetMyAPIKey()
 
 	for i := range r.calibrationData.TestData {
 		testCase := &r.calibrationData.TestData[i]
 
 		if testCase.Test != nil {
-			// If test data contains "test" parameter, just print it
-			fmt.Printf("Test Q: %s\n", testCase.Test.Q)
+			// If test data contains "test" parameter, ask the model for the answer
+			logger.Info("answering test question", "question", testCase.Test.Q)
 
-			aiAnswer, err := ai.SendChatCompletion("gpt-4o-mini", true, []openai.ChatCompletionMessage{
+			aiAnswer, err := r.aiSvc.SendChatCompletion("gpt-4o-mini", true, []openai.ChatCompletionMessage{
 				{
 					Role:    "system",
 					Content: "You can response with only one word, no other text.",
@@ -124,8 +129,7 @@ func (r *IndustrialRobot) Recalibrate() error {
 			if err != nil {
 				return fmt.Errorf("error getting OpenAI response: %w", err)
 			}
-			fmt.Printf("AI answer: %s\n", aiAnswer)
-			fmt.Printf("Updating calibration data with correct answer...\n")
+			logger.Info("updating test answer", "question", testCase.Test.Q, "answer", aiAnswer)
 			testCase.Test.A = aiAnswer
 			continue
 		}
@@ -133,16 +137,13 @@ func (r *IndustrialRobot) Recalibrate() error {
 		// Calculate actual result
 		actualResult, err := calculateResult(*testCase)
 		if err != nil {
-			fmt.Printf("Error calculating result: %v\n", err)
+			logger.Warn("error calculating result", "equation", testCase.Question, "error", err)
 			continue
 		}
 
 		// Compare with provided answer
 		if actualResult != testCase.Answer {
-			fmt.Printf("Equation: %s\n", testCase.Question)
-			fmt.Printf("ERROR: Answer provided (%d) is incorrect! Actual result is: %d\n",
-				testCase.Answer, actualResult)
-			fmt.Printf("Updating calibration data with correct answer...\n")
+			logger.Info("fixing incorrect answer", "equation", testCase.Question, "provided", testCase.Answer, "actual", actualResult)
 			testCase.Answer = actualResult
 		}
 	}
@@ -151,14 +152,13 @@ func (r *IndustrialRobot) Recalibrate() error {
 
 // SendCalibrationReport sends the calibration data to the central server
 func (r *IndustrialRobot) SendCalibrationReport() error {
-	fmt.Println("Sending calibration report...")
-
-	resp, err := http.SendC3ntralaReport("JSON", r.calibrationData)
+	logger.Info("sending calibration report")
 
+	resp, err := r.c3ntralaSvc.PostReport("JSON", r.calibrationData, false)
 	if err != nil {
 		return fmt.Errorf("error sending calibration report: %w", err)
 	}
 
-	fmt.Printf("Calibration report sent successfully: %v\n", resp)
+	logger.Info("calibration report sent", "result", resp)
 	return nil
 }
@@ -4,8 +4,8 @@ import (
 	"fmt"
 
 	"github.com/crowmw/ai_devs3/pkg/ai"
-	"github.com/crowmw/ai_devs3/pkg/config"
-	"github.com/crowmw/ai_devs3/pkg/http"
+	"github.com/crowmw/ai_devs3/pkg/c3ntrala"
+	"github.com/crowmw/ai_devs3/pkg/env"
 )
 
 // RobotDescriptionResult represents the structure of the robot description JSON
@@ -20,10 +20,11 @@ type SentryRobot struct {
 }
 
 // NewSentryRobot creates a new SentryRobot instance and initializes it with data
-func NewSentryRobot() (*SentryRobot, error) {
+func NewSentryRobot(envSvc *env.Service, aiSvc *ai.Service) (*SentryRobot, error) {
 	// Fetch robot description
 	var robotDescriptionResult RobotDescriptionResult
-	err := http.FetchJSONData(config.GetC3ntralaURL()+"/data/"+config.GetMyAPIKey()+"/robotid.json", &robotDescriptionResult)
+	resources := c3ntrala.NewResources(envSvc.GetC3ntralaURL(), envSvc.GetMyAPIKey(), "")
+	err := resources.LoadJSON(c3ntrala.ResourceRobotID, &robotDescriptionResult)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching robot description: %w", err)
 	}
@@ -32,7 +33,7 @@ func NewSentryRobot() (*SentryRobot, error) {
 	prompt := fmt.Sprintf("Create a detailed, high-quality image of a robot with the following characteristics: %s. The image should be photorealistic, with high attention to detail and professional lighting.", robotDescriptionResult.Description)
 
 	// Generate image using DALL-E 3
-	imageURL, err := ai.GenerateImageWithDalle(prompt)
+	imageURL, err := aiSvc.GenerateImageWithDalle(prompt)
 	if err != nil {
 		return nil, fmt.Errorf("error generating image: %w", err)
 	}
@@ -2,16 +2,12 @@ package serce_agent
 
 import (
 	"fmt"
-	"strings"
 	"time"
-)
 
-func getToolsPrompt(state *State) string {
-	var toolsList strings.Builder
-	for _, tool := range state.Tools {
-		toolsList.WriteString(fmt.Sprintf("- %s: %s\n  Instruction: %s\n", tool.Name, tool.Description, tool.Instruction))
-	}
+	"github.com/crowmw/ai_devs3/pkg/agent"
+)
 
+func getToolsPrompt(state *agent.State) string {
 	return fmt.Sprintf(`Your task is to analyze the conversation context and select the most appropriate tool with its payload.
 
 <prompt_objective>
@@ -80,7 +76,8 @@ AI: {
   "result": {
     "tool": "answer_question",
     "payload": {
-      "question": "What was the value of the 'key' variable? Remember to use the value after 'klucz=' in the memory."
+      "question": "What was the value of the 'key' variable? Remember to use the value after 'klucz=' in the memory.",
+      "keyword": "klucz"
     }
   }
 }
@@ -107,5 +104,5 @@ Before delivering ANY output:
 
 <confirmation>
 This prompt is designed to create an internal dialogue while analyzing conversations. It processes the conversation context and selects the most appropriate tool with its payload. The output focuses on utilizing available tools effectively, avoiding assumptions about unavailable tools, and ensures the payload matches the tool's requirements exactly. For any memory-related queries, the answer_question tool should be used as it has access to the saved memory. When memory contains key-value pairs, the value after "klucz=" should be used as the answer.
-</confirmation>`, time.Now().Format("2006-01-02 15:04:05"), toolsList.String())
+</confirmation>`, time.Now().Format("2006-01-02 15:04:05"), agent.FormatToolInstructions(state.Tools))
 }
@@ -1,209 +1,152 @@
 package serce_agent
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"path/filepath"
-	"strings"
+	"time"
 
+	"github.com/crowmw/ai_devs3/pkg/agent"
 	"github.com/crowmw/ai_devs3/pkg/ai"
 	"github.com/crowmw/ai_devs3/pkg/c3ntrala"
+	"github.com/crowmw/ai_devs3/pkg/conversation"
 	"github.com/crowmw/ai_devs3/pkg/env"
+	"github.com/crowmw/ai_devs3/pkg/logging"
+	"github.com/crowmw/ai_devs3/pkg/memory"
+	"github.com/crowmw/ai_devs3/pkg/trace"
 	"github.com/sashabaranov/go-openai"
 )
 
+var logger = logging.New("agent").With(logging.KeyAgent, "serce")
+
+// Service talks with the serce LLM, each message is answered by a single selected tool.
+// The conversation is kept in the agent state and checkpointed to the store after every message,
+// facts saved with data_memory go to the long-term memory and are recalled by answer_question.
+// Older messages are summarized once the conversation outgrows its token budget.
 type Service struct {
-	State       State
-	envSvc      *env.Service
-	aiSvc       *ai.Service
-	c3ntralaSvc *c3ntrala.Service
+	agent        *agent.Agent
+	envSvc       *env.Service
+	c3ntralaSvc  *c3ntrala.Service
+	memory       *memory.Memory
+	conversation *conversation.Manager
 }
 
-func NewService(envSvc *env.Service, aiSvc *ai.Service, c3ntralaSvc *c3ntrala.Service, initSystemMessage string) (*Service, error) {
-	fmt.Println("\n🚀 [AGENT] Initializing Serce Agent Service...")
-
-	state := State{
-		Memory:   "",
-		Messages: []string{initSystemMessage},
-		Tools:    getTools(),
-	}
+// NewService creates the agent and opens its memory from memoryOpts, the embeddings of the memory and
+// the summaries of the conversation count against the limits of the run like the agent's own calls
+func NewService(envSvc *env.Service, aiSvc *ai.Service, c3ntralaSvc *c3ntrala.Service, initSystemMessage string, store agent.Store, memoryOpts memory.Options, oversight agent.Oversight, limits agent.Limits) (*Service, error) {
+	s := &Service{envSvc: envSvc, c3ntralaSvc: c3ntralaSvc}
 
-	// Write system messages to file
-	if err := writeSystemMessages(initSystemMessage); err != nil {
+	a, err := agent.New(aiSvc, agent.Options{
+		Name:  "serce",
+		Model: "gpt-4o",
+		Mode:  agent.ModeSelect,
+		// the whole conversation is one run, every message is a step
+		Limits: agent.Limits{
+			MaxSteps: 30,
+			Timeout:  15 * time.Minute,
+			MaxCost:  1,
+		}.Override(limits),
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: initSystemMessage},
+		},
+		Tools:     s.getTools(),
+		Prompts:   agent.Prompts{Select: getToolsPrompt},
+		Store:     store,
+		Oversight: oversight,
+	})
+	if err != nil {
 		return nil, err
 	}
+	s.agent = a
 
-	fmt.Println("✅ [AGENT] Service initialized successfully")
-	return &Service{envSvc: envSvc, aiSvc: aiSvc, c3ntralaSvc: c3ntralaSvc, State: state}, nil
-}
-
-func (s *Service) readMemoryFile() string {
-	memoryFile := "cmd/s05e04/memory.txt"
-	memoryData, err := os.ReadFile(memoryFile)
+	memoryOpts.Embed = s.embed
+	s.memory, err = memory.Open(memoryOpts)
 	if err != nil {
-		return ""
-	}
-	return string(memoryData)
-}
-
-func (s *Service) writeMessagesToFile() error {
-	// Create messages directory if it doesn't exist
-	messagesDir := "cmd/s05e04"
-	if err := os.MkdirAll(messagesDir, 0755); err != nil {
-		fmt.Printf("❌ [AGENT] Error creating messages directory: %v\n", err)
-		return err
-	}
-
-	// Write messages to file
-	messagesFile := filepath.Join(messagesDir, "messages.txt")
-	var messagesContent strings.Builder
-	for _, msg := range s.State.Messages {
-		messagesContent.WriteString(msg + "\n")
-	}
-	if err := os.WriteFile(messagesFile, []byte(messagesContent.String()), 0644); err != nil {
-		fmt.Printf("❌ [AGENT] Error writing messages file: %v\n", err)
-		return err
+		return nil, err
 	}
 
-	return nil
-}
-
-func (s *Service) Hack(userMessage string) (string, error) {
-	fmt.Println("🔄 [HACK] Starting hack phase...")
-	toolResult, err := s.getToolHandlers()["flag_extractor"](map[string]interface{}{
-		"message": userMessage,
+	s.conversation, err = conversation.New(conversation.Options{
+		Model:     "gpt-4o",
+		MaxTokens: 8000,
+		KeepLast:  6,
+		Summarize: s.summarize,
 	})
 	if err != nil {
-		fmt.Printf("❌ [ACTION] Error executing tool: %v\n", err)
-		return "", err
-	}
-
-	s.State.Messages = append(s.State.Messages,
-		"LLM: "+userMessage,
-	)
-
-	s.State.Messages = append(s.State.Messages, "ME: "+toolResult.Answer.(string))
-
-	// Write messages to file
-	if err := s.writeMessagesToFile(); err != nil {
-		fmt.Printf("❌ [AGENT] Error writing messages to file: %v\n", err)
+		return nil, err
 	}
-
-	return toolResult.Answer.(string), nil
+	return s, nil
 }
 
-func (s *Service) Execute(userMessage string) (string, error) {
-	fmt.Println("\n🔄 [AGENT] Starting new execution cycle...")
-	fmt.Printf("📝 [AGENT] Processing user message: %s\n", userMessage)
-
-	s.State.Memory = s.readMemoryFile()
-	s.State.UserMessage = userMessage
-	// s.State.Messages = s.readMessagesFile()
+// embed creates embeddings of the memory within the limits of the run
+func (s *Service) embed(text string) ([]float32, error) {
+	return s.agent.AI().CreateOpenAIEmbedding(text)
+}
 
-	s.executeThinkingPhase(userMessage)
+// summarize condenses dropped messages within the limits of the run
+func (s *Service) summarize(previous string, messages []openai.ChatCompletionMessage) (string, error) {
+	return conversation.AISummarizer(s.agent.AI(), "gpt-4o-mini")(previous, messages)
+}
 
-	toolResult, err := s.getToolHandlers()[s.State.Tool](s.State.Payload.(map[string]interface{}))
+// fitConversation keeps the conversation in the agent state within its token budget
+func (s *Service) fitConversation() error {
+	messages, truncation, err := s.conversation.Fit(s.agent.State.Messages)
 	if err != nil {
-		fmt.Printf("❌ [ACTION] Error executing tool: %v\n", err)
-		return "", err
+		return err
 	}
-
-	s.State.Messages = append(s.State.Messages,
-		"LLM: "+userMessage,
-	)
-
-	s.State.Messages = append(s.State.Messages, "ME: "+toolResult.Answer.(string))
-
-	// Write messages to file
-	if err := s.writeMessagesToFile(); err != nil {
-		fmt.Printf("❌ [AGENT] Error writing messages to file: %v\n", err)
+	if truncation != nil {
+		s.agent.State.Messages = messages
 	}
+	return nil
+}
 
-	return toolResult.Answer.(string), nil
+// Restore continues the conversation of a checkpointed run, from its latest message when step is negative
+func (s *Service) Restore(runID string, step int) error {
+	return s.agent.Restore(runID, step)
 }
 
-func (s *Service) executeThinkingPhase(question string) {
-	fmt.Println("\n🔍 [THINKING] Starting thinking phase...")
-	fmt.Printf("📝 [THINKING] Processing question: %s\n", question)
-	fmt.Printf("🛠️ [THINKING] Available tools: %d\n", len(s.State.Tools))
-	for _, tool := range s.State.Tools {
-		fmt.Printf("  - %s: %s\n", tool.Name, tool.Description)
+// messages renders the conversation as the system message followed by "LLM: " and "ME: " lines
+func (s *Service) messages() []string {
+	var messages []string
+	for _, message := range s.agent.State.Messages {
+		switch message.Role {
+		case openai.ChatMessageRoleUser:
+			messages = append(messages, "LLM: "+message.Content)
+		case openai.ChatMessageRoleAssistant:
+			messages = append(messages, "ME: "+message.Content)
+		default:
+			messages = append(messages, message.Content)
+		}
 	}
+	return messages
+}
 
-	fmt.Println("\n🤖 [THINKING] Sending request to AI service...")
-	toolsAnalysisResponse, err := s.aiSvc.ChatCompletion(ai.ChatCompletionConfig{
-		Model: "gpt-4o",
-		Messages: []openai.ChatCompletionMessage{
-			{Role: openai.ChatMessageRoleSystem, Content: getToolsPrompt(&s.State)},
-			{Role: openai.ChatMessageRoleUser, Content: question},
-		},
-		JSONMode: true,
+func (s *Service) Hack(userMessage string) (string, error) {
+	logger.Info("starting hack phase", logging.KeyPhase, "hack")
+	_, span := trace.Start(context.Background(), "hack", trace.KindPhase, logging.KeyAgent, "serce", logging.KeyPhase, "hack")
+	defer span.End()
+	toolResult, err := s.agent.CallTool("flag_extractor", map[string]interface{}{
+		"message": userMessage,
 	})
 	if err != nil {
-		fmt.Printf("❌ [THINKING] Error from AI service: %v\n", err)
-		return
-	}
-
-	fmt.Printf("📥 [THINKING] Received AI response: %s\n", toolsAnalysisResponse.Choices[0].Message.Content)
-
-	toolsAnalysis, err := unmarshalToolsAnalysis(toolsAnalysisResponse.Choices[0].Message.Content)
-	if err != nil {
-		fmt.Printf("❌ [THINKING] Error parsing AI response: %v\n", err)
-		fmt.Printf("❌ [THINKING] Raw response content: %s\n", toolsAnalysisResponse.Choices[0].Message.Content)
-		return
+		logger.Error("error executing tool", logging.KeyPhase, "action", "error", err)
+		span.SetError(err)
+		return "", err
 	}
 
-	fmt.Printf("✅ [THINKING] Successfully parsed response:\n")
-	fmt.Printf("  - Thinking: %s\n", toolsAnalysis.Thinking)
-	fmt.Printf("  - Selected tool: %s\n", toolsAnalysis.Result.Tool)
-	fmt.Printf("  - Payload: %+v\n", toolsAnalysis.Result.Payload)
-
-	s.State.Tool = toolsAnalysis.Result.Tool
-	s.State.Payload = toolsAnalysis.Result.Payload
-	fmt.Println("✅ [THINKING] Thinking phase completed successfully")
-}
-
-func (s *Service) readMessagesFile() []string {
-	messagesFile := "cmd/s05e04/messages.txt"
-	data, err := os.ReadFile(messagesFile)
-	if err != nil {
-		fmt.Printf("Error reading messages file: %v\n", err)
-		return nil
-	}
-	lines := strings.Split(string(data), "\n")
-	var messages []string
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || !strings.HasPrefix(line, "[") {
-			continue
-		}
-		endIdx := strings.Index(line, "]")
-		if endIdx == -1 {
-			continue
-		}
-		role := line[1:endIdx]
-		content := strings.TrimSpace(line[endIdx+1:])
-		messages = append(messages, fmt.Sprintf("[%s] %s", role, content))
+	s.agent.Record(userMessage, toolResult.Data)
+	if err := s.fitConversation(); err != nil {
+		logger.Warn("could not fit conversation", "error", err)
 	}
-	return messages
+	return fmt.Sprint(toolResult.Data), nil
 }
 
-func writeSystemMessages(message string) error {
-	// Create messages directory if it doesn't exist
-	messagesDir := "cmd/s05e04"
-	if err := os.MkdirAll(messagesDir, 0755); err != nil {
-		fmt.Printf("❌ [AGENT] Error creating messages directory: %v\n", err)
-		return err
+func (s *Service) Execute(userMessage string) (string, error) {
+	if err := s.fitConversation(); err != nil {
+		logger.Warn("could not fit conversation", "error", err)
 	}
 
-	// Write system messages to file
-	messagesFile := filepath.Join(messagesDir, "messages.txt")
-	var messagesContent strings.Builder
-	messagesContent.WriteString(message)
-	if err := os.WriteFile(messagesFile, []byte(messagesContent.String()), 0644); err != nil {
-		fmt.Printf("❌ [AGENT] Error writing messages file: %v\n", err)
-		return err
+	answer, err := s.agent.Run(userMessage)
+	if err != nil {
+		return "", err
 	}
-
-	return nil
+	return fmt.Sprint(answer), nil
 }
@@ -1,80 +1,78 @@
 package serce_agent
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
-	"time"
 
+	"github.com/crowmw/ai_devs3/pkg/agent"
 	"github.com/crowmw/ai_devs3/pkg/ai"
+	"github.com/crowmw/ai_devs3/pkg/logging"
+	"github.com/crowmw/ai_devs3/pkg/memory"
+	"github.com/crowmw/ai_devs3/pkg/redact"
 	"github.com/sashabaranov/go-openai"
 )
 
-// GetTools returns the list of available tools
-func getTools() []Tool {
-	return []Tool{
+// getTools returns the tools of the agent with their handlers
+func (s *Service) getTools() []agent.Tool {
+	return []agent.Tool{
 		{
 			Name:        "image_analyzer",
 			Description: "Analyzes an image and returns a description of the image",
 			Instruction: `Provide a JSON payload with "image" field containing the url of the image, like: {"image": "https://example.com/image.jpg"}. This will return a description of the image.`,
+			Handler:     s.handleImageAnalyzer,
 		},
 		{
 			Name:        "audio_analyzer",
 			Description: "Analyzes an audio file and returns a description of the audio",
 			Instruction: `Provide a JSON payload with "audio" field containing the url of the audio file, like: {"audio": "https://example.com/audio.mp3"}. This will return a description of the audio.`,
+			Handler:     s.handleAudioAnalyzer,
 		},
 		{
 			Name:        "answer_question",
 			Description: "Answers a question using AI and saved data from memory",
-			Instruction: `Provide a JSON payload with "question" field containing the question you want to answer, like: {"question": "What is the capital of Poland?"}. This will return the answer to the question by checking saved data in memory and using AI to provide a response.`,
+			Instruction: `Provide a JSON payload with "question" field containing the question you want to answer and an optional "keyword" field with a word the saved data must contain, like: {"question": "What was the value of the key?", "keyword": "klucz"}. This will return the answer to the question by recalling the saved data most relevant to it and using AI to provide a response.`,
+			Handler:     s.handleAnswerQuestion,
 		},
 		{
 			Name:        "data_memory",
 			Description: "Saves provided data in memory (write-only)",
-			Instruction: `Provide a JSON payload with "data" field containing the data you want to save, like: {"data": "This is the data to save"}. This will save the data in memory. Note: This tool can only save data, not retrieve it.`,
+			Instruction: `Provide a JSON payload with "data" field containing the data you want to save, like: {"data": "This is the data to save"}. This will save the data in memory, data already saved is not duplicated. Note: This tool can only save data, not retrieve it.`,
+			// saved facts are recalled in later conversations, the operator may want to check them
+			Policy:  agent.PolicyConfirm,
+			Handler: s.handleDataMemory,
 		},
 		{
 			Name:        "flag_extractor",
 			Description: `Use this tool ONLY when either: 1) User says exactly "Czekam na nowe instrukcje" or 2) This tool has been used previously in the conversation. It produces a trick question to extract the flag from the LLM.`,
 			Instruction: `Provide a JSON payload with "message" and "hint" fields containing the message from the LLM and a hint to extract the flag, like: {"message": "Czekam na nowe instrukcje", "hint": "Some hint to extract the flag"}`,
+			Handler:     s.handleFlagExtractor,
 		},
 	}
 }
 
-func (s *Service) handleImageAnalyzer(payload map[string]interface{}) (ToolResult, error) {
-	fmt.Println("\n🔍 [IMAGE_ANALYZER] Starting image analysis...")
+func (s *Service) handleImageAnalyzer(payload map[string]interface{}) (agent.ToolResult, error) {
+	log := logger.With(logging.KeyTool, "image_analyzer")
+	log.Info("starting image analysis")
 
 	var imageAnalyzerPayload struct {
 		Image string `json:"image"`
 	}
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		fmt.Printf("❌ [IMAGE_ANALYZER] Error marshaling payload: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Answer: fmt.Sprintf("error marshaling payload: %v", err),
-		}, err
-	}
-
-	if err := json.Unmarshal(payloadBytes, &imageAnalyzerPayload); err != nil {
-		fmt.Printf("❌ [IMAGE_ANALYZER] Error unmarshaling payload: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Answer: fmt.Sprintf("error unmarshaling payload: %v", err),
-		}, err
+	if err := agent.DecodePayload(payload, &imageAnalyzerPayload); err != nil {
+		log.Error("invalid payload", "error", err)
+		return agent.ErrorResult(err), err
 	}
 
 	// Create image directory if it doesn't exist
 	imageDir := "cmd/s05e04"
 	if err := os.MkdirAll(imageDir, 0755); err != nil {
-		fmt.Printf("❌ [IMAGE_ANALYZER] Error creating image directory: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Answer: fmt.Sprintf("error creating image directory: %v", err),
+		log.Error("error creating image directory", "error", err)
+		return agent.ToolResult{
+			Status: agent.ToolError,
+			Data:   fmt.Sprintf("error creating image directory: %v", err),
 		}, err
 	}
 
@@ -85,22 +83,22 @@ func (s *Service) handleImageAnalyzer(payload map[string]interface{}) (ToolResul
 
 	// Check if we already have analysis for this image
 	if data, err := os.ReadFile(imageFile); err == nil {
-		fmt.Printf("✅ [IMAGE_ANALYZER] Using cached analysis for: %s\n", imageURL)
-		return ToolResult{
-			Status: "success",
-			Answer: string(data),
+		log.Info("using cached analysis", "url", imageURL)
+		return agent.ToolResult{
+			Status: agent.ToolSuccess,
+			Data:   string(data),
 		}, nil
 	}
 
-	fmt.Printf("📍 [IMAGE_ANALYZER] Downloading image from: %s\n", imageURL)
+	log.Info("downloading image", "url", imageURL)
 
 	// Create a temporary file to store the image
 	tempFile, err := os.CreateTemp("", "image-*.jpg")
 	if err != nil {
-		fmt.Printf("❌ [IMAGE_ANALYZER] Error creating temp file: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Answer: fmt.Sprintf("error creating temp file: %v", err),
+		log.Error("error creating temp file", "error", err)
+		return agent.ToolResult{
+			Status: agent.ToolError,
+			Data:   fmt.Sprintf("error creating temp file: %v", err),
 		}, err
 	}
 	defer os.Remove(tempFile.Name()) // Clean up the temp file when done
@@ -109,102 +107,91 @@ func (s *Service) handleImageAnalyzer(payload map[string]interface{}) (ToolResul
 	// Download the image
 	httpResp, err := http.Get(imageURL)
 	if err != nil {
-		fmt.Printf("❌ [IMAGE_ANALYZER] Error downloading image: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Answer: fmt.Sprintf("error downloading image: %v", err),
+		log.Error("error downloading image", "error", err)
+		return agent.ToolResult{
+			Status: agent.ToolError,
+			Data:   fmt.Sprintf("error downloading image: %v", err),
 		}, err
 	}
 	defer httpResp.Body.Close()
 
 	if httpResp.StatusCode != http.StatusOK {
-		fmt.Printf("❌ [IMAGE_ANALYZER] Error downloading image: status code %d\n", httpResp.StatusCode)
-		return ToolResult{
-			Status: "error",
-			Answer: fmt.Sprintf("error downloading image: status code %d", httpResp.StatusCode),
+		log.Error("error downloading image", "status", httpResp.StatusCode)
+		return agent.ToolResult{
+			Status: agent.ToolError,
+			Data:   fmt.Sprintf("error downloading image: status code %d", httpResp.StatusCode),
 		}, fmt.Errorf("error downloading image: status code %d", httpResp.StatusCode)
 	}
 
 	// Copy the downloaded content to the temp file
 	_, err = io.Copy(tempFile, httpResp.Body)
 	if err != nil {
-		fmt.Printf("❌ [IMAGE_ANALYZER] Error saving image: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Answer: fmt.Sprintf("error saving image: %v", err),
+		log.Error("error saving image", "error", err)
+		return agent.ToolResult{
+			Status: agent.ToolError,
+			Data:   fmt.Sprintf("error saving image: %v", err),
 		}, err
 	}
 
 	// Ensure all data is written to disk
 	if err := tempFile.Sync(); err != nil {
-		fmt.Printf("❌ [IMAGE_ANALYZER] Error syncing temp file: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Answer: fmt.Sprintf("error syncing temp file: %v", err),
+		log.Error("error syncing temp file", "error", err)
+		return agent.ToolResult{
+			Status: agent.ToolError,
+			Data:   fmt.Sprintf("error syncing temp file: %v", err),
 		}, err
 	}
 
-	fmt.Printf("🤖 [IMAGE_ANALYZER] Analyzing image from local file: %s\n", tempFile.Name())
-	imageDescription, err := s.aiSvc.ImageAnalysis(tempFile.Name())
+	log.Debug("analyzing image from local file", "file", tempFile.Name())
+	imageDescription, err := s.agent.AI().ImageAnalysis(tempFile.Name())
 	if err != nil {
-		fmt.Printf("❌ [IMAGE_ANALYZER] Error analyzing image: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Answer: err.Error(),
+		log.Error("error analyzing image", "error", err)
+		return agent.ToolResult{
+			Status: agent.ToolError,
+			Data:   err.Error(),
 		}, err
 	}
 
 	// Save analysis to file
-	if err := os.WriteFile(imageFile, []byte(imageDescription), 0644); err != nil {
-		fmt.Printf("❌ [IMAGE_ANALYZER] Error saving analysis: %v\n", err)
+	if err := redact.WriteFile(imageFile, []byte(imageDescription), 0644); err != nil {
+		log.Error("error saving analysis", "error", err)
 	}
 
-	fmt.Printf("✅ [IMAGE_ANALYZER] Successfully analyzed image: %s\n", imageDescription)
+	log.Info("image analyzed", "description", imageDescription)
 
-	return ToolResult{
-		Status: "success",
-		Answer: imageDescription,
+	return agent.ToolResult{
+		Status: agent.ToolSuccess,
+		Data:   imageDescription,
 	}, nil
 }
 
-func (s *Service) handleAudioAnalyzer(payload map[string]interface{}) (ToolResult, error) {
-	fmt.Println("\n🆔 [AUDIO_ANALYZER] Starting audio analysis...")
+func (s *Service) handleAudioAnalyzer(payload map[string]interface{}) (agent.ToolResult, error) {
+	log := logger.With(logging.KeyTool, "audio_analyzer")
+	log.Info("starting audio analysis")
 
 	var audioAnalyzerPayload struct {
 		Audio string `json:"audio"`
 	}
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		fmt.Printf("❌ [AUDIO_ANALYZER] Error marshaling payload: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Answer: fmt.Sprintf("error marshaling payload: %v", err),
-		}, err
-	}
-
-	if err := json.Unmarshal(payloadBytes, &audioAnalyzerPayload); err != nil {
-		fmt.Printf("❌ [AUDIO_ANALYZER] Error unmarshaling payload: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Answer: fmt.Sprintf("error unmarshaling payload: %v", err),
-		}, err
+	if err := agent.DecodePayload(payload, &audioAnalyzerPayload); err != nil {
+		log.Error("invalid payload", "error", err)
+		return agent.ErrorResult(err), err
 	}
 
 	if audioAnalyzerPayload.Audio == "" {
-		fmt.Println("❌ [AUDIO_ANALYZER] Error: Audio parameter is required")
-		return ToolResult{
-			Status: "error",
-			Answer: "You need to provide only parameter 'audio' with audio file in payload",
-		}, err
+		log.Error("audio parameter is required")
+		return agent.ToolResult{
+			Status: agent.ToolError,
+			Data:   "You need to provide only parameter 'audio' with audio file in payload",
+		}, nil
 	}
 
 	// Create audio directory if it doesn't exist
 	audioDir := "cmd/s05e04"
 	if err := os.MkdirAll(audioDir, 0755); err != nil {
-		fmt.Printf("❌ [AUDIO_ANALYZER] Error creating audio directory: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Answer: fmt.Sprintf("error creating audio directory: %v", err),
+		log.Error("error creating audio directory", "error", err)
+		return agent.ToolResult{
+			Status: agent.ToolError,
+			Data:   fmt.Sprintf("error creating audio directory: %v", err),
 		}, err
 	}
 
@@ -215,64 +202,89 @@ func (s *Service) handleAudioAnalyzer(payload map[string]interface{}) (ToolResul
 
 	// Check if we already have analysis for this audio
 	if data, err := os.ReadFile(audioFile); err == nil {
-		fmt.Printf("✅ [AUDIO_ANALYZER] Using cached analysis for: %s\n", audioURL)
-		return ToolResult{
-			Status: "success",
-			Answer: string(data),
+		log.Info("using cached analysis", "url", audioURL)
+		return agent.ToolResult{
+			Status: agent.ToolSuccess,
+			Data:   string(data),
 		}, nil
 	}
 
-	fmt.Printf("👤 [AUDIO_ANALYZER] Analyzing audio: %s\n", audioURL)
-	audioDescription, err := s.aiSvc.AudioAnalysis(audioURL)
+	log.Info("analyzing audio", "url", audioURL)
+	audioDescription, err := s.agent.AI().AudioAnalysis(audioURL)
 	if err != nil {
-		fmt.Printf("❌ [AUDIO_ANALYZER] Error analyzing audio: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Answer: err.Error(),
+		log.Error("error analyzing audio", "error", err)
+		return agent.ToolResult{
+			Status: agent.ToolError,
+			Data:   err.Error(),
 		}, err
 	}
 
 	// Save analysis to file
-	if err := os.WriteFile(audioFile, []byte(audioDescription), 0644); err != nil {
-		fmt.Printf("❌ [AUDIO_ANALYZER] Error saving analysis: %v\n", err)
+	if err := redact.WriteFile(audioFile, []byte(audioDescription), 0644); err != nil {
+		log.Error("error saving analysis", "error", err)
 	}
 
-	fmt.Printf("✅ [AUDIO_ANALYZER] Analyzed audio: %s\n", audioDescription)
+	log.Info("audio analyzed", "description", audioDescription)
 
-	return ToolResult{
-		Status: "success",
-		Answer: audioDescription,
+	return agent.ToolResult{
+		Status: agent.ToolSuccess,
+		Data:   audioDescription,
 	}, nil
 }
 
-func (s *Service) handleAnswerQuestion(payload map[string]interface{}) (ToolResult, error) {
-	fmt.Println("\n📍 [ANSWER_QUESTION] Starting answer question...")
+// recallLimit is how many facts similar to a question are shown to answer_question
+const recallLimit = 5
+
+// recall collects the facts similar to the question and the ones containing the keyword, each fact once
+func (s *Service) recall(question, keyword string) ([]string, error) {
+	matches, err := s.memory.Recall(question, recallLimit)
+	if err != nil {
+		return nil, err
+	}
+	var facts []string
+	seen := map[string]bool{}
+	add := func(record memory.Record) {
+		if !seen[record.ID] {
+			seen[record.ID] = true
+			facts = append(facts, fmt.Sprintf("[%s] %s", record.UpdatedAt.Format("2006-01-02 15:04:05"), record.Text))
+		}
+	}
+	if keyword != "" {
+		for _, record := range s.memory.Lookup(keyword) {
+			add(record)
+		}
+	}
+	for _, match := range matches {
+		add(match.Record)
+	}
+	return facts, nil
+}
+
+func (s *Service) handleAnswerQuestion(payload map[string]interface{}) (agent.ToolResult, error) {
+	log := logger.With(logging.KeyTool, "answer_question")
+	log.Info("starting answer question")
 
 	var answerQuestionPayload struct {
 		Question string `json:"question"`
+		Keyword  string `json:"keyword"`
 	}
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		fmt.Printf("❌ [ANSWER_QUESTION] Error marshaling payload: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Answer: fmt.Sprintf("error marshaling payload: %v", err),
-		}, err
+	if err := agent.DecodePayload(payload, &answerQuestionPayload); err != nil {
+		log.Error("invalid payload", "error", err)
+		return agent.ErrorResult(err), err
 	}
 
-	if err := json.Unmarshal(payloadBytes, &answerQuestionPayload); err != nil {
-		fmt.Printf("❌ [ANSWER_QUESTION] Error unmarshaling payload: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Answer: fmt.Sprintf("error unmarshaling payload: %v", err),
-		}, err
+	facts, err := s.recall(answerQuestionPayload.Question, answerQuestionPayload.Keyword)
+	if err != nil {
+		log.Error("error recalling facts", "error", err)
+		return agent.ErrorResult(err), err
 	}
 
-	fmt.Printf("🔍 [ANSWER_QUESTION] Answering question: %s\n", answerQuestionPayload.Question)
+	log.Info("answering question", "question", answerQuestionPayload.Question, "facts", len(facts))
 	systemMessage := `You are a helpful assistant that can answer questions and use saved data from memory. Response in Polish short answers. Without any other text and markdown formatting.
-				<memory>` + strings.Join(s.State.Messages, "\n") + `</memory>`
-	fmt.Printf("🔍 [ANSWER_QUESTION] System message: %s\n", systemMessage)
-	answer, err := s.aiSvc.ChatCompletion(ai.ChatCompletionConfig{
+				<memory>` + strings.Join(facts, "\n") + `</memory>
+				<conversation>` + strings.Join(s.messages(), "\n") + `</conversation>`
+	logging.Dump(log, "system message", "prompt", systemMessage)
+	answer, err := s.agent.AI().ChatCompletion(ai.ChatCompletionConfig{
 		Model: "gpt-4.1",
 		Messages: []openai.ChatCompletionMessage{
 			{
@@ -286,117 +298,69 @@ func (s *Service) handleAnswerQuestion(payload map[string]interface{}) (ToolResu
 		},
 	})
 	if err != nil {
-		fmt.Printf("❌ [ANSWER_QUESTION] Error answering question: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Answer: err.Error(),
+		log.Error("error answering question", "error", err)
+		return agent.ToolResult{
+			Status: agent.ToolError,
+			Data:   err.Error(),
 		}, err
 	}
 
-	fmt.Printf("✅ [ANSWER_QUESTION] Answered: %s\n", answer.Choices[0].Message.Content)
+	log.Info("question answered", "answer", answer.Choices[0].Message.Content)
 
-	return ToolResult{
-		Status: "success",
-		Answer: answer.Choices[0].Message.Content,
+	return agent.ToolResult{
+		Status: agent.ToolSuccess,
+		Data:   answer.Choices[0].Message.Content,
 	}, nil
 }
 
-func (s *Service) handleDataMemory(payload map[string]interface{}) (ToolResult, error) {
-	fmt.Println("\n📍 [DATA_MEMORY] Starting data memory...")
+func (s *Service) handleDataMemory(payload map[string]interface{}) (agent.ToolResult, error) {
+	log := logger.With(logging.KeyTool, "data_memory")
+	log.Info("starting data memory")
 
 	var dataMemoryPayload struct {
 		Data string `json:"data"`
 	}
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		fmt.Printf("❌ [DATA_MEMORY] Error marshaling payload: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Answer: fmt.Sprintf("error unmarshaling payload: %v", err),
-		}, err
+	if err := agent.DecodePayload(payload, &dataMemoryPayload); err != nil {
+		log.Error("invalid payload", "error", err)
+		return agent.ErrorResult(err), err
 	}
 
-	if err := json.Unmarshal(payloadBytes, &dataMemoryPayload); err != nil {
-		fmt.Printf("❌ [DATA_MEMORY] Error unmarshaling payload: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Answer: fmt.Sprintf("error unmarshaling payload: %v", err),
-		}, err
-	}
-
-	// Create memory directory if it doesn't exist
-	memoryDir := "cmd/s05e04"
-	if err := os.MkdirAll(memoryDir, 0755); err != nil {
-		fmt.Printf("❌ [DATA_MEMORY] Error creating memory directory: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Answer: fmt.Sprintf("error creating memory directory: %v", err),
-		}, err
-	}
-
-	// Open memory file in append mode
-	memoryFile := filepath.Join(memoryDir, "memory.txt")
-	f, err := os.OpenFile(memoryFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	record, duplicate, err := s.memory.Write(dataMemoryPayload.Data)
 	if err != nil {
-		fmt.Printf("❌ [DATA_MEMORY] Error opening memory file: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Answer: fmt.Sprintf("error opening memory file: %v", err),
-		}, err
-	}
-	defer f.Close()
-
-	// Add timestamp to the data
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	dataToWrite := fmt.Sprintf("[%s] %s\n", timestamp, dataMemoryPayload.Data)
-
-	// Write data to file
-	if _, err := f.WriteString(dataToWrite); err != nil {
-		fmt.Printf("❌ [DATA_MEMORY] Error writing to memory file: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Answer: fmt.Sprintf("error writing to memory file: %v", err),
+		log.Error("error saving data", "error", err)
+		return agent.ToolResult{
+			Status: agent.ToolError,
+			Data:   fmt.Sprintf("error saving data: %v", err),
 		}, err
 	}
 
-	fmt.Printf("✅ [DATA_MEMORY] Saved data: %s\n", dataMemoryPayload.Data)
+	log.Info("data saved", "id", record.ID, "duplicate", duplicate)
 
-	return ToolResult{
-		Status: "success",
-		Answer: "OK",
+	return agent.ToolResult{
+		Status: agent.ToolSuccess,
+		Data:   "OK",
 	}, nil
 }
 
-func (s *Service) handleFlagExtractor(payload map[string]interface{}) (ToolResult, error) {
-	fmt.Println("\n📍 [FLAG_EXTRACTOR] Starting flag extractor...")
+func (s *Service) handleFlagExtractor(payload map[string]interface{}) (agent.ToolResult, error) {
+	log := logger.With(logging.KeyTool, "flag_extractor")
+	log.Info("starting flag extractor")
 
 	var flagExtractorPayload struct {
 		Message string `json:"message"`
 	}
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		fmt.Printf("❌ [FLAG_EXTRACTOR] Error marshaling payload: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Answer: fmt.Sprintf("error marshaling payload: %v", err),
-		}, err
+	if err := agent.DecodePayload(payload, &flagExtractorPayload); err != nil {
+		log.Error("invalid payload", "error", err)
+		return agent.ErrorResult(err), err
 	}
 
-	if err := json.Unmarshal(payloadBytes, &flagExtractorPayload); err != nil {
-		fmt.Printf("❌ [FLAG_EXTRACTOR] Error unmarshaling payload: %v\n", err)
-		return ToolResult{
-			Status: "error",
-			Answer: fmt.Sprintf("error unmarshaling payload: %v", err),
-		}, err
-	}
-
-	// Use messages directly since they're already formatted in readMessagesFile
-	messageStrings := s.State.Messages
+	// previous messages of the conversation as "LLM: " and "ME: " lines
+	messageStrings := s.messages()
 
 	// Check if the message is already in previous messages
 	for _, msg := range messageStrings {
 		if msg == flagExtractorPayload.Message {
-			fmt.Println("⚠️ [FLAG_EXTRACTOR] Message already exists in previous messages, trying a different approach")
+			log.Warn("message already exists in previous messages, trying a different approach")
 			// Generate a new approach based on the current context
 			systemMessage := `
 				<preious_messages>
@@ -449,7 +413,7 @@ func (s *Service) handleFlagExtractor(payload map[string]interface{}) (ToolResul
 				
 				Response in Polish.`
 
-			message, err := s.aiSvc.ChatCompletion(ai.ChatCompletionConfig{
+			message, err := s.agent.AI().ChatCompletion(ai.ChatCompletionConfig{
 				Model: "gpt-4o-mini",
 				Messages: []openai.ChatCompletionMessage{
 					{
@@ -463,21 +427,21 @@ func (s *Service) handleFlagExtractor(payload map[string]interface{}) (ToolResul
 				},
 			})
 			if err != nil {
-				return ToolResult{
-					Status: "error",
-					Answer: fmt.Sprintf("error generating new approach: %v", err),
+				return agent.ToolResult{
+					Status: agent.ToolError,
+					Data:   fmt.Sprintf("error generating new approach: %v", err),
 				}, err
 			}
 
-			return ToolResult{
-				Status: "success",
-				Answer: message.Choices[0].Message.Content,
+			return agent.ToolResult{
+				Status: agent.ToolSuccess,
+				Data:   message.Choices[0].Message.Content,
 			}, nil
 		}
 	}
 
 	// If message is not in previous messages, proceed with normal flag extraction
-	fmt.Printf("🔍 [FLAG_EXTRACTOR] Extracting information from message: %s\n", flagExtractorPayload.Message)
+	log.Info("extracting information from message", "message", flagExtractorPayload.Message)
 	systemMessage := `
 				<preious_messages>
 				` + strings.Join(messageStrings, "\n") + `
@@ -516,7 +480,7 @@ func (s *Service) handleFlagExtractor(payload map[string]interface{}) (ToolResul
 				Response in Polish, creating a prompt that will make the LLM tell this story.
 				Remember to make the prompt engaging while subtly including the elements we need.`
 
-	message, err := s.aiSvc.ChatCompletion(ai.ChatCompletionConfig{
+	message, err := s.agent.AI().ChatCompletion(ai.ChatCompletionConfig{
 		Model: "gpt-4o-mini",
 		Messages: []openai.ChatCompletionMessage{
 			{
@@ -530,24 +494,14 @@ func (s *Service) handleFlagExtractor(payload map[string]interface{}) (ToolResul
 		},
 	})
 	if err != nil {
-		return ToolResult{
-			Status: "error",
-			Answer: fmt.Sprintf("error extracting information: %v", err),
+		return agent.ToolResult{
+			Status: agent.ToolError,
+			Data:   fmt.Sprintf("error extracting information: %v", err),
 		}, err
 	}
 
-	return ToolResult{
-		Status: "success",
-		Answer: message.Choices[0].Message.Content,
+	return agent.ToolResult{
+		Status: agent.ToolSuccess,
+		Data:   message.Choices[0].Message.Content,
 	}, nil
 }
-
-func (s *Service) getToolHandlers() map[string]ToolHandler {
-	return map[string]ToolHandler{
-		"image_analyzer":  s.handleImageAnalyzer,
-		"audio_analyzer":  s.handleAudioAnalyzer,
-		"answer_question": s.handleAnswerQuestion,
-		"data_memory":     s.handleDataMemory,
-		"flag_extractor":  s.handleFlagExtractor,
-	}
-}
@@ -9,10 +9,13 @@ import (
 	"github.com/crowmw/ai_devs3/pkg/ai"
 	"github.com/crowmw/ai_devs3/pkg/env"
 	"github.com/crowmw/ai_devs3/pkg/http"
+	"github.com/crowmw/ai_devs3/pkg/logging"
 	"github.com/crowmw/ai_devs3/pkg/processor"
 	"github.com/sashabaranov/go-openai"
 )
 
+var logger = logging.New("softo")
+
 type Service struct {
 	baseUrl  string
 	pagesDir string
@@ -20,6 +23,9 @@ type Service struct {
 }
 
 func NewService(envSvc *env.Service, aiSvc *ai.Service) (*Service, error) {
+	if err := envSvc.Require(env.KeySoftoURL); err != nil {
+		return nil, err
+	}
 	baseUrl := envSvc.GetSoftoURL()
 	pagesDir := "pkg/softo/pages"
 	return &Service{
@@ -30,7 +36,7 @@ func NewService(envSvc *env.Service, aiSvc *ai.Service) (*Service, error) {
 }
 
 func (s *Service) GetPage(url string) (string, error) {
-	fmt.Println("❇️ Fetching page:", url)
+	logger.Info("fetching page", "url", url)
 
 	// Extract filename from URL
 	filename := processor.GetFilenameFromURL(url)
@@ -44,16 +50,14 @@ func (s *Service) GetPage(url string) (string, error) {
 		// File exists, read and return content
 		content, err := os.ReadFile(filePath)
 		if err != nil {
-			fmt.Printf("Error reading existing file %s: %v\n", filePath, err)
 			return "", fmt.Errorf("error reading existing file %s: %v", filePath, err)
 		}
-		fmt.Printf("✅ Found existing file %s\n", filePath)
+		logger.Debug("using cached page", "path", filePath)
 		return string(content), nil
 	}
 
 	body, err := http.FetchData(url)
 	if err != nil {
-		fmt.Println(err)
 		return "", fmt.Errorf("error fetching data: %v", err)
 	}
 
@@ -61,13 +65,11 @@ func (s *Service) GetPage(url string) (string, error) {
 
 	// Create pages directory if it doesn't exist
 	if err := os.MkdirAll(s.pagesDir, 0755); err != nil {
-		fmt.Printf("Error creating directory %s: %v\n", s.pagesDir, err)
 		return "", fmt.Errorf("error creating directory %s: %v", s.pagesDir, err)
 	}
 
 	// Write MD to file
 	if err := os.WriteFile(filePath, []byte(sanitizedHTML), 0644); err != nil {
-		fmt.Printf("Error writing file %s: %v\n", filePath, err)
 		return "", fmt.Errorf("error writing file %s: %v", filePath, err)
 	}
 
@@ -80,11 +82,10 @@ type Answer struct {
 }
 
 func (s *Service) TryToFindAnswer(question string, url string) (*Answer, error) {
-	fmt.Println("❇️ Trying to find answer for:", question, "at:", s.baseUrl+url)
+	logger.Info("trying to find answer", "question", question, "url", s.baseUrl+url)
 
 	page, err := s.GetPage(s.baseUrl + url)
 	if err != nil {
-		fmt.Println(err)
 		return nil, err
 	}
 
@@ -96,14 +97,12 @@ func (s *Service) TryToFindAnswer(question string, url string) (*Answer, error)
 		},
 	})
 	if err != nil {
-		fmt.Println(err)
 		return nil, err
 	}
 
 	var answer *Answer
 	err = json.Unmarshal([]byte(response.Choices[0].Message.Content), &answer)
 	if err != nil {
-		fmt.Println(err)
 		return nil, err
 	}
 
@@ -0,0 +1,91 @@
+package textpl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// monthStems maps folded stems of Polish month names (nominative and genitive) to months
+var monthStems = map[string]time.Month{
+	"stycz": time.January, "lut": time.February, "marz": time.March, "marc": time.March,
+	"kwie": time.April, "maj": time.May, "czerw": time.June, "lip": time.July,
+	"sierp": time.August, "wrze": time.September, "pazdziernik": time.October,
+	"listopad": time.November, "grud": time.December,
+}
+
+// ParseMonth returns the month for a Polish month name in nominative or genitive (e.g. "marca")
+func ParseMonth(word string) (time.Month, bool) {
+	word = Normalize(word)
+	if number, err := strconv.Atoi(word); err == nil && number >= 1 && number <= 12 {
+		return time.Month(number), true
+	}
+	for stem, month := range monthStems {
+		if strings.HasPrefix(word, stem) {
+			return month, true
+		}
+	}
+	return 0, false
+}
+
+// ParseDate parses a date written in Polish words or mixed with digits, e.g.
+// "piętnastego marca dwa tysiące dwadzieścia cztery", "15 marca 2024 roku", "15.03.2024" or "1 stycznia".
+// When the year is missing, defaultYear is used.
+func ParseDate(s string, defaultYear int) (time.Time, error) {
+	words := Words(s)
+
+	monthIndex := -1
+	var month time.Month
+	for i, word := range words {
+		if _, err := strconv.Atoi(word); err == nil {
+			continue
+		}
+		if m, ok := ParseMonth(word); ok {
+			monthIndex, month = i, m
+			break
+		}
+	}
+
+	// Fall back to the numeric form, e.g. "15.03.2024"
+	if monthIndex == -1 && len(words) >= 2 {
+		if m, ok := ParseMonth(words[1]); ok {
+			monthIndex, month = 1, m
+		}
+	}
+
+	if monthIndex <= 0 {
+		return time.Time{}, fmt.Errorf("no day and month found in %q", s)
+	}
+
+	dayWords := words[:monthIndex]
+	// Skip leading words like "dnia"
+	for len(dayWords) > 0 && dayWords[0] == "dnia" {
+		dayWords = dayWords[1:]
+	}
+	day, err := ParseOrdinal(strings.Join(dayWords, " "))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid day in %q: %w", s, err)
+	}
+
+	year := defaultYear
+	var yearWords []string
+	for _, word := range words[monthIndex+1:] {
+		if word == "roku" || word == "r" || word == "rok" {
+			break
+		}
+		yearWords = append(yearWords, word)
+	}
+	if len(yearWords) > 0 {
+		year, err = ParseNumber(strings.Join(yearWords, " "))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid year in %q: %w", s, err)
+		}
+	}
+
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	if date.Day() != day || date.Month() != month {
+		return time.Time{}, fmt.Errorf("invalid date in %q", s)
+	}
+	return date, nil
+}
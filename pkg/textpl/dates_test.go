@@ -0,0 +1,41 @@
+package textpl
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"piętnastego marca dwa tysiące dwadzieścia cztery", "2024-03-15"},
+		{"drugiego marca 2024", "2024-03-02"},
+		{"trzeciego maja 2024 roku", "2024-05-03"},
+		{"dwudziestego drugiego lipca 2023", "2023-07-22"},
+		{"dwudziestego trzeciego grudnia 2023 r.", "2023-12-23"},
+		{"dnia pierwszego stycznia", "2025-01-01"},
+		{"15 marca 2024 roku", "2024-03-15"},
+		{"1 stycznia", "2025-01-01"},
+		{"15.03.2024", "2024-03-15"},
+		{"trzydziestego pierwszego października 2024", "2024-10-31"},
+		{"29 lutego 2024", "2024-02-29"},
+	}
+	for _, tt := range tests {
+		got, err := ParseDate(tt.in, 2025)
+		if err != nil {
+			t.Errorf("ParseDate(%q) error = %v", tt.in, err)
+			continue
+		}
+		if got.Format(time.DateOnly) != tt.want {
+			t.Errorf("ParseDate(%q) = %s, want %s", tt.in, got.Format(time.DateOnly), tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "marca 2024", "trzydziestego lutego 2024", "29 lutego 2023", "jutro"} {
+		if got, err := ParseDate(in, 2025); err == nil {
+			t.Errorf("ParseDate(%q) = %s, want error", in, got.Format(time.DateOnly))
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package textpl
+
+import (
+	"strings"
+	"unicode"
+)
+
+// diacritics maps Polish letters to their ASCII counterparts
+var diacritics = map[rune]rune{
+	'Ą': 'A', 'Ć': 'C', 'Ę': 'E', 'Ł': 'L', 'Ń': 'N', 'Ó': 'O', 'Ś': 'S', 'Ź': 'Z', 'Ż': 'Z',
+	'ą': 'a', 'ć': 'c', 'ę': 'e', 'ł': 'l', 'ń': 'n', 'ó': 'o', 'ś': 's', 'ź': 'z', 'ż': 'z',
+}
+
+// Fold replaces Polish diacritics with plain ASCII letters, keeping the case (e.g. "Łódź" -> "Lodz")
+func Fold(s string) string {
+	return strings.Map(func(r rune) rune {
+		if folded, ok := diacritics[r]; ok {
+			return folded
+		}
+		return r
+	}, s)
+}
+
+// Normalize folds diacritics, lowercases and collapses whitespace
+func Normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(Fold(s))), " ")
+}
+
+// Upper folds diacritics and uppercases, the form used by C3ntrala for names (e.g. "Rafał" -> "RAFAL")
+func Upper(s string) string {
+	return strings.ToUpper(strings.TrimSpace(Fold(s)))
+}
+
+// EqualFold reports whether two strings are equal ignoring case, diacritics and extra whitespace
+func EqualFold(a, b string) bool {
+	return Normalize(a) == Normalize(b)
+}
+
+// Contains reports whether substr is within s, ignoring case and diacritics
+func Contains(s, substr string) bool {
+	return strings.Contains(Normalize(s), Normalize(substr))
+}
+
+// Words splits text into normalized words, dropping punctuation
+func Words(s string) []string {
+	return strings.FieldsFunc(Normalize(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
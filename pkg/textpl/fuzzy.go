@@ -0,0 +1,73 @@
+package textpl
+
+// Levenshtein returns the edit distance between two strings, counted in runes
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 {
+		return len(rb)
+	}
+	if len(rb) == 0 {
+		return len(ra)
+	}
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+// Similarity returns a score between 0 and 1 based on the edit distance of normalized strings
+func Similarity(a, b string) float64 {
+	na, nb := []rune(Normalize(a)), []rune(Normalize(b))
+	longest := max(len(na), len(nb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(Levenshtein(string(na), string(nb)))/float64(longest)
+}
+
+// FuzzyMatch reports whether two strings are within maxDistance edits after normalization
+func FuzzyMatch(a, b string, maxDistance int) bool {
+	return Levenshtein(Normalize(a), Normalize(b)) <= maxDistance
+}
+
+// Match is a candidate found by BestMatch
+type Match struct {
+	Value      string
+	Distance   int
+	Similarity float64
+}
+
+// BestMatch returns the candidate closest to the query after normalization.
+// ok is false when there are no candidates or the best one is below minSimilarity.
+func BestMatch(query string, candidates []string, minSimilarity float64) (Match, bool) {
+	var best Match
+	found := false
+	normalizedQuery := Normalize(query)
+	for _, candidate := range candidates {
+		distance := Levenshtein(normalizedQuery, Normalize(candidate))
+		similarity := Similarity(query, candidate)
+		if !found || distance < best.Distance {
+			best = Match{Value: candidate, Distance: distance, Similarity: similarity}
+			found = true
+		}
+	}
+	if !found || best.Similarity < minSimilarity {
+		return best, false
+	}
+	return best, true
+}
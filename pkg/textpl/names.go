@@ -0,0 +1,80 @@
+package textpl
+
+import "strings"
+
+// caseEndings are common Polish declension endings of first names and surnames, longest first
+var caseEndings = []string{
+	"owie", "owi", "ach", "ami", "iem", "ego", "emu",
+	"om", "em", "ie", "ej", "ow", "ia", "ii",
+	"a", "e", "i", "o", "u", "y",
+}
+
+// Stem returns a crude stem of a Polish name, so that inflected forms share the same value
+// (e.g. "Barbary", "Barbarze" and "Barbara" -> "barbar", "Rafałowi" -> "rafal")
+func Stem(name string) string {
+	word := Normalize(name)
+	for _, ending := range caseEndings {
+		// Keep at least two letters so short names are not stripped to nothing (Ewa, Ewy -> ew)
+		if strings.HasSuffix(word, ending) && len(word)-len(ending) >= 2 {
+			word = strings.TrimSuffix(word, ending)
+			break
+		}
+	}
+	// Stem alternations: Barbarze -> barbar, Aleksandrze -> aleksandr
+	if strings.HasSuffix(word, "rz") && len(word) > 4 {
+		word = strings.TrimSuffix(word, "z")
+	}
+	return word
+}
+
+// MatchName reports whether two names refer to the same person, tolerating case,
+// diacritics and basic inflection (e.g. "Barbary" and "BARBARA")
+func MatchName(a, b string) bool {
+	if EqualFold(a, b) {
+		return true
+	}
+
+	wordsA := strings.Fields(Normalize(a))
+	wordsB := strings.Fields(Normalize(b))
+	if len(wordsA) != len(wordsB) || len(wordsA) == 0 {
+		return false
+	}
+	for i := range wordsA {
+		stemA, stemB := Stem(wordsA[i]), Stem(wordsB[i])
+		if stemA == stemB {
+			continue
+		}
+		// Allow a vowel dropped by inflection in longer stems (e.g. "aleksandr" vs "aleksander"),
+		// never a different letter, Marek and Darek are different people
+		if min(len(stemA), len(stemB)) >= 5 && insertedVowel(stemA, stemB) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// insertedVowel reports whether one stem is the other with a single vowel inserted after the first letter
+func insertedVowel(a, b string) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	if len(b) != len(a)+1 {
+		return false
+	}
+	i := 0
+	for i < len(a) && a[i] == b[i] {
+		i++
+	}
+	return i > 0 && strings.IndexByte("aeiouy", b[i]) >= 0 && a[i:] == b[i+1:]
+}
+
+// FindName returns the first candidate matching the name, or an empty string and false
+func FindName(name string, candidates []string) (string, bool) {
+	for _, candidate := range candidates {
+		if MatchName(name, candidate) {
+			return candidate, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,36 @@
+package textpl
+
+import "testing"
+
+func TestMatchName(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"Barbara", "BARBARA", true},
+		{"Barbary", "Barbara", true},
+		{"Barbarze", "Barbara", true},
+		{"Rafałowi", "RAFAL", true},
+		{"Ewa", "Ewy", true},
+		{"Ewa", "Ewie", true},
+		{"Anna", "Anny", true},
+		{"Aleksander", "Aleksandra", true},
+		{"Jan Kowalski", "Jana Kowalskiego", true},
+		{"Aleksandrem", "Aleksander", true},
+		{"Ewa", "Adam", false},
+		{"Marek", "Darek", false},
+		{"Mariusz", "Dariusz", false},
+		{"Aleksander", "Aleksandar", false},
+		{"Grzegorz", "Grzegosz", false},
+		{"Antoni", "Bantoni", false},
+		{"Adrian", "Adrianx", false},
+		{"Jan", "Janina", false},
+		{"Jan Kowalski", "Jan", false},
+		{"", "Ewa", false},
+	}
+	for _, tt := range tests {
+		if got := MatchName(tt.a, tt.b); got != tt.want {
+			t.Errorf("MatchName(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
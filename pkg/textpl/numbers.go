@@ -0,0 +1,156 @@
+package textpl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// numberWords maps folded Polish cardinal number words to their values
+var numberWords = map[string]int{
+	"zero": 0, "jeden": 1, "jedna": 1, "jedno": 1, "dwa": 2, "dwie": 2, "trzy": 3, "cztery": 4,
+	"piec": 5, "szesc": 6, "siedem": 7, "osiem": 8, "dziewiec": 9, "dziesiec": 10,
+	"jedenascie": 11, "dwanascie": 12, "trzynascie": 13, "czternascie": 14, "pietnascie": 15,
+	"szesnascie": 16, "siedemnascie": 17, "osiemnascie": 18, "dziewietnascie": 19,
+	"dwadziescia": 20, "trzydziesci": 30, "czterdziesci": 40, "piecdziesiat": 50,
+	"szescdziesiat": 60, "siedemdziesiat": 70, "osiemdziesiat": 80, "dziewiecdziesiat": 90,
+	"sto": 100, "dwiescie": 200, "trzysta": 300, "czterysta": 400, "piecset": 500,
+	"szescset": 600, "siedemset": 700, "osiemset": 800, "dziewiecset": 900,
+}
+
+// multiplierWords maps folded Polish words for thousands and millions to their values
+var multiplierWords = map[string]int{
+	"tysiac": 1000, "tysiace": 1000, "tysiecy": 1000,
+	"milion": 1000000, "miliony": 1000000, "milionow": 1000000,
+}
+
+// Places of number words within a group below a thousand
+const (
+	placeUnits = iota + 1
+	placeTens
+	placeHundreds
+)
+
+// numberPlace returns the place of a number word, teens take the place of units
+func numberPlace(value int) int {
+	switch {
+	case value >= 100:
+		return placeHundreds
+	case value >= 20:
+		return placeTens
+	}
+	return placeUnits
+}
+
+// ParseNumber parses a cardinal number written in Polish words or digits
+// (e.g. "dwa tysiące dwadzieścia cztery" -> 2024, "minus pięć" -> -5)
+func ParseNumber(s string) (int, error) {
+	words := Words(s)
+	if len(words) == 0 {
+		return 0, fmt.Errorf("empty number")
+	}
+
+	sign := 1
+	if words[0] == "minus" {
+		sign = -1
+		words = words[1:]
+	}
+
+	// within a group below a thousand hundreds, tens and units come once each in that order,
+	// multipliers in descending order, so "dwa dwa" or "tysiąc tysiąc" is an error instead of a sum
+	total, group := 0, 0
+	place, lastMultiplier := placeHundreds+1, 0
+	seen := false
+	for _, word := range words {
+		if digits, err := strconv.Atoi(word); err == nil {
+			if place <= placeHundreds {
+				return 0, fmt.Errorf("misplaced number %q in %q", word, s)
+			}
+			group += digits
+			place = 0
+			seen = true
+			continue
+		}
+		if value, ok := numberWords[word]; ok {
+			p := numberPlace(value)
+			if p >= place || value >= 10 && value < 20 && place == placeTens {
+				return 0, fmt.Errorf("misplaced number word %q in %q", word, s)
+			}
+			group += value
+			place = p
+			seen = true
+			continue
+		}
+		if multiplier, ok := multiplierWords[word]; ok {
+			if lastMultiplier != 0 && multiplier >= lastMultiplier {
+				return 0, fmt.Errorf("misplaced number word %q in %q", word, s)
+			}
+			// "tysiąc" alone means one thousand
+			if group == 0 {
+				group = 1
+			}
+			total += group * multiplier
+			group = 0
+			place, lastMultiplier = placeHundreds+1, multiplier
+			seen = true
+			continue
+		}
+		if word == "i" {
+			continue
+		}
+		return 0, fmt.Errorf("unknown number word: %q", word)
+	}
+
+	if !seen {
+		return 0, fmt.Errorf("no number found in %q", s)
+	}
+	return sign * (total + group), nil
+}
+
+// ordinalStems maps folded stems of Polish ordinal numbers to their values
+var ordinalStems = map[string]int{
+	"pierwsz": 1, "drug": 2, "trzec": 3, "czwart": 4, "piat": 5, "szost": 6, "siodm": 7,
+	"osm": 8, "dziewiat": 9, "dziesiat": 10, "jedenast": 11, "dwunast": 12, "trzynast": 13,
+	"czternast": 14, "pietnast": 15, "szesnast": 16, "siedemnast": 17, "osiemnast": 18,
+	"dziewietnast": 19, "dwudziest": 20, "trzydziest": 30,
+}
+
+// ordinalEndings are declension endings of ordinal numbers, longest first.
+// Soft stems take "i" before the ending: drugiego, trzeciej, drugiemu.
+var ordinalEndings = []string{"iego", "iemu", "iej", "ego", "emu", "im", "ia", "ie", "ym", "ej", "a", "e", "i", "y"}
+
+// ParseOrdinal parses a Polish ordinal number in any case (e.g. "dwudziestego pierwszego" -> 21)
+func ParseOrdinal(s string) (int, error) {
+	words := Words(s)
+	if len(words) == 0 {
+		return 0, fmt.Errorf("empty ordinal")
+	}
+
+	total := 0
+	for _, word := range words {
+		if digits, err := strconv.Atoi(strings.TrimSuffix(word, ".")); err == nil {
+			total += digits
+			continue
+		}
+		value, ok := ordinalValue(word)
+		if !ok {
+			return 0, fmt.Errorf("unknown ordinal word: %q", word)
+		}
+		total += value
+	}
+	return total, nil
+}
+
+func ordinalValue(word string) (int, bool) {
+	if value, ok := ordinalStems[word]; ok {
+		return value, true
+	}
+	for _, ending := range ordinalEndings {
+		if strings.HasSuffix(word, ending) {
+			if value, ok := ordinalStems[strings.TrimSuffix(word, ending)]; ok {
+				return value, true
+			}
+		}
+	}
+	return 0, false
+}
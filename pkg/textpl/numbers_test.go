@@ -0,0 +1,77 @@
+package textpl
+
+import "testing"
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"zero", 0},
+		{"dwa tysiące dwadzieścia cztery", 2024},
+		{"tysiąc dziewięćset osiemdziesiąt", 1980},
+		{"minus pięć", -5},
+		{"2024", 2024},
+		{"trzy miliony", 3000000},
+		{"milion dwieście tysięcy sto dwanaście", 1200112},
+		{"2 tysiące 5", 2005},
+		{"sto i jeden", 101},
+	}
+	for _, tt := range tests {
+		got, err := ParseNumber(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseNumber(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "minus", "dwa kotki", "dwa dwa", "tysiąc tysiąc", "tysiąc milion",
+		"dwadzieścia trzydzieści", "dwadzieścia dwanaście", "pięć sto", "sto 5", "20 24"} {
+		if got, err := ParseNumber(in); err == nil {
+			t.Errorf("ParseNumber(%q) = %d, want error", in, got)
+		}
+	}
+}
+
+func TestParseOrdinal(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"pierwszy", 1},
+		{"pierwszego", 1},
+		{"drugi", 2},
+		{"drugiego", 2},
+		{"drugiej", 2},
+		{"drugiemu", 2},
+		{"drugim", 2},
+		{"druga", 2},
+		{"trzeci", 3},
+		{"trzeciego", 3},
+		{"trzeciej", 3},
+		{"trzecia", 3},
+		{"czwartego", 4},
+		{"piątego", 5},
+		{"siódmej", 7},
+		{"ósmego", 8},
+		{"piętnastego", 15},
+		{"dwudziestego", 20},
+		{"dwudziestego pierwszego", 21},
+		{"dwudziestego drugiego", 22},
+		{"dwudziestej trzeciej", 23},
+		{"trzydziestego pierwszego", 31},
+		{"15", 15},
+		{"3.", 3},
+	}
+	for _, tt := range tests {
+		got, err := ParseOrdinal(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseOrdinal(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "marca", "drugowy"} {
+		if got, err := ParseOrdinal(in); err == nil {
+			t.Errorf("ParseOrdinal(%q) = %d, want error", in, got)
+		}
+	}
+}
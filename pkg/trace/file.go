@@ -0,0 +1,72 @@
+package trace
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileExporter writes every span as one JSON line, secrets are masked
+type FileExporter struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileExporter creates the trace file and its directory
+func NewFileExporter(path string) (*FileExporter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("error creating trace directory: %w", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("error creating trace file: %w", err)
+	}
+	return &FileExporter{file: file}, nil
+}
+
+func (e *FileExporter) Export(span *Span) error {
+	data, err := json.Marshal(span.redacted())
+	if err != nil {
+		return fmt.Errorf("error marshaling span: %w", err)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err = e.file.Write(append(data, '\n'))
+	return err
+}
+
+func (e *FileExporter) Shutdown() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.file.Close()
+}
+
+// ReadFile loads spans from a JSONL trace file
+func ReadFile(path string) ([]*Span, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening trace file: %w", err)
+	}
+	defer file.Close()
+
+	var spans []*Span
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		span := &Span{}
+		if err := json.Unmarshal(scanner.Bytes(), span); err != nil {
+			return nil, fmt.Errorf("error parsing %s line %d: %w", path, line, err)
+		}
+		spans = append(spans, span)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading trace file: %w", err)
+	}
+	return spans, nil
+}
@@ -0,0 +1,40 @@
+package trace
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/crowmw/ai_devs3/pkg/redact"
+)
+
+// Transport records every request made through Base as an http span, a child of the span carried
+// by the request context. The span ends when the response headers arrive, reading the body is not included.
+type Transport struct {
+	Base http.RoundTripper
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	_, span := Start(req.Context(), req.Method+" "+req.URL.Host, KindHTTP,
+		"method", req.Method,
+		"url", redact.String(req.URL.String()),
+	)
+	defer span.End()
+	if req.ContentLength > 0 {
+		span.Set("request_bytes", req.ContentLength)
+	}
+
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		span.SetError(redact.Error(err))
+		return nil, err
+	}
+
+	span.Set("status_code", resp.StatusCode)
+	if resp.ContentLength >= 0 {
+		span.Set("response_bytes", resp.ContentLength)
+	}
+	if resp.StatusCode >= 400 {
+		span.SetError(errors.New(resp.Status))
+	}
+	return resp, nil
+}
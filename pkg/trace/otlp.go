@@ -0,0 +1,206 @@
+package trace
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// otlpBatchSize is the number of spans sent to the collector in one request
+const otlpBatchSize = 64
+
+// OTLPExporter sends spans to an OpenTelemetry collector using OTLP/HTTP with JSON encoding
+type OTLPExporter struct {
+	url     string
+	service string
+	client  *http.Client
+
+	mu      sync.Mutex
+	pending []*Span
+}
+
+// NewOTLPExporter creates an exporter for a collector at endpoint, e.g. http://localhost:4318.
+// Requests go through transport directly so that they are not traced themselves.
+func NewOTLPExporter(endpoint, service string, transport http.RoundTripper) *OTLPExporter {
+	return &OTLPExporter{
+		url:     strings.TrimRight(endpoint, "/") + "/v1/traces",
+		service: service,
+		client:  &http.Client{Transport: transport},
+	}
+}
+
+func (e *OTLPExporter) Export(span *Span) error {
+	e.mu.Lock()
+	e.pending = append(e.pending, span)
+	if len(e.pending) < otlpBatchSize {
+		e.mu.Unlock()
+		return nil
+	}
+	batch := e.pending
+	e.pending = nil
+	e.mu.Unlock()
+	return e.send(batch)
+}
+
+func (e *OTLPExporter) Shutdown() error {
+	e.mu.Lock()
+	batch := e.pending
+	e.pending = nil
+	e.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+	return e.send(batch)
+}
+
+func (e *OTLPExporter) send(spans []*Span) error {
+	request := otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: []otlpKeyValue{{Key: "service.name", Value: otlpValue{StringValue: &e.service}}}},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: "github.com/crowmw/ai_devs3/pkg/trace"},
+		}},
+	}}}
+	scope := &request.ResourceSpans[0].ScopeSpans[0]
+	for _, span := range spans {
+		scope.Spans = append(scope.Spans, toOTLP(span.redacted()))
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("error marshaling OTLP request: %w", err)
+	}
+
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error sending spans to collector: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("collector returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// OTLP/JSON payload, see opentelemetry-proto trace/v1/trace.proto
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// OTLP span kinds and status codes
+const (
+	otlpKindInternal = 1
+	otlpKindClient   = 3
+	otlpStatusOK     = 1
+	otlpStatusError  = 2
+)
+
+func toOTLP(span *Span) otlpSpan {
+	kind := otlpKindInternal
+	if span.Kind == KindHTTP || span.Kind == KindLLM {
+		kind = otlpKindClient
+	}
+	status := otlpStatus{Code: otlpStatusOK}
+	if span.Status == StatusError {
+		status = otlpStatus{Code: otlpStatusError, Message: span.Error}
+	}
+
+	result := otlpSpan{
+		TraceID:           span.TraceID,
+		SpanID:            span.SpanID,
+		ParentSpanID:      span.ParentID,
+		Name:              span.Name,
+		Kind:              kind,
+		StartTimeUnixNano: strconv.FormatInt(span.StartTime.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(span.EndTime.UnixNano(), 10),
+		Status:            status,
+	}
+	result.Attributes = append(result.Attributes, otlpKeyValue{Key: "kind", Value: otlpString(span.Kind)})
+	for key, value := range span.Attrs {
+		result.Attributes = append(result.Attributes, otlpKeyValue{Key: key, Value: otlpAttr(value)})
+	}
+	return result
+}
+
+// otlpAttr converts scalar values to their OTLP type, anything else is sent as JSON text
+func otlpAttr(value any) otlpValue {
+	switch v := value.(type) {
+	case string:
+		return otlpString(v)
+	case bool:
+		return otlpValue{BoolValue: &v}
+	case int:
+		s := strconv.Itoa(v)
+		return otlpValue{IntValue: &s}
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		return otlpValue{IntValue: &s}
+	case float64:
+		return otlpValue{DoubleValue: &v}
+	case fmt.Stringer:
+		return otlpString(v.String())
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return otlpString(fmt.Sprint(value))
+	}
+	return otlpString(string(data))
+}
+
+func otlpString(s string) otlpValue {
+	return otlpValue{StringValue: &s}
+}
@@ -0,0 +1,308 @@
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/crowmw/ai_devs3/pkg/logging"
+	"github.com/crowmw/ai_devs3/pkg/redact"
+)
+
+var logger = logging.New("trace")
+
+// Span kinds
+const (
+	KindRun   = "run"
+	KindPhase = "phase"
+	KindLLM   = "llm"
+	KindTool  = "tool"
+	KindHTTP  = "http"
+)
+
+// Span statuses
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// Span is a single timed operation of a run. A nil *Span is valid and does nothing,
+// which is what Start returns while tracing is disabled.
+type Span struct {
+	TraceID    string         `json:"trace_id"`
+	SpanID     string         `json:"span_id"`
+	ParentID   string         `json:"parent_id,omitempty"`
+	Name       string         `json:"name"`
+	Kind       string         `json:"kind"`
+	StartTime  time.Time      `json:"start"`
+	EndTime    time.Time      `json:"end"`
+	DurationMs float64        `json:"duration_ms"`
+	Status     string         `json:"status"`
+	Error      string         `json:"error,omitempty"`
+	Attrs      map[string]any `json:"attrs,omitempty"`
+
+	mu     sync.Mutex
+	tracer *Tracer
+	ended  bool
+}
+
+// Set adds key/value pairs to the span, like the arguments of slog
+func (s *Span) Set(args ...any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	setAttrs(s.Attrs, args)
+}
+
+// SetError marks the span as failed, a nil error is ignored
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Status = StatusError
+	s.Error = err.Error()
+}
+
+// End finishes the span and hands it to the exporters, later calls do nothing
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.EndTime = time.Now()
+	s.DurationMs = float64(s.EndTime.Sub(s.StartTime).Microseconds()) / 1000
+	s.mu.Unlock()
+
+	s.tracer.finish(s)
+}
+
+// redacted returns a copy of an ended span with secrets masked in its error and attributes,
+// exporters marshal it instead of masking the marshaled bytes
+func (s *Span) redacted() *Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &Span{
+		TraceID:    s.TraceID,
+		SpanID:     s.SpanID,
+		ParentID:   s.ParentID,
+		Name:       redact.String(s.Name),
+		Kind:       s.Kind,
+		StartTime:  s.StartTime,
+		EndTime:    s.EndTime,
+		DurationMs: s.DurationMs,
+		Status:     s.Status,
+		Error:      redact.String(s.Error),
+		Attrs:      redact.Attrs(s.Attrs),
+	}
+}
+
+// Exporter receives finished spans
+type Exporter interface {
+	Export(span *Span) error
+	Shutdown() error
+}
+
+// Tracer records the spans of one run
+type Tracer struct {
+	traceID   string
+	exporters []Exporter
+
+	mu sync.Mutex
+	// open holds the spans not ended yet, in start order
+	open []*Span
+	// root is the first span started without a parent while it is open, usually the run
+	root *Span
+}
+
+// NewTracer creates a tracer with a fresh trace id
+func NewTracer(exporters ...Exporter) *Tracer {
+	return &Tracer{traceID: randomID(16), exporters: exporters}
+}
+
+// TraceID identifies every span recorded by the tracer
+func (t *Tracer) TraceID() string {
+	return t.traceID
+}
+
+// Start opens a child span of parent. A span without a parent becomes a child of the root span,
+// the first one started without a parent, so calls that carry no context still land in their run.
+func (t *Tracer) Start(parent *Span, name, kind string, args ...any) *Span {
+	span := &Span{
+		TraceID:   t.traceID,
+		SpanID:    randomID(8),
+		Name:      name,
+		Kind:      kind,
+		StartTime: time.Now(),
+		Status:    StatusOK,
+		Attrs:     make(map[string]any),
+		tracer:    t,
+	}
+	setAttrs(span.Attrs, args)
+
+	t.mu.Lock()
+	switch {
+	case parent != nil:
+		span.ParentID = parent.SpanID
+	case t.root != nil:
+		span.ParentID = t.root.SpanID
+	default:
+		t.root = span
+	}
+	t.open = append(t.open, span)
+	t.mu.Unlock()
+	return span
+}
+
+func (t *Tracer) finish(span *Span) {
+	t.mu.Lock()
+	for i := len(t.open) - 1; i >= 0; i-- {
+		if t.open[i] == span {
+			t.open = append(t.open[:i], t.open[i+1:]...)
+			break
+		}
+	}
+	if t.root == span {
+		t.root = nil
+	}
+	t.mu.Unlock()
+
+	for _, exporter := range t.exporters {
+		if err := exporter.Export(span); err != nil {
+			logger.Warn("could not export span", "span", span.Name, "error", err)
+		}
+	}
+}
+
+// Shutdown ends spans left open and flushes the exporters
+func (t *Tracer) Shutdown() error {
+	t.mu.Lock()
+	open := append([]*Span(nil), t.open...)
+	t.mu.Unlock()
+	for i := len(open) - 1; i >= 0; i-- {
+		open[i].SetError(errors.New("span was not ended"))
+		open[i].End()
+	}
+
+	var errs []error
+	for _, exporter := range t.exporters {
+		errs = append(errs, exporter.Shutdown())
+	}
+	return errors.Join(errs...)
+}
+
+// Options configure tracing of a run
+type Options struct {
+	// File is the JSONL file spans are written to, empty disables it
+	File string
+	// OTLPEndpoint is the base URL of an OTLP/HTTP collector, e.g. http://localhost:4318
+	OTLPEndpoint string
+	// Service is reported to the collector as service.name
+	Service string
+}
+
+var current atomic.Pointer[Tracer]
+
+// Setup installs the tracer used by Start and wraps http.DefaultTransport so that every
+// HTTP call is recorded. Nothing is traced when neither a file nor an endpoint is given.
+// The returned function flushes the exporters and restores the transport.
+func Setup(opts Options) (*Tracer, func() error, error) {
+	if opts.File == "" && opts.OTLPEndpoint == "" {
+		return nil, func() error { return nil }, nil
+	}
+
+	base := http.DefaultTransport
+	var exporters []Exporter
+	if opts.File != "" {
+		exporter, err := NewFileExporter(opts.File)
+		if err != nil {
+			return nil, nil, err
+		}
+		exporters = append(exporters, exporter)
+	}
+	if opts.OTLPEndpoint != "" {
+		service := opts.Service
+		if service == "" {
+			service = "aidevs"
+		}
+		exporters = append(exporters, NewOTLPExporter(opts.OTLPEndpoint, service, base))
+	}
+
+	tracer := NewTracer(exporters...)
+	current.Store(tracer)
+	http.DefaultTransport = &Transport{Base: base}
+
+	shutdown := func() error {
+		http.DefaultTransport = base
+		current.CompareAndSwap(tracer, nil)
+		return tracer.Shutdown()
+	}
+	return tracer, shutdown, nil
+}
+
+type spanKey struct{}
+
+// ContextWithSpan returns a copy of ctx carrying span, spans started from it become its children
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	if span == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span carried by ctx, or nil
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// Start opens a span on the tracer installed by Setup as a child of the span carried by ctx and
+// returns ctx carrying the new span. Pass the returned context to the calls the span covers,
+// also to goroutines, so their spans get the right parent. It returns ctx and nil when tracing is off.
+func Start(ctx context.Context, name, kind string, args ...any) (context.Context, *Span) {
+	tracer := current.Load()
+	if tracer == nil {
+		return ctx, nil
+	}
+	span := tracer.Start(SpanFromContext(ctx), name, kind, args...)
+	return ContextWithSpan(ctx, span), span
+}
+
+// Enabled reports whether spans are being recorded, use it to skip building costly attributes
+func Enabled() bool {
+	return current.Load() != nil
+}
+
+func setAttrs(attrs map[string]any, args []any) {
+	for i := 0; i < len(args); i += 2 {
+		key := fmt.Sprint(args[i])
+		if i+1 == len(args) {
+			attrs["!BADKEY"] = args[i]
+			break
+		}
+		attrs[key] = args[i+1]
+	}
+}
+
+func randomID(size int) string {
+	b := make([]byte, size)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
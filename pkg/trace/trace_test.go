@@ -0,0 +1,129 @@
+package trace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type recorder struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+func (r *recorder) Export(span *Span) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, span)
+	return nil
+}
+
+func (r *recorder) Shutdown() error { return nil }
+
+func install(t *testing.T, exporters ...Exporter) *Tracer {
+	t.Helper()
+	tracer := NewTracer(exporters...)
+	current.Store(tracer)
+	t.Cleanup(func() { current.CompareAndSwap(tracer, nil) })
+	return tracer
+}
+
+func TestStartParents(t *testing.T) {
+	install(t, &recorder{})
+
+	ctx, run := Start(context.Background(), "run", KindRun)
+	defer run.End()
+
+	// actions of a batch run at the same time, each tool span belongs to its own action
+	const actions = 8
+	type pair struct{ action, tool *Span }
+	pairs := make([]pair, actions)
+	var wg sync.WaitGroup
+	for i := 0; i < actions; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			actionCtx, action := Start(ctx, "action", KindPhase)
+			_, tool := Start(actionCtx, "tool", KindTool)
+			pairs[i] = pair{action, tool}
+			tool.End()
+			action.End()
+		}(i)
+	}
+	wg.Wait()
+
+	for i, p := range pairs {
+		if p.action.ParentID != run.SpanID {
+			t.Errorf("action %d: got parent %s, want the run %s", i, p.action.ParentID, run.SpanID)
+		}
+		if p.tool.ParentID != p.action.SpanID {
+			t.Errorf("tool %d: got parent %s, want its action %s", i, p.tool.ParentID, p.action.SpanID)
+		}
+	}
+
+	// a span without a context parent goes under the run, not under a span that happens to be open
+	_, phase := Start(ctx, "phase", KindPhase)
+	_, orphan := Start(context.Background(), "embedding", KindLLM)
+	if orphan.ParentID != run.SpanID {
+		t.Errorf("got parent %s, want the run %s", orphan.ParentID, run.SpanID)
+	}
+	orphan.End()
+	phase.End()
+}
+
+func TestStartDisabled(t *testing.T) {
+	ctx := context.Background()
+	got, span := Start(ctx, "run", KindRun)
+	if span != nil || got != ctx {
+		t.Errorf("Start() = %v, %v, want the context and no span", got, span)
+	}
+	span.Set("key", "value")
+	span.End()
+}
+
+func TestFileExporterMasksSecrets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.jsonl")
+	exporter, err := NewFileExporter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	install(t, exporter)
+
+	_, span := Start(context.Background(), "POST c3ntrala.ag3nts.org", KindHTTP,
+		"url", "https://c3ntrala.ag3nts.org/report?apikey=secret-in-url&task=loop",
+		"payload", map[string]any{"apikey": "secret-in-map", "answer": "ELBLAG"},
+		"body", `{"apikey":"secret-in-json","answer":"ELBLAG"}`,
+		"request", struct {
+			APIKey string `json:"apikey"`
+			Task   string `json:"task"`
+		}{"secret-in-struct", "loop"},
+		"token", 123456,
+	)
+	span.End()
+	if err := exporter.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"secret-in-url", "secret-in-map", "secret-in-json", "secret-in-struct", "123456"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("trace file contains %q: %s", secret, data)
+		}
+	}
+	spans, err := ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spans) != 1 || spans[0].Attrs["body"] != `{"apikey":"[REDACTED]","answer":"ELBLAG"}` {
+		t.Errorf("got spans %+v", spans)
+	}
+	if span.Attrs["token"] != 123456 {
+		t.Errorf("the span itself was changed: %v", span.Attrs)
+	}
+}
@@ -0,0 +1,197 @@
+package traceview
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/crowmw/ai_devs3/pkg/trace"
+)
+
+// RunDiff compares two runs of the same task step by step
+type RunDiff struct {
+	A       *Run
+	B       *Run
+	Entries []*DiffEntry
+}
+
+// DiffEntry pairs a phase, llm or tool span of run A with the matching span of run B.
+// One side is nil when the span exists in only one run.
+type DiffEntry struct {
+	A       *Node
+	B       *Node
+	Changed bool
+	// Sections hold line diffs of prompts, responses, payloads and results
+	Sections []DiffSection
+}
+
+// DiffSection is a line diff of one span attribute
+type DiffSection struct {
+	Name  string
+	Lines []DiffLine
+}
+
+// DiffLine is a line kept ("="), removed from A ("-") or added in B ("+")
+type DiffLine struct {
+	Op   string
+	Text string
+}
+
+// DiffRuns aligns the phase, llm and tool spans of two runs in order of execution
+func DiffRuns(a, b *Run) *RunDiff {
+	nodesA, nodesB := diffNodes(a), diffNodes(b)
+	keysA, keysB := make([]string, len(nodesA)), make([]string, len(nodesB))
+	for i, node := range nodesA {
+		keysA[i] = node.Span.Kind + "/" + node.Span.Name
+	}
+	for i, node := range nodesB {
+		keysB[i] = node.Span.Kind + "/" + node.Span.Name
+	}
+
+	diff := &RunDiff{A: a, B: b}
+	for _, pair := range align(keysA, keysB) {
+		entry := &DiffEntry{}
+		if pair[0] >= 0 {
+			entry.A = nodesA[pair[0]]
+		}
+		if pair[1] >= 0 {
+			entry.B = nodesB[pair[1]]
+		}
+		entry.Changed = entry.A == nil || entry.B == nil
+		for _, name := range []string{"prompt", "response", "payload", "result", "error"} {
+			textA, textB := diffText(entry.A, name), diffText(entry.B, name)
+			if textA == "" && textB == "" {
+				continue
+			}
+			if textA != textB {
+				entry.Changed = true
+			}
+			entry.Sections = append(entry.Sections, DiffSection{Name: name, Lines: diffLines(textA, textB)})
+		}
+		diff.Entries = append(diff.Entries, entry)
+	}
+	return diff
+}
+
+func diffNodes(run *Run) []*Node {
+	var nodes []*Node
+	for _, node := range run.Rows {
+		switch node.Span.Kind {
+		case trace.KindPhase, trace.KindLLM, trace.KindTool:
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// diffText renders the compared attribute of a span as text
+func diffText(node *Node, name string) string {
+	if node == nil {
+		return ""
+	}
+	span := node.Span
+	switch name {
+	case "prompt":
+		return formatMessages(span.Attrs["messages"])
+	case "error":
+		return span.Error
+	}
+	value, ok := span.Attrs[name]
+	if !ok {
+		return ""
+	}
+	return formatValue(value)
+}
+
+// formatMessages renders the messages attribute of an llm span
+func formatMessages(value any) string {
+	messages, ok := value.([]any)
+	if !ok {
+		return ""
+	}
+	var b strings.Builder
+	for _, message := range messages {
+		m, _ := message.(map[string]any)
+		fmt.Fprintf(&b, "[%v]\n%v\n", m["role"], m["content"])
+	}
+	return b.String()
+}
+
+// formatValue renders strings as they are and anything else as indented JSON
+func formatValue(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// diffLines returns a line diff of a and b
+func diffLines(a, b string) []DiffLine {
+	linesA, linesB := splitLines(a), splitLines(b)
+	var lines []DiffLine
+	for _, pair := range align(linesA, linesB) {
+		switch {
+		case pair[0] >= 0 && pair[1] >= 0:
+			lines = append(lines, DiffLine{Op: "=", Text: linesA[pair[0]]})
+		case pair[0] >= 0:
+			lines = append(lines, DiffLine{Op: "-", Text: linesA[pair[0]]})
+		default:
+			lines = append(lines, DiffLine{Op: "+", Text: linesB[pair[1]]})
+		}
+	}
+	return lines
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimRight(s, "\n"), "\n")
+}
+
+// align matches equal items of a and b using the longest common subsequence.
+// Each pair holds an index into a and an index into b, -1 marks a missing side.
+func align(a, b []string) [][2]int {
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var pairs [][2]int
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			pairs = append(pairs, [2]int{i, j})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			pairs = append(pairs, [2]int{i, -1})
+			i++
+		default:
+			pairs = append(pairs, [2]int{-1, j})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		pairs = append(pairs, [2]int{i, -1})
+	}
+	for ; j < len(b); j++ {
+		pairs = append(pairs, [2]int{-1, j})
+	}
+	return pairs
+}
@@ -0,0 +1,265 @@
+package traceview
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/crowmw/ai_devs3/pkg/ai"
+	"github.com/crowmw/ai_devs3/pkg/logging"
+	"github.com/crowmw/ai_devs3/pkg/trace"
+)
+
+// Run is a trace file arranged as a span tree
+type Run struct {
+	File     string
+	Task     string
+	TraceID  string
+	Start    time.Time
+	Duration time.Duration
+	Status   string
+	Error    string
+	// Rows are all spans in depth first order, ready for the timeline
+	Rows  []*Node
+	Steps []*Step
+	Usage Usage
+}
+
+// Node is a span with its children and position on the timeline
+type Node struct {
+	Span     *trace.Span
+	Children []*Node
+	Depth    int
+	// Offset and Width are percentages of the run duration
+	Offset float64
+	Width  float64
+	// Usage sums the llm spans of the subtree
+	Usage Usage
+}
+
+// Step groups the cost of one agent phase, or of a top level span when the run has no phases
+type Step struct {
+	Label    string
+	Duration time.Duration
+	Usage    Usage
+}
+
+// Usage is the token count and estimated cost of llm calls
+type Usage struct {
+	Calls            int
+	PromptTokens     int
+	CompletionTokens int
+	Cost             float64
+}
+
+func (u *Usage) add(other Usage) {
+	u.Calls += other.Calls
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.Cost += other.Cost
+}
+
+// Tokens is the total number of tokens
+func (u Usage) Tokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// LoadRun reads a JSONL trace file written by the trace package
+func LoadRun(path string) (*Run, error) {
+	spans, err := trace.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(spans) == 0 {
+		return nil, fmt.Errorf("trace file %s has no spans", path)
+	}
+	return buildRun(filepath.Base(path), spans), nil
+}
+
+func buildRun(file string, spans []*trace.Span) *Run {
+	run := &Run{File: file, TraceID: spans[0].TraceID, Status: trace.StatusOK}
+
+	nodes := make(map[string]*Node, len(spans))
+	for _, span := range spans {
+		nodes[span.SpanID] = &Node{Span: span}
+	}
+	var roots []*Node
+	for _, span := range spans {
+		node := nodes[span.SpanID]
+		if parent, ok := nodes[span.ParentID]; ok && span.ParentID != "" {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	run.Start = spans[0].StartTime
+	end := spans[0].EndTime
+	for _, span := range spans {
+		if span.StartTime.Before(run.Start) {
+			run.Start = span.StartTime
+		}
+		if span.EndTime.After(end) {
+			end = span.EndTime
+		}
+	}
+	run.Duration = end.Sub(run.Start)
+
+	sortNodes(roots)
+	for _, root := range roots {
+		run.Usage.add(run.walk(root, 0))
+		if root.Span.Kind == trace.KindRun && run.Task == "" {
+			run.Task, _ = root.Span.Attrs[logging.KeyTask].(string)
+			run.Status, run.Error = root.Span.Status, root.Span.Error
+		}
+	}
+	if run.Task == "" {
+		run.Task = taskFromFile(file)
+	}
+
+	run.Steps = collectSteps(roots)
+	return run
+}
+
+// walk places the subtree on the timeline and returns its usage
+func (r *Run) walk(node *Node, depth int) Usage {
+	node.Depth = depth
+	if r.Duration > 0 {
+		node.Offset = 100 * float64(node.Span.StartTime.Sub(r.Start)) / float64(r.Duration)
+		node.Width = 100 * float64(node.Span.EndTime.Sub(node.Span.StartTime)) / float64(r.Duration)
+	}
+	r.Rows = append(r.Rows, node)
+
+	node.Usage = spanUsage(node.Span)
+	sortNodes(node.Children)
+	for _, child := range node.Children {
+		node.Usage.add(r.walk(child, depth+1))
+	}
+	return node.Usage
+}
+
+// collectSteps returns phase spans, or the children of the run span when there are none
+func collectSteps(roots []*Node) []*Step {
+	var steps []*Step
+	var visit func(node *Node)
+	visit = func(node *Node) {
+		if node.Span.Kind == trace.KindPhase {
+			label := node.Span.Name
+			if step, ok := node.Span.Attrs["step"].(float64); ok {
+				label = fmt.Sprintf("step %d · %s", int(step), label)
+			}
+			steps = append(steps, &Step{Label: label, Duration: spanDuration(node.Span), Usage: node.Usage})
+			return
+		}
+		for _, child := range node.Children {
+			visit(child)
+		}
+	}
+	for _, root := range roots {
+		visit(root)
+	}
+	if len(steps) > 0 {
+		return steps
+	}
+
+	for _, root := range roots {
+		for _, child := range root.Children {
+			if child.Usage.Calls > 0 {
+				steps = append(steps, &Step{Label: child.Span.Name, Duration: spanDuration(child.Span), Usage: child.Usage})
+			}
+		}
+	}
+	return steps
+}
+
+func spanUsage(span *trace.Span) Usage {
+	if span.Kind != trace.KindLLM {
+		return Usage{}
+	}
+	usage := Usage{
+		Calls:            1,
+		PromptTokens:     intAttr(span, "prompt_tokens"),
+		CompletionTokens: intAttr(span, "completion_tokens"),
+	}
+	model, _ := span.Attrs["model"].(string)
+	usage.Cost = ai.EstimateCost(model, usage.PromptTokens, usage.CompletionTokens)
+	return usage
+}
+
+func spanDuration(span *trace.Span) time.Duration {
+	return span.EndTime.Sub(span.StartTime)
+}
+
+// intAttr reads a number attribute, JSON decoding turns them into float64
+func intAttr(span *trace.Span, key string) int {
+	switch v := span.Attrs[key].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
+func sortNodes(nodes []*Node) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].Span.StartTime.Before(nodes[j].Span.StartTime)
+	})
+}
+
+// taskFromFile extracts the task from names like s05e02-20250101-120000.jsonl
+func taskFromFile(file string) string {
+	name := strings.TrimSuffix(file, filepath.Ext(file))
+	if i := strings.Index(name, "-"); i > 0 {
+		return name[:i]
+	}
+	return name
+}
+
+// Summary describes a trace file in the run list
+type Summary struct {
+	File     string
+	Task     string
+	Start    time.Time
+	Duration time.Duration
+	Status   string
+	Spans    int
+	Usage    Usage
+}
+
+// ListRuns summarizes every trace file in dir, newest first
+func ListRuns(dir string) ([]Summary, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading trace directory: %w", err)
+	}
+
+	var summaries []Summary
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".jsonl" {
+			continue
+		}
+		run, err := LoadRun(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			logger.Warn("skipping trace file", "file", entry.Name(), "error", err)
+			continue
+		}
+		summaries = append(summaries, Summary{
+			File:     run.File,
+			Task:     run.Task,
+			Start:    run.Start,
+			Duration: run.Duration,
+			Status:   run.Status,
+			Spans:    len(run.Rows),
+			Usage:    run.Usage,
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Start.After(summaries[j].Start) })
+	return summaries, nil
+}
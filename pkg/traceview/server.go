@@ -0,0 +1,143 @@
+package traceview
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/crowmw/ai_devs3/pkg/logging"
+)
+
+var logger = logging.New("traceview")
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// Server renders trace files from a directory as HTML pages
+type Server struct {
+	dir       string
+	templates *template.Template
+	mux       *http.ServeMux
+}
+
+// NewServer creates a viewer for the trace files in dir, usually data/traces
+func NewServer(dir string) (*Server, error) {
+	templates, err := template.New("").Funcs(templateFuncs).ParseFS(templateFS, "templates/*.html")
+	if err != nil {
+		return nil, fmt.Errorf("error parsing templates: %w", err)
+	}
+
+	s := &Server{dir: dir, templates: templates, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/", s.handleList)
+	s.mux.HandleFunc("/run", s.handleRun)
+	s.mux.HandleFunc("/diff", s.handleDiff)
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger.Debug("request", "method", r.Method, "path", r.URL.Path, "query", r.URL.RawQuery)
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	runs, err := ListRuns(s.dir)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// previous maps a run to the run of the same task before it, for the compare links
+	previous := make(map[string]string)
+	for i, run := range runs {
+		for _, older := range runs[i+1:] {
+			if older.Task == run.Task {
+				previous[run.File] = older.File
+				break
+			}
+		}
+	}
+	s.render(w, "list.html", map[string]any{"Dir": s.dir, "Runs": runs, "Previous": previous})
+}
+
+func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
+	run, err := s.loadRun(r.URL.Query().Get("file"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	s.render(w, "run.html", run)
+}
+
+func (s *Server) handleDiff(w http.ResponseWriter, r *http.Request) {
+	a, err := s.loadRun(r.URL.Query().Get("a"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	b, err := s.loadRun(r.URL.Query().Get("b"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	s.render(w, "diff.html", DiffRuns(a, b))
+}
+
+// loadRun reads a trace file by name, paths outside the trace directory are refused
+func (s *Server) loadRun(file string) (*Run, error) {
+	if file == "" || file != filepath.Base(file) || filepath.Ext(file) != ".jsonl" {
+		return nil, fmt.Errorf("invalid trace file %q", file)
+	}
+	return LoadRun(filepath.Join(s.dir, file))
+}
+
+func (s *Server) render(w http.ResponseWriter, name string, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.templates.ExecuteTemplate(w, name, data); err != nil {
+		logger.Error("error rendering template", "template", name, "error", err)
+	}
+}
+
+var templateFuncs = template.FuncMap{
+	"duration": func(d time.Duration) string {
+		switch {
+		case d >= time.Minute:
+			return d.Round(time.Second).String()
+		case d >= time.Second:
+			return fmt.Sprintf("%.2fs", d.Seconds())
+		}
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	},
+	"spanDuration": spanDuration,
+	"cost": func(usd float64) string {
+		if usd == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("$%.4f", usd)
+	},
+	"pct": func(v float64) string {
+		return fmt.Sprintf("%.2f%%", v)
+	},
+	"indent": func(depth int) string {
+		return fmt.Sprintf("%.1fem", float64(depth)*1.2)
+	},
+	"messages": func(value any) []map[string]any {
+		items, _ := value.([]any)
+		var messages []map[string]any
+		for _, item := range items {
+			if m, ok := item.(map[string]any); ok {
+				messages = append(messages, m)
+			}
+		}
+		return messages
+	},
+	"format": formatValue,
+	"lower":  strings.ToLower,
+}
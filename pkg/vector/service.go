@@ -9,10 +9,13 @@ import (
 
 	"github.com/crowmw/ai_devs3/pkg/ai"
 	"github.com/crowmw/ai_devs3/pkg/env"
+	"github.com/crowmw/ai_devs3/pkg/logging"
 	"github.com/google/uuid"
 	"github.com/qdrant/go-client/qdrant"
 )
 
+var logger = logging.New("vector")
+
 // Point represents a data point to be stored in the vector database
 type Point struct {
 	ID      string
@@ -37,6 +40,10 @@ type Service struct {
 
 // NewService creates a new VectorService instance
 func NewService(envSvc *env.Service, openAISvc *ai.Service, dimensions uint64) (*Service, error) {
+	if err := envSvc.Require(env.KeyQdrantURL, env.KeyQdrantAPIKey); err != nil {
+		return nil, err
+	}
+
 	client, err := qdrant.NewClient(&qdrant.Config{
 		Host:   envSvc.GetQdrantURL(),
 		Port:   6334,
@@ -153,13 +160,13 @@ type NewPoint struct {
 
 // AddPoints adds points to a collection and returns the added points
 func (s *Service) AddPoints(ctx context.Context, collection string, points []NewPoint) ([]*qdrant.PointStruct, error) {
-	fmt.Println("🔍 Adding points to collection:", collection)
+	logger.Info("adding points", "collection", collection, "points", len(points))
 	if err := s.EnsureCollectionExists(ctx, collection); err != nil {
 		return nil, err
 	}
 	pointsToUpsert := make([]*qdrant.PointStruct, len(points))
 	for i, point := range points {
-		fmt.Println("🔍 Creating embedding for point:", point.Metadata)
+		logger.Debug("creating embedding", "metadata", point.Metadata)
 		embedding, err := s.openAISvc.CreateJinaEmbedding(point.Text)
 		if err != nil {
 			return nil, fmt.Errorf("error creating embedding: %w", err)
@@ -178,13 +185,13 @@ func (s *Service) AddPoints(ctx context.Context, collection string, points []New
 	}
 
 	// Save points to file
-	fmt.Println("🔍 Saving points to file")
+	logger.Debug("saving points to file", "collection", collection)
 	if err := s.savePointsToFile(pointsToUpsert, collection); err != nil {
 		return nil, fmt.Errorf("error saving points to file: %w", err)
 	}
 
 	// Upsert points to Qdrant
-	fmt.Println("🔍 Upserting points to Qdrant")
+	logger.Debug("upserting points", "collection", collection)
 	upsertPoints := &qdrant.UpsertPoints{
 		CollectionName: collection,
 		Points:         pointsToUpsert,
@@ -200,7 +207,7 @@ func (s *Service) AddPoints(ctx context.Context, collection string, points []New
 
 // PerformSearch performs a vector search
 func (s *Service) Search(ctx context.Context, collection string, query string, limit uint64) ([]*qdrant.ScoredPoint, error) {
-	fmt.Println("🔍 Searching for query:", query)
+	logger.Info("searching", "collection", collection, "query", query, "limit", limit)
 	queryEmbedding, err := s.openAISvc.CreateJinaEmbedding(query)
 	if err != nil {
 		return nil, fmt.Errorf("error creating query embedding: %w", err)